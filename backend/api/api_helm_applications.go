@@ -21,6 +21,10 @@ func GetHelmReleaseHistory(w http.ResponseWriter, r *http.Request) {
 	controllers.GetHelmReleaseHistoryController(w, r)
 }
 
+func GetHelmReleaseGraph(w http.ResponseWriter, r *http.Request) {
+	controllers.GetHelmReleaseGraphController(w, r)
+}
+
 func ListHelmReleases(w http.ResponseWriter, r *http.Request) {
 	controllers.ListHelmReleasesController(w, r)
 }
@@ -32,3 +32,91 @@ func ListResources(w http.ResponseWriter, r *http.Request) {
 func UpdateResource(w http.ResponseWriter, r *http.Request) {
 	controllers.UpdateResourceController(w, r)
 }
+
+func GetPodLogs(w http.ResponseWriter, r *http.Request) {
+	controllers.GetPodLogsController(w, r)
+}
+
+func ExecPod(w http.ResponseWriter, r *http.Request) {
+	controllers.ExecPodController(w, r)
+}
+
+func AttachPod(w http.ResponseWriter, r *http.Request) {
+	controllers.AttachPodController(w, r)
+}
+
+func PortForwardPod(w http.ResponseWriter, r *http.Request) {
+	controllers.PortForwardPodController(w, r)
+}
+
+func PortForwardService(w http.ResponseWriter, r *http.Request) {
+	controllers.PortForwardServiceController(w, r)
+}
+
+func GetScale(w http.ResponseWriter, r *http.Request) {
+	controllers.GetScaleController(w, r)
+}
+
+func UpdateScale(w http.ResponseWriter, r *http.Request) {
+	controllers.UpdateScaleController(w, r)
+}
+
+func RestartRollout(w http.ResponseWriter, r *http.Request) {
+	controllers.RestartRolloutController(w, r)
+}
+
+func PauseRollout(w http.ResponseWriter, r *http.Request) {
+	controllers.PauseRolloutController(w, r)
+}
+
+func ResumeRollout(w http.ResponseWriter, r *http.Request) {
+	controllers.ResumeRolloutController(w, r)
+}
+
+func UndoRollout(w http.ResponseWriter, r *http.Request) {
+	controllers.UndoRolloutController(w, r)
+}
+
+func GetRolloutStatus(w http.ResponseWriter, r *http.Request) {
+	controllers.GetRolloutStatusController(w, r)
+}
+
+func PatchResource(w http.ResponseWriter, r *http.Request) {
+	controllers.PatchResourceController(w, r)
+}
+
+func ApplyManifest(w http.ResponseWriter, r *http.Request) {
+	controllers.ApplyManifestController(w, r)
+}
+
+func GetResourceEvents(w http.ResponseWriter, r *http.Request) {
+	controllers.GetResourceEventsController(w, r)
+}
+
+func ListNamespaceEvents(w http.ResponseWriter, r *http.Request) {
+	controllers.ListNamespaceEventsController(w, r)
+}
+
+func GetOwnershipGraph(w http.ResponseWriter, r *http.Request) {
+	controllers.GetOwnershipGraphController(w, r)
+}
+
+func RevealSecret(w http.ResponseWriter, r *http.Request) {
+	controllers.RevealSecretController(w, r)
+}
+
+func CordonNode(w http.ResponseWriter, r *http.Request) {
+	controllers.CordonNodeController(w, r)
+}
+
+func UncordonNode(w http.ResponseWriter, r *http.Request) {
+	controllers.UncordonNodeController(w, r)
+}
+
+func DrainNode(w http.ResponseWriter, r *http.Request) {
+	controllers.DrainNodeController(w, r)
+}
+
+func GetResourceUsage(w http.ResponseWriter, r *http.Request) {
+	controllers.GetResourceUsageController(w, r)
+}
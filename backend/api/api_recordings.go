@@ -0,0 +1,26 @@
+/*
+ * KubernetesAccessManager - API
+ *
+ * This is a backend API server documentation for KubernetesAccessManager  Some useful links: - [Jira](https://samuelus.atlassian.net/jira/software/projects/ZPI/boards/4) - [Confluence](https://samuelus.atlassian.net/wiki/spaces/ZPI/overview)
+ *
+ * API version: 0.0.5
+ * Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ */
+package api
+
+import (
+	"github.com/ZPI-2024-25/KubernetesAccessManager/controllers"
+	"net/http"
+)
+
+func ListRecordings(w http.ResponseWriter, r *http.Request) {
+	controllers.ListRecordingsController(w, r)
+}
+
+func GetRecording(w http.ResponseWriter, r *http.Request) {
+	controllers.GetRecordingController(w, r)
+}
+
+func GetRecordingCast(w http.ResponseWriter, r *http.Request) {
+	controllers.GetRecordingCastController(w, r)
+}
@@ -26,7 +26,7 @@ type Route struct {
 type Routes []Route
 
 func NewRouter() *mux.Router {
-	router := mux.NewRouter().StrictSlash(true)
+	router := mux.NewRouter().StrictSlash(true).UseEncodedPath()
 	for _, route := range routes {
 		var handler http.Handler
 		handler = route.HandlerFunc
@@ -68,6 +68,13 @@ var routes = Routes{
 		GetHelmReleaseHistory,
 	},
 
+	Route{
+		"GetHelmReleaseGraph",
+		strings.ToUpper("Get"),
+		"/api/v1/helm/releases/{releaseName}/graph",
+		GetHelmReleaseGraph,
+	},
+
 	Route{
 		"ListHelmReleases",
 		strings.ToUpper("Get"),
@@ -110,6 +117,174 @@ var routes = Routes{
 		GetResource,
 	},
 
+	Route{
+		"GetPodLogs",
+		strings.ToUpper("Get"),
+		"/api/v1/k8s/Pod/{resourceName}/logs",
+		GetPodLogs,
+	},
+
+	Route{
+		"RevealSecret",
+		strings.ToUpper("Get"),
+		"/api/v1/k8s/Secret/{resourceName}/reveal",
+		RevealSecret,
+	},
+
+	Route{
+		"CordonNode",
+		strings.ToUpper("Post"),
+		"/api/v1/k8s/Node/{resourceName}/cordon",
+		CordonNode,
+	},
+
+	Route{
+		"UncordonNode",
+		strings.ToUpper("Post"),
+		"/api/v1/k8s/Node/{resourceName}/uncordon",
+		UncordonNode,
+	},
+
+	Route{
+		"DrainNode",
+		strings.ToUpper("Post"),
+		"/api/v1/k8s/Node/{resourceName}/drain",
+		DrainNode,
+	},
+
+	Route{
+		"ExecPod",
+		strings.ToUpper("Get"),
+		"/api/v1/k8s/Pod/{resourceName}/exec",
+		ExecPod,
+	},
+
+	Route{
+		"AttachPod",
+		strings.ToUpper("Get"),
+		"/api/v1/k8s/Pod/{resourceName}/attach",
+		AttachPod,
+	},
+
+	Route{
+		"PortForwardPod",
+		strings.ToUpper("Get"),
+		"/api/v1/k8s/Pod/{resourceName}/portforward",
+		PortForwardPod,
+	},
+
+	Route{
+		"PortForwardService",
+		strings.ToUpper("Get"),
+		"/api/v1/k8s/Service/{resourceName}/portforward",
+		PortForwardService,
+	},
+
+	Route{
+		"GetScale",
+		strings.ToUpper("Get"),
+		"/api/v1/k8s/{resourceType}/{resourceName}/scale",
+		GetScale,
+	},
+
+	Route{
+		"UpdateScale",
+		strings.ToUpper("Put"),
+		"/api/v1/k8s/{resourceType}/{resourceName}/scale",
+		UpdateScale,
+	},
+
+	Route{
+		"RestartRollout",
+		strings.ToUpper("Post"),
+		"/api/v1/k8s/{resourceType}/{resourceName}/rollout/restart",
+		RestartRollout,
+	},
+
+	Route{
+		"PauseRollout",
+		strings.ToUpper("Post"),
+		"/api/v1/k8s/{resourceType}/{resourceName}/rollout/pause",
+		PauseRollout,
+	},
+
+	Route{
+		"ResumeRollout",
+		strings.ToUpper("Post"),
+		"/api/v1/k8s/{resourceType}/{resourceName}/rollout/resume",
+		ResumeRollout,
+	},
+
+	Route{
+		"UndoRollout",
+		strings.ToUpper("Post"),
+		"/api/v1/k8s/{resourceType}/{resourceName}/rollout/undo",
+		UndoRollout,
+	},
+
+	Route{
+		"GetRolloutStatus",
+		strings.ToUpper("Get"),
+		"/api/v1/k8s/{resourceType}/{resourceName}/rollout/status",
+		GetRolloutStatus,
+	},
+
+	Route{
+		"GetResourceEvents",
+		strings.ToUpper("Get"),
+		"/api/v1/k8s/{resourceType}/{resourceName}/events",
+		GetResourceEvents,
+	},
+
+	Route{
+		"GetOwnershipGraph",
+		strings.ToUpper("Get"),
+		"/api/v1/k8s/{resourceType}/{resourceName}/graph",
+		GetOwnershipGraph,
+	},
+
+	Route{
+		"GetResourceUsage",
+		strings.ToUpper("Get"),
+		"/api/v1/k8s/{resourceType}/{resourceName}/usage",
+		GetResourceUsage,
+	},
+
+	Route{
+		"ListNamespaceEvents",
+		strings.ToUpper("Get"),
+		"/api/v1/events",
+		ListNamespaceEvents,
+	},
+
+	Route{
+		"ApplyManifest",
+		strings.ToUpper("Post"),
+		"/api/v1/apply",
+		ApplyManifest,
+	},
+
+	Route{
+		"ListRecordings",
+		strings.ToUpper("Get"),
+		"/api/v1/recordings",
+		ListRecordings,
+	},
+
+	Route{
+		"GetRecording",
+		strings.ToUpper("Get"),
+		"/api/v1/recordings/{recordingId}",
+		GetRecording,
+	},
+
+	Route{
+		"GetRecordingCast",
+		strings.ToUpper("Get"),
+		"/api/v1/recordings/{recordingId}/cast",
+		GetRecordingCast,
+	},
+
 	Route{
 		"ListResources",
 		strings.ToUpper("Get"),
@@ -124,6 +299,13 @@ var routes = Routes{
 		UpdateResource,
 	},
 
+	Route{
+		"PatchResource",
+		strings.ToUpper("Patch"),
+		"/api/v1/k8s/{resourceType}/{resourceName}",
+		PatchResource,
+	},
+
 	Route{
 		"CheckLoginStatus",
 		strings.ToUpper("Get"),
@@ -0,0 +1,79 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+)
+
+// ActionReveal is the action of entries recording revealed values of a Secret.
+const ActionReveal = "reveal"
+
+// Trail keeps a record of accesses to sensitive data.
+type Trail interface {
+	Record(entry models.AuditEntry) error
+}
+
+// WriterTrail writes every entry as a single line of JSON, which log collectors pick up as one record.
+type WriterTrail struct {
+	mutex  sync.Mutex
+	writer io.Writer
+}
+
+var (
+	trailInstance Trail = NewWriterTrail(os.Stdout)
+	trailMutex    sync.RWMutex
+)
+
+// InitTrail makes GetTrail append entries to the file, e.g. on a persistent volume, in place of the standard output.
+func InitTrail(path string) error {
+	trail, err := NewFileTrail(path)
+	if err != nil {
+		return err
+	}
+
+	trailMutex.Lock()
+	defer trailMutex.Unlock()
+	trailInstance = trail
+	return nil
+}
+
+// GetTrail returns the trail prepared by InitTrail, or the one writing to the standard output.
+func GetTrail() Trail {
+	trailMutex.RLock()
+	defer trailMutex.RUnlock()
+	return trailInstance
+}
+
+func NewWriterTrail(writer io.Writer) *WriterTrail {
+	return &WriterTrail{writer: writer}
+}
+
+// NewFileTrail opens the file for appending, so entries from earlier runs are kept.
+func NewFileTrail(path string) (*WriterTrail, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create audit trail directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit trail: %w", err)
+	}
+	return NewWriterTrail(file), nil
+}
+
+func (t *WriterTrail) Record(entry models.AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	_, err = t.writer.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestEntry() models.AuditEntry {
+	return models.AuditEntry{
+		Time:      time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		User:      "alice",
+		Action:    ActionReveal,
+		Resource:  "Secret",
+		Namespace: "payments",
+		Name:      "db-credentials",
+		Keys:      []string{"password"},
+	}
+}
+
+func TestWriterTrailWritesJSONLines(t *testing.T) {
+	var buffer bytes.Buffer
+	trail := NewWriterTrail(&buffer)
+
+	assert.NoError(t, trail.Record(newTestEntry()))
+	assert.NoError(t, trail.Record(newTestEntry()))
+
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	assert.Len(t, lines, 2)
+	var entry models.AuditEntry
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, newTestEntry(), entry)
+}
+
+func TestFileTrailAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.log")
+	first, err := NewFileTrail(path)
+	assert.NoError(t, err)
+	assert.NoError(t, first.Record(newTestEntry()))
+
+	second, err := NewFileTrail(path)
+	assert.NoError(t, err)
+	assert.NoError(t, second.Record(newTestEntry()))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(data), "\n"))
+}
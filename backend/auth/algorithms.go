@@ -240,25 +240,26 @@ func regulateMatrix(matrix PermissionMatrix, op models.Operation,
 	}
 }
 
+// addOp grants the operation. A "*" grants only the basic operations, privileged ones have to be granted explicitly.
 func addOp(ops map[models.OperationType]struct{}, opType models.OperationType) {
 	if opType == models.All {
-		ops[models.Create] = struct{}{}
-		ops[models.Read] = struct{}{}
-		ops[models.Update] = struct{}{}
-		ops[models.Delete] = struct{}{}
-		ops[models.List] = struct{}{}
+		for _, op := range models.GetAllOperationTypes() {
+			ops[op] = struct{}{}
+		}
 	} else {
 		ops[opType] = struct{}{}
 	}
 }
 
+// removeOp revokes the operation. A "*" revokes the privileged operations as well.
 func removeOp(ops map[models.OperationType]struct{}, opType models.OperationType) {
 	if opType == models.All {
-		delete(ops, models.Create)
-		delete(ops, models.Read)
-		delete(ops, models.Update)
-		delete(ops, models.Delete)
-		delete(ops, models.List)
+		for _, op := range models.GetAllOperationTypes() {
+			delete(ops, op)
+		}
+		for _, op := range models.GetPrivilegedOperationTypes() {
+			delete(ops, op)
+		}
 	} else {
 		delete(ops, opType)
 	}
@@ -281,4 +282,53 @@ func expandResources(resource string, matrix PermissionMatrix) {
 			namespaced[resource][opType] = struct{}{}
 		}
 	}
-}
\ No newline at end of file
+}
+
+// collectReplicaLimits returns the replica limits of the role followed by those of its subroles,
+// so that a limit of the role takes precedence over an equally specific one inherited from a subrole.
+func collectReplicaLimits(role *models.Role, subroleMap map[string]*models.Role) []models.ReplicaLimit {
+	limits := append([]models.ReplicaLimit{}, role.Replicas...)
+	for _, child := range role.Subroles {
+		if childRole, exists := subroleMap[child]; exists {
+			limits = append(limits, collectReplicaLimits(childRole, subroleMap)...)
+		}
+	}
+	return limits
+}
+
+// getReplicaLimit returns the most specific limit matching the namespace and resource, a namespace
+// match being more specific than a resource match, or nil when no limit matches.
+func getReplicaLimit(limits []models.ReplicaLimit, namespace string, resource string) *models.ReplicaLimit {
+	var bestLimit *models.ReplicaLimit
+	bestScore := -1
+	for i, limit := range limits {
+		if (limit.Namespace != "*" && limit.Namespace != namespace) || (limit.Resource != "*" && limit.Resource != resource) {
+			continue
+		}
+		score := 0
+		if limit.Namespace != "*" {
+			score += 2
+		}
+		if limit.Resource != "*" {
+			score++
+		}
+		if score > bestScore {
+			bestLimit = &limits[i]
+			bestScore = score
+		}
+	}
+	return bestLimit
+}
+
+func isWithinReplicaLimit(limit *models.ReplicaLimit, replicas int32) bool {
+	if limit == nil {
+		return true
+	}
+	if limit.Min != nil && replicas < *limit.Min {
+		return false
+	}
+	if limit.Max != nil && replicas > *limit.Max {
+		return false
+	}
+	return true
+}
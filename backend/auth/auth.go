@@ -27,9 +27,17 @@ func InitializeAuth() {
 	log.Println("Connected successfully to auth provider on URL:", common.KeycloakJwksUrl)
 }
 
+// WebSocketTokenProtocolPrefix marks the Sec-WebSocket-Protocol entry carrying the token of a WebSocket request.
+const WebSocketTokenProtocolPrefix = "bearer."
+
+// GetJWTTokenFromHeader reads the bearer token from the Authorization header. Browsers cannot set that
+// header on WebSocket connections, so they may pass the token as a "bearer.<token>" subprotocol instead.
 func GetJWTTokenFromHeader(r *http.Request) (string, error) {
 	authHeader := r.Header.Get("Authorization")
 	if authHeader == "" {
+		if token, found := getTokenFromWebSocketProtocols(r); found {
+			return token, nil
+		}
 		return "", fmt.Errorf("authorization header missing")
 	}
 
@@ -40,6 +48,18 @@ func GetJWTTokenFromHeader(r *http.Request) (string, error) {
 	return parts[1], nil
 }
 
+func getTokenFromWebSocketProtocols(r *http.Request) (string, bool) {
+	for _, header := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(header, ",") {
+			protocol = strings.TrimSpace(protocol)
+			if strings.HasPrefix(protocol, WebSocketTokenProtocolPrefix) {
+				return strings.TrimPrefix(protocol, WebSocketTokenProtocolPrefix), true
+			}
+		}
+	}
+	return "", false
+}
+
 func IsTokenValid(tokenStr string) (bool, *jwt.MapClaims) {
 	claims := jwt.MapClaims{}
 	if jwks == nil {
@@ -84,6 +104,17 @@ func IsUserAuthorized(operation models.Operation, roles []string) (bool, error)
 	return false, nil
 }
 
+// IsReplicaCountAllowed authorizes scaling the way IsUserAuthorized does, additionally checking the replica limits of the roles.
+func IsReplicaCountAllowed(operation models.Operation, roles []string, replicas int32) (bool, error) {
+	authService, err := GetRoleMapInstance()
+	if err != nil {
+		log.Printf("Error while getting Rolemap instance: %v\n", err)
+		return false, err
+	}
+
+	return authService.IsReplicaCountAllowed(roles, &operation, replicas), nil
+}
+
 func ExtractRoles(claims *jwt.MapClaims) ([]string, *models.ModelError) {
 	var roles []string
 	if realmAccess, ok := (*claims)["realm_access"].(map[string]interface{}); ok {
@@ -125,6 +156,26 @@ func ExtractUserStatus(claims *jwt.MapClaims) (int32, string, string) {
 	return exp, preferredUsername, email
 }
 
+// ExtractExpirationTime returns the time the token expires at, zero time when the claim is missing.
+func ExtractExpirationTime(claims *jwt.MapClaims) time.Time {
+	if exp, ok := (*claims)["exp"].(float64); ok {
+		return time.Unix(int64(exp), 0)
+	}
+	return time.Time{}
+}
+
+// ExtractUsername returns the name under which the user is impersonated in the cluster.
+// It falls back to the subject claim when preferred_username is not present in the token.
+func ExtractUsername(claims *jwt.MapClaims) string {
+	if preferredUsername, ok := (*claims)["preferred_username"].(string); ok && preferredUsername != "" {
+		return preferredUsername
+	}
+	if subject, ok := (*claims)["sub"].(string); ok {
+		return subject
+	}
+	return ""
+}
+
 func FilterRestrictedResources(resources *models.ResourceList, claims *jwt.MapClaims, resourceType string) (*models.ResourceList, *models.ModelError) {
 	namespaces := make(map[string]struct{})
 	for _, resource := range resources.ResourceList {
@@ -145,6 +196,17 @@ func FilterRestrictedResources(resources *models.ResourceList, claims *jwt.MapCl
 	return resources, nil
 }
 
+// IsNamespaceAllowed checks the operation on a resource type in a single namespace, the way
+// FilterRestrictedResources does for every listed resource.
+func IsNamespaceAllowed(claims *jwt.MapClaims, resourceType string, namespace string, opType models.OperationType) (bool, *models.ModelError) {
+	allowed, err := getAllowedNamespaces(claims, resourceType, opType, map[string]struct{}{namespace: {}})
+	if err != nil {
+		return false, err
+	}
+	_, ok := allowed[namespace]
+	return ok, nil
+}
+
 func FilterRestrictedReleases(releases []models.HelmRelease, claims *jwt.MapClaims) ([]models.HelmRelease, *models.ModelError) {
 	namespaces := make(map[string]struct{})
 	for _, release := range releases {
@@ -4,9 +4,11 @@ import (
 	"github.com/ZPI-2024-25/KubernetesAccessManager/common"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/stretchr/testify/assert"
+	"net/http"
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestAlternativeWayOfExtractingRoles(t *testing.T) {
@@ -132,3 +134,84 @@ func TestExtractRoles(t *testing.T) {
 		assert.ElementsMatch(t, expectedRoles, roles)
 	})
 }
+
+func TestExtractUsername(t *testing.T) {
+	t.Run("TestExtractUsernameFromPreferredUsername", func(t *testing.T) {
+		claims := jwt.MapClaims{
+			"preferred_username": "jane",
+			"sub":                "dd967421-a04e-4c3a-a74c-57e483dad1a8",
+		}
+		assert.Equal(t, "jane", ExtractUsername(&claims))
+	})
+
+	t.Run("TestExtractUsernameFallbackToSubject", func(t *testing.T) {
+		claims := jwt.MapClaims{
+			"sub": "dd967421-a04e-4c3a-a74c-57e483dad1a8",
+		}
+		assert.Equal(t, "dd967421-a04e-4c3a-a74c-57e483dad1a8", ExtractUsername(&claims))
+	})
+
+	t.Run("TestExtractUsernameMissing", func(t *testing.T) {
+		claims := jwt.MapClaims{}
+		assert.Equal(t, "", ExtractUsername(&claims))
+	})
+}
+
+func TestExtractExpirationTime(t *testing.T) {
+	t.Run("TestExtractExpirationTime", func(t *testing.T) {
+		claims := jwt.MapClaims{"exp": float64(1730123468)}
+		assert.Equal(t, time.Unix(1730123468, 0), ExtractExpirationTime(&claims))
+	})
+
+	t.Run("TestExtractExpirationTimeMissing", func(t *testing.T) {
+		claims := jwt.MapClaims{}
+		assert.True(t, ExtractExpirationTime(&claims).IsZero())
+	})
+}
+
+func TestGetJWTTokenFromHeader(t *testing.T) {
+	tests := []struct {
+		name          string
+		headers       map[string]string
+		expectedToken string
+		expectError   bool
+	}{
+		{
+			name:          "Authorization header",
+			headers:       map[string]string{"Authorization": "Bearer abc.def.ghi"},
+			expectedToken: "abc.def.ghi",
+		},
+		{
+			name:        "Invalid authorization header",
+			headers:     map[string]string{"Authorization": "Basic abc"},
+			expectError: true,
+		},
+		{
+			name:          "WebSocket subprotocol",
+			headers:       map[string]string{"Sec-WebSocket-Protocol": "kam.v1, bearer.abc.def.ghi"},
+			expectedToken: "abc.def.ghi",
+		},
+		{
+			name:        "WebSocket subprotocol without token",
+			headers:     map[string]string{"Sec-WebSocket-Protocol": "kam.v1"},
+			expectError: true,
+		},
+		{
+			name:        "No headers",
+			headers:     map[string]string{},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := http.NewRequest(http.MethodGet, "/api/v1/", nil)
+			for key, value := range tt.headers {
+				r.Header.Set(key, value)
+			}
+			token, err := GetJWTTokenFromHeader(r)
+			assert.Equal(t, tt.expectError, err != nil)
+			assert.Equal(t, tt.expectedToken, token)
+		})
+	}
+}
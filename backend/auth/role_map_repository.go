@@ -20,6 +20,8 @@ type RoleMapRepository struct {
 	RoleMap      map[string]*models.Role
 	SubroleMap   map[string]*models.Role
 	flattenedMap map[string]PermissionMatrix
+	// replica limits of every role together with those inherited from its subroles
+	replicaLimits map[string][]models.ReplicaLimit
 }
 
 type operationConfig struct {
@@ -28,11 +30,19 @@ type operationConfig struct {
 	Operations []models.OperationType `json:"operations,omitempty"`
 }
 
+type replicaLimitConfig struct {
+	Namespace string `json:"namespace,omitempty"`
+	Resource  string `json:"resource,omitempty"`
+	Min       *int32 `json:"min,omitempty"`
+	Max       *int32 `json:"max,omitempty"`
+}
+
 type roleConfig struct {
-	Name     string            `json:"name,omitempty"`
-	Permit   []operationConfig `json:"permit,omitempty"`
-	Deny     []operationConfig `json:"deny,omitempty"`
-	Subroles []string          `json:"subroles,omitempty"`
+	Name     string               `json:"name,omitempty"`
+	Permit   []operationConfig    `json:"permit,omitempty"`
+	Deny     []operationConfig    `json:"deny,omitempty"`
+	Subroles []string             `json:"subroles,omitempty"`
+	Replicas []replicaLimitConfig `json:"replicas,omitempty"`
 }
 
 var (
@@ -51,9 +61,10 @@ func GetRoleMapInstance() (*RoleMapRepository, error) {
 		permissionMatrix := createPermissionMatrix(roleMap, subroleMap)
 		mutex.Lock()
 		instance = &RoleMapRepository{
-			RoleMap:      roleMap,
-			SubroleMap:   subroleMap,
-			flattenedMap: permissionMatrix,
+			RoleMap:       roleMap,
+			SubroleMap:    subroleMap,
+			flattenedMap:  permissionMatrix,
+			replicaLimits: createReplicaLimits(roleMap, subroleMap),
 		}
 		mutex.Unlock()
 		log.Printf("RoleMapRepository initialized with %d roles %d subroles", len(instance.RoleMap), len(instance.SubroleMap))
@@ -75,6 +86,21 @@ func (rmr *RoleMapRepository) HasPermission(rolenames []string, operation *model
 	return false
 }
 
+// IsReplicaCountAllowed checks whether any of the roles both permits the operation and allows scaling
+// to the replica count. A role without a matching replica limit allows any count.
+func (rmr *RoleMapRepository) IsReplicaCountAllowed(rolenames []string, operation *models.Operation, replicas int32) bool {
+	for _, role := range rolenames {
+		if !flatHasPermission(operation, rmr.flattenedMap[role]) {
+			continue
+		}
+		limit := getReplicaLimit(rmr.replicaLimits[role], operation.Namespace, operation.Resource)
+		if isWithinReplicaLimit(limit, replicas) {
+			return true
+		}
+	}
+	return false
+}
+
 func (rmr *RoleMapRepository) GetAllPermissions(roles []string) PermissionMatrix {
 	pmatrix := make(PermissionMatrix)
 	first := true
@@ -93,6 +119,19 @@ func (rmr *RoleMapRepository) GetAllPermissions(roles []string) PermissionMatrix
 	return pmatrix
 }
 
+// FullPermissionMatrix grants every operation on every resource in every namespace.
+// It is reported to users when the role map is disabled and the API server alone decides about access.
+func FullPermissionMatrix() PermissionMatrix {
+	matrix := make(PermissionMatrix)
+	matrix["*"] = make(map[string]map[models.OperationType]struct{})
+	matrix["*"]["*"] = make(map[models.OperationType]struct{})
+	addOp(matrix["*"]["*"], models.All)
+	for _, op := range models.GetPrivilegedOperationTypes() {
+		addOp(matrix["*"]["*"], op)
+	}
+	return matrix
+}
+
 func (rmr *RoleMapRepository) HasPermissionInAnyNamespace(rolenames []string, resource string, op models.OperationType) bool {
 	for _, role := range rolenames {
 		for _, namespace := range rmr.flattenedMap[role] {
@@ -138,6 +177,17 @@ func createPermissionMatrix(
 	return superMatrix
 }
 
+func createReplicaLimits(
+	roleMap map[string]*models.Role,
+	subroleMap map[string]*models.Role,
+) map[string][]models.ReplicaLimit {
+	limits := make(map[string][]models.ReplicaLimit)
+	for _, role := range roleMap {
+		limits[role.Name] = collectReplicaLimits(role, subroleMap)
+	}
+	return limits
+}
+
 func GetRoleMapConfig(namespace string, name string) (map[string]*models.Role, map[string]*models.Role) {
 	res, err := cluster.GetResource("ConfigMap", namespace, name, cluster.GetResourceInterface)
 	if err != nil {
@@ -211,6 +261,9 @@ func fromRoleConfig(config *roleConfig) *models.Role {
 		Name:     config.Name,
 		Subroles: config.Subroles,
 	}
+	if len(config.Replicas) > 0 {
+		role.Replicas = fromReplicaLimitConfigList(config.Replicas)
+	}
 	if len(permit) > 0 {
 		role.Permit = permit
 	}
@@ -250,6 +303,27 @@ func fromOperationConfigList(operations []operationConfig) []models.Operation {
 	return ops
 }
 
+func fromReplicaLimitConfigList(limits []replicaLimitConfig) []models.ReplicaLimit {
+	replicaLimits := make([]models.ReplicaLimit, 0, len(limits))
+	for _, limitConfig := range limits {
+		namespace := limitConfig.Namespace
+		if namespace == "" {
+			namespace = "*"
+		}
+		resource := limitConfig.Resource
+		if resource == "" {
+			resource = "*"
+		}
+		replicaLimits = append(replicaLimits, models.ReplicaLimit{
+			Namespace: namespace,
+			Resource:  resource,
+			Min:       limitConfig.Min,
+			Max:       limitConfig.Max,
+		})
+	}
+	return replicaLimits
+}
+
 func WatchForRolemapChanges() {
 	cluster.WatchForChanges(common.RoleMapNamespace, common.RoleMapName, &mutex, updateRoleMapRepo)
 }
@@ -296,5 +370,6 @@ func doRoleMapRepoUpdate(mutex *sync.Mutex, namespace, resourceName string) {
 		rolemapRepo.RoleMap = rolemap
 		rolemapRepo.SubroleMap = subroleMap
 		rolemapRepo.flattenedMap = flattened
+		rolemapRepo.replicaLimits = createReplicaLimits(rolemap, subroleMap)
 	}
 }
@@ -1444,4 +1444,121 @@ func TestHasPermissionInAnyNamespace(t *testing.T) {
 			assert.Equal(t, tt.expected, result)
 		})
 	}
-}
\ No newline at end of file
+}
+func TestIsReplicaCountAllowed(t *testing.T) {
+	one, three, five, ten := int32(1), int32(3), int32(5), int32(10)
+	roleMap := map[string]*models.Role{
+		"developer": {
+			Name:   "developer",
+			Permit: []models.Operation{{Type: models.Scale, Resource: "Deployment", Namespace: "*"}},
+			Replicas: []models.ReplicaLimit{
+				{Namespace: "*", Resource: "*", Min: &one, Max: &three},
+				{Namespace: "staging", Resource: "Deployment", Max: &five},
+			},
+			Subroles: []string{"batch"},
+		},
+		"operator": {
+			Name:   "operator",
+			Permit: []models.Operation{{Type: models.Scale, Resource: "*", Namespace: "prod"}},
+		},
+		"reader": {
+			Name:     "reader",
+			Permit:   []models.Operation{{Type: models.Read, Resource: "*", Namespace: "*"}},
+			Replicas: []models.ReplicaLimit{{Namespace: "*", Resource: "*", Max: &ten}},
+		},
+	}
+	subroleMap := map[string]*models.Role{
+		"batch": {
+			Name:     "batch",
+			Permit:   []models.Operation{{Type: models.Scale, Resource: "StatefulSet", Namespace: "*"}},
+			Replicas: []models.ReplicaLimit{{Namespace: "*", Resource: "StatefulSet", Max: &ten}},
+		},
+	}
+	rmr := &RoleMapRepository{
+		RoleMap:       roleMap,
+		SubroleMap:    subroleMap,
+		flattenedMap:  createPermissionMatrix(roleMap, subroleMap),
+		replicaLimits: createReplicaLimits(roleMap, subroleMap),
+	}
+
+	tests := []struct {
+		name      string
+		rolenames []string
+		namespace string
+		resource  string
+		replicas  int32
+		expected  bool
+	}{
+		{"Within the wildcard limit", []string{"developer"}, "dev", "Deployment", 2, true},
+		{"Above the wildcard limit", []string{"developer"}, "dev", "Deployment", 4, false},
+		{"Below the wildcard limit", []string{"developer"}, "dev", "Deployment", 0, false},
+		{"More specific limit takes precedence", []string{"developer"}, "staging", "Deployment", 5, true},
+		{"More specific limit without minimum", []string{"developer"}, "staging", "Deployment", 0, true},
+		{"Limit inherited from subrole", []string{"developer"}, "dev", "StatefulSet", 8, true},
+		{"Role without limits", []string{"operator"}, "prod", "Deployment", 50, true},
+		{"Another role allows the count", []string{"developer", "operator"}, "prod", "Deployment", 50, true},
+		{"Limit without scale permission", []string{"reader"}, "dev", "Deployment", 2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operation := &models.Operation{Type: models.Scale, Resource: tt.resource, Namespace: tt.namespace}
+			assert.Equal(t, tt.expected, rmr.IsReplicaCountAllowed(tt.rolenames, operation, tt.replicas))
+		})
+	}
+}
+
+func TestFromRoleConfigReplicaLimits(t *testing.T) {
+	five := int32(5)
+	role := fromRoleConfig(&roleConfig{
+		Name:     "developer",
+		Replicas: []replicaLimitConfig{{Resource: "Deployment", Max: &five}},
+	})
+
+	assert.Equal(t, []models.ReplicaLimit{{Namespace: "*", Resource: "Deployment", Max: &five}}, role.Replicas)
+}
+
+func TestWildcardExcludesPrivilegedOperations(t *testing.T) {
+	roleMap := map[string]*models.Role{
+		"admin": {Name: "admin", Permit: []models.Operation{{Type: models.All, Resource: "*", Namespace: "*"}}},
+		"operator": {Name: "operator", Permit: []models.Operation{
+			{Type: models.All, Resource: "*", Namespace: "*"},
+			{Type: models.Exec, Resource: "Pod", Namespace: "*"},
+		}},
+		"restricted": {Name: "restricted", Permit: []models.Operation{
+			{Type: models.Exec, Resource: "Pod", Namespace: "*"},
+			{Type: models.Reveal, Resource: "Secret", Namespace: "*"},
+		}, Deny: []models.Operation{{Type: models.All, Resource: "*", Namespace: "prod"}}},
+	}
+	rmr := &RoleMapRepository{RoleMap: roleMap, flattenedMap: createPermissionMatrix(roleMap, nil)}
+
+	tests := []struct {
+		name      string
+		rolenames []string
+		operation models.Operation
+		expected  bool
+	}{
+		{"Wildcard grants basic operation", []string{"admin"}, models.Operation{Type: models.Delete, Resource: "Pod", Namespace: "dev"}, true},
+		{"Wildcard does not grant exec", []string{"admin"}, models.Operation{Type: models.Exec, Resource: "Pod", Namespace: "dev"}, false},
+		{"Wildcard does not grant reveal", []string{"admin"}, models.Operation{Type: models.Reveal, Resource: "Secret", Namespace: "dev"}, false},
+		{"Wildcard does not grant force delete", []string{"admin"}, models.Operation{Type: models.ForceDelete, Resource: "Pod", Namespace: "dev"}, false},
+		{"Wildcard does not grant drain", []string{"admin"}, models.Operation{Type: models.Drain, Resource: "Node", Namespace: ""}, false},
+		{"Explicit grant of exec", []string{"operator"}, models.Operation{Type: models.Exec, Resource: "Pod", Namespace: "dev"}, true},
+		{"Explicit grant limited to resource", []string{"operator"}, models.Operation{Type: models.Exec, Resource: "Service", Namespace: "dev"}, false},
+		{"Explicit grant outside denied namespace", []string{"restricted"}, models.Operation{Type: models.Reveal, Resource: "Secret", Namespace: "dev"}, true},
+		{"Wildcard deny revokes privileged operation", []string{"restricted"}, models.Operation{Type: models.Exec, Resource: "Pod", Namespace: "prod"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, rmr.HasPermission(tt.rolenames, &tt.operation))
+		})
+	}
+}
+
+func TestFullPermissionMatrixIncludesPrivilegedOperations(t *testing.T) {
+	matrix := FullPermissionMatrix()
+	for _, op := range append(models.GetAllOperationTypes(), models.GetPrivilegedOperationTypes()...) {
+		assert.True(t, flatHasPermission(&models.Operation{Type: op, Resource: "Pod", Namespace: "dev"}, matrix), string(op))
+	}
+}
@@ -0,0 +1,27 @@
+package cluster
+
+import (
+	"context"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IsActionAllowed asks the API server whether the client returned by getClientset may perform an action.
+// It authorizes KAM features which are not requests to the cluster, such as viewing session recordings,
+// for impersonated users.
+func IsActionAllowed(ctx context.Context, attributes authorizationv1.ResourceAttributes, getClientset ClientsetGetter) (bool, *models.ModelError) {
+	clientset, err := getClientset()
+	if err != nil {
+		return false, err
+	}
+
+	review, reviewErr := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attributes},
+	}, metav1.CreateOptions{})
+	if reviewErr != nil {
+		return false, handleKubernetesErrorOr(reviewErr, 500, "Failed to review access")
+	}
+	return review.Status.Allowed, nil
+}
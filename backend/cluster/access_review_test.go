@@ -0,0 +1,68 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"github.com/stretchr/testify/assert"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestIsActionAllowed(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = review.Spec.ResourceAttributes.Namespace == "payments"
+		return true, review, nil
+	})
+	getClientset := func() (kubernetes.Interface, *models.ModelError) {
+		return clientset, nil
+	}
+	attributes := authorizationv1.ResourceAttributes{Verb: "get", Resource: "pods", Subresource: "recordings"}
+
+	attributes.Namespace = "payments"
+	allowed, err := IsActionAllowed(context.TODO(), attributes, getClientset)
+	assert.Nil(t, err)
+	assert.True(t, allowed)
+
+	attributes.Namespace = "billing"
+	allowed, err = IsActionAllowed(context.TODO(), attributes, getClientset)
+	assert.Nil(t, err)
+	assert.False(t, allowed)
+}
+
+func TestIsActionAllowedReviewError(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+	getClientset := func() (kubernetes.Interface, *models.ModelError) {
+		return clientset, nil
+	}
+
+	allowed, err := IsActionAllowed(context.TODO(), authorizationv1.ResourceAttributes{Verb: "get", Resource: "pods"}, getClientset)
+	assert.False(t, allowed)
+	assert.Equal(t, &models.ModelError{Code: 500, Message: "Failed to review access: connection refused"}, err)
+}
+
+func TestIsActionAllowedReviewStatusError(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewTooManyRequests("too many requests", 5)
+	})
+	getClientset := func() (kubernetes.Interface, *models.ModelError) {
+		return clientset, nil
+	}
+
+	allowed, err := IsActionAllowed(context.TODO(), authorizationv1.ResourceAttributes{Verb: "get", Resource: "pods"}, getClientset)
+	assert.False(t, allowed)
+	assert.Equal(t, int32(429), err.Code)
+	assert.Equal(t, int32(5), err.RetryAfterSeconds)
+}
@@ -0,0 +1,337 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	applyActionCreated    = "created"
+	applyActionConfigured = "configured"
+	applyActionUnchanged  = "unchanged"
+	applyActionFailed     = "failed"
+	applyActionSkipped    = "skipped"
+
+	namespaceKind                = "Namespace"
+	customResourceDefinitionKind = "CustomResourceDefinition"
+	crdEstablishedPollInterval   = 500 * time.Millisecond
+)
+
+// crdEstablishedTimeout limits how long custom resources wait for the definition applied before them to be served
+var crdEstablishedTimeout = 30 * time.Second
+
+// ApplyAuthorizer checks whether the caller may do the operation on an object of the resource type, given by
+// its canonical name, in the namespace.
+type ApplyAuthorizer func(resourceType string, namespace string, opType models.OperationType) *models.ModelError
+
+type ApplyOptions struct {
+	// Namespace of the namespaced objects which do not set one. Objects setting another namespace are rejected.
+	Namespace string
+	// Atomic stops at the first failure and deletes the objects created until then.
+	Atomic bool
+	DryRun bool
+	// Force takes over fields owned by other field managers.
+	Force bool
+}
+
+// manifestObject is an object of a manifest together with what is needed to roll it back.
+type manifestObject struct {
+	document          int
+	object            *unstructured.Unstructured
+	resultIndex       int
+	resourceInterface dynamic.ResourceInterface
+}
+
+// ApplyManifest applies the objects of a manifest with server-side apply, the way kubectl apply --server-side
+// does. Objects are applied in dependency order: namespaces, then custom resource definitions, then the other
+// kinds in the order Helm installs them, so a manifest may contain custom resources of the definitions it
+// creates. Each object is authorized on its own with both the create and the update operation, as server-side
+// apply may do either. The report lists the result of every object, failures do not stop the remaining objects unless the
+// request is atomic.
+func ApplyManifest(documents []interface{}, options ApplyOptions, authorize ApplyAuthorizer, getResourceInterface ResourceInterfaceGetter) models.ApplyReport {
+	report := models.ApplyReport{DryRun: options.DryRun, Results: make([]models.ApplyResult, 0)}
+
+	objects := make([]*manifestObject, 0)
+	for i, document := range documents {
+		items, err := decodeManifestDocument(document, options.Namespace)
+		if err != nil {
+			report.Results = append(report.Results, models.ApplyResult{Document: i + 1, Action: applyActionFailed, Error: err})
+			continue
+		}
+		for _, item := range items {
+			objects = append(objects, &manifestObject{document: i + 1, object: item})
+		}
+	}
+	// With an atomic request nothing is applied when any document is invalid
+	failed := len(report.Results) > 0
+	sortByApplyOrder(objects)
+
+	created := make([]*manifestObject, 0)
+	for _, object := range objects {
+		result := models.ApplyResult{Action: applyActionSkipped}
+		if !failed || !options.Atomic {
+			result.Action, result.Error = applyManifestObject(object, options, authorize, getResourceInterface)
+		}
+		if result.Action == applyActionCreated {
+			created = append(created, object)
+		}
+		if result.Error != nil {
+			failed = true
+			if result.Action == "" {
+				result.Action = applyActionFailed
+			}
+		}
+
+		result.Document = object.document
+		result.ApiVersion = object.object.GetAPIVersion()
+		result.Kind = object.object.GetKind()
+		result.Name = object.object.GetName()
+		result.Namespace = object.object.GetNamespace()
+		object.resultIndex = len(report.Results)
+		report.Results = append(report.Results, result)
+	}
+
+	report.Succeeded = !failed
+	if failed && options.Atomic && !options.DryRun {
+		report.RolledBack = rollbackCreatedObjects(created, report.Results)
+	}
+	return report
+}
+
+// decodeManifestDocument returns the object of a document, or the items of a List.
+func decodeManifestDocument(document interface{}, namespace string) ([]*unstructured.Unstructured, *models.ModelError) {
+	// The JSON form gives the value types of unstructured objects, whatever format the manifest was written in
+	data, err := json.Marshal(document)
+	if err != nil {
+		return nil, &models.ModelError{Code: 400, Message: fmt.Sprintf("Invalid object: %s", err)}
+	}
+	var content map[string]interface{}
+	if err := json.Unmarshal(data, &content); err != nil || content == nil {
+		return nil, &models.ModelError{Code: 400, Message: "Invalid object: the document is not an object"}
+	}
+
+	object := &unstructured.Unstructured{Object: content}
+	if !object.IsList() {
+		if err := validateManifestObject(object, namespace); err != nil {
+			return nil, err
+		}
+		return []*unstructured.Unstructured{object}, nil
+	}
+
+	items := make([]*unstructured.Unstructured, 0)
+	listErr := object.EachListItem(func(item runtime.Object) error {
+		items = append(items, item.(*unstructured.Unstructured))
+		return nil
+	})
+	if listErr != nil {
+		return nil, &models.ModelError{Code: 400, Message: fmt.Sprintf("Invalid list: %s", listErr)}
+	}
+	for _, item := range items {
+		if err := validateManifestObject(item, namespace); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+func validateManifestObject(object *unstructured.Unstructured, namespace string) *models.ModelError {
+	if object.GetAPIVersion() == "" || object.GetKind() == "" {
+		return &models.ModelError{Code: 400, Message: "Invalid object: apiVersion and kind are required"}
+	}
+	if object.GetName() == "" {
+		return &models.ModelError{Code: 400, Message: fmt.Sprintf("Invalid object: %s without metadata.name", object.GetKind())}
+	}
+	if namespace != "" && object.GetNamespace() != "" && object.GetNamespace() != namespace {
+		return &models.ModelError{
+			Code:    400,
+			Message: fmt.Sprintf("The namespace %s of %s %s does not match the namespace %s of the request", object.GetNamespace(), object.GetKind(), object.GetName(), namespace),
+		}
+	}
+	return nil
+}
+
+// sortByApplyOrder orders the objects by kind, keeping the order of the manifest for objects of the same kind.
+func sortByApplyOrder(objects []*manifestObject) {
+	sort.SliceStable(objects, func(i, j int) bool {
+		return getApplyOrder(objects[i].object.GetKind()) < getApplyOrder(objects[j].object.GetKind())
+	})
+}
+
+// getApplyOrder puts namespaces and custom resource definitions first, as the other objects may be placed in
+// them or be instances of them, and the other known kinds in the order Helm installs them. Unknown kinds,
+// custom resources in particular, go last.
+func getApplyOrder(kind string) int {
+	switch kind {
+	case namespaceKind:
+		return 0
+	case customResourceDefinitionKind:
+		return 1
+	}
+	for i, installedKind := range releaseutil.InstallOrder {
+		if installedKind == kind {
+			return i + 2
+		}
+	}
+	return len(releaseutil.InstallOrder) + 2
+}
+
+// applyManifestObject applies an object and returns what happened to it. A custom resource definition
+// is only reported as applied once it is served, so that its custom resources can be applied next.
+func applyManifestObject(object *manifestObject, options ApplyOptions, authorize ApplyAuthorizer, getResourceInterface ResourceInterfaceGetter) (string, *models.ModelError) {
+	typeName := fmt.Sprintf("%s/%s", object.object.GetAPIVersion(), object.object.GetKind())
+	resourceType, err := NormalizeResourceType(typeName)
+	if err != nil {
+		if err.Code == 400 {
+			return "", &models.ModelError{Code: 400, Message: fmt.Sprintf("Invalid Resource Type: %s", typeName)}
+		}
+		return "", err
+	}
+	_, namespaced, err := GetResourceGroupVersion(typeName)
+	if err != nil {
+		return "", err
+	}
+
+	namespace := options.Namespace
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	if namespaced {
+		if object.object.GetNamespace() == "" {
+			object.object.SetNamespace(namespace)
+		}
+		namespace = object.object.GetNamespace()
+	} else {
+		object.object.SetNamespace("")
+	}
+
+	// Both operations are required before the object is read, as whether it exists must not be revealed
+	// to a caller who could only do one of them. Cluster-scoped objects are authorized in the namespace
+	// of the request, as with the other endpoints.
+	for _, opType := range []models.OperationType{models.Create, models.Update} {
+		if err := authorize(resourceType, namespace, opType); err != nil {
+			return "", err
+		}
+	}
+
+	resourceInterface, err := getResourceInterface(typeName, namespace, DefaultNamespace)
+	if err != nil {
+		return "", err
+	}
+	name := object.object.GetName()
+	current, getErr := resourceInterface.Get(context.TODO(), name, metav1.GetOptions{})
+	if getErr != nil {
+		if !errors.IsNotFound(getErr) {
+			return "", handleKubernetesError(getErr)
+		}
+		current = nil
+	}
+
+	data, marshalErr := object.object.MarshalJSON()
+	if marshalErr != nil {
+		return "", &models.ModelError{Code: 400, Message: fmt.Sprintf("Invalid object: %s", marshalErr)}
+	}
+	force := options.Force
+	applied, patchErr := resourceInterface.Patch(context.TODO(), name, types.ApplyPatchType, data, metav1.PatchOptions{
+		FieldManager: FieldManager,
+		Force:        &force,
+		DryRun:       getDryRunOption(options.DryRun),
+	})
+	if patchErr != nil {
+		return "", handleKubernetesError(patchErr)
+	}
+	object.resourceInterface = resourceInterface
+
+	action := applyActionCreated
+	if current != nil {
+		action = applyActionConfigured
+		if hasSameContent(current, applied) {
+			action = applyActionUnchanged
+		}
+	}
+
+	if object.object.GetKind() == customResourceDefinitionKind && !options.DryRun {
+		if err := waitForEstablished(resourceInterface, name); err != nil {
+			return action, err
+		}
+	}
+	return action, nil
+}
+
+// hasSameContent compares objects without the metadata which the API server changes on every apply.
+func hasSameContent(current *unstructured.Unstructured, applied *unstructured.Unstructured) bool {
+	current, applied = current.DeepCopy(), applied.DeepCopy()
+	for _, object := range []*unstructured.Unstructured{current, applied} {
+		object.SetManagedFields(nil)
+		object.SetResourceVersion("")
+		object.SetGeneration(0)
+	}
+	return equality.Semantic.DeepEqual(current.Object, applied.Object)
+}
+
+// waitForEstablished waits until the API server serves the resources of a custom resource definition and
+// refreshes the discovery cache, so that they can be resolved.
+func waitForEstablished(resourceInterface dynamic.ResourceInterface, name string) *models.ModelError {
+	pollErr := wait.PollUntilContextTimeout(context.TODO(), crdEstablishedPollInterval, crdEstablishedTimeout, true, func(ctx context.Context) (bool, error) {
+		definition, err := resourceInterface.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return isEstablished(definition), nil
+	})
+	if pollErr != nil {
+		if wait.Interrupted(pollErr) {
+			return &models.ModelError{Code: 500, Message: fmt.Sprintf("CustomResourceDefinition %s was not established within %s", name, crdEstablishedTimeout)}
+		}
+		return handleKubernetesError(pollErr)
+	}
+
+	if cache, err := getDiscoveryCache(); err == nil {
+		cache.invalidate(invalidationReasonCRD)
+	}
+	return nil
+}
+
+func isEstablished(definition *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(definition.Object, "status", "conditions")
+	for _, condition := range conditions {
+		conditionMap, ok := condition.(map[string]interface{})
+		if ok && conditionMap["type"] == "Established" && conditionMap["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
+
+// rollbackCreatedObjects deletes the created objects in the reverse order of creation, so e.g. a namespace is
+// deleted after the objects in it. Objects which were only updated keep their changes. It reports whether
+// every created object was deleted.
+func rollbackCreatedObjects(created []*manifestObject, results []models.ApplyResult) bool {
+	propagationPolicy := metav1.DeletePropagationBackground
+	rolledBack := true
+	for i := len(created) - 1; i >= 0; i-- {
+		object := created[i]
+		deleteErr := object.resourceInterface.Delete(context.TODO(), object.object.GetName(), metav1.DeleteOptions{PropagationPolicy: &propagationPolicy})
+		if deleteErr != nil && !errors.IsNotFound(deleteErr) {
+			err := handleKubernetesError(deleteErr)
+			results[object.resultIndex].Error = &models.ModelError{Code: err.Code, Message: fmt.Sprintf("Rollback failed: %s", err.Message)}
+			rolledBack = false
+			continue
+		}
+		results[object.resultIndex].RolledBack = true
+	}
+	return rolledBack
+}
@@ -0,0 +1,208 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+type deleteResourceInterface struct {
+	dynamic.ResourceInterface
+	err     error
+	deleted []string
+}
+
+func (d *deleteResourceInterface) Delete(ctx context.Context, name string, options metav1.DeleteOptions, subresources ...string) error {
+	d.deleted = append(d.deleted, name)
+	return d.err
+}
+
+func newTestManifestObject(apiVersion string, kind string, name string) *manifestObject {
+	object := &unstructured.Unstructured{}
+	object.SetAPIVersion(apiVersion)
+	object.SetKind(kind)
+	object.SetName(name)
+	return &manifestObject{object: object}
+}
+
+func TestDecodeManifestDocument(t *testing.T) {
+	items, err := decodeManifestDocument(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "settings"},
+		"data":       map[string]interface{}{"replicas": 3},
+	}, "payments")
+	assert.Nil(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, "settings", items[0].GetName())
+	// Numbers are converted to the types of unstructured objects, so that the objects can be copied
+	assert.NotPanics(t, func() { items[0].DeepCopy() })
+}
+
+func TestDecodeManifestDocumentList(t *testing.T) {
+	items, err := decodeManifestDocument(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items": []interface{}{
+			map[string]interface{}{"apiVersion": "v1", "kind": "Service", "metadata": map[string]interface{}{"name": "api"}},
+			map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": map[string]interface{}{"name": "api"}},
+		},
+	}, "")
+	assert.Nil(t, err)
+	assert.Len(t, items, 2)
+	assert.Equal(t, "Service", items[0].GetKind())
+	assert.Equal(t, "Deployment", items[1].GetKind())
+}
+
+func TestDecodeManifestDocumentInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		document interface{}
+	}{
+		{"not an object", []interface{}{"a", "b"}},
+		{"no kind", map[string]interface{}{"apiVersion": "v1", "metadata": map[string]interface{}{"name": "api"}}},
+		{"no name", map[string]interface{}{"apiVersion": "v1", "kind": "Service"}},
+		{"other namespace", map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"metadata":   map[string]interface{}{"name": "api", "namespace": "billing"},
+		}},
+		{"invalid list item", map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "List",
+			"items":      []interface{}{map[string]interface{}{"apiVersion": "v1", "kind": "Service"}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeManifestDocument(tt.document, "payments")
+			assert.NotNil(t, err)
+			assert.Equal(t, int32(400), err.Code)
+		})
+	}
+}
+
+func TestSortByApplyOrder(t *testing.T) {
+	objects := []*manifestObject{
+		newTestManifestObject("example.com/v1", "Widget", "widget"),
+		newTestManifestObject("networking.k8s.io/v1", "Ingress", "api"),
+		newTestManifestObject("apps/v1", "Deployment", "api"),
+		newTestManifestObject("v1", "Service", "api"),
+		newTestManifestObject("v1", "ConfigMap", "first"),
+		newTestManifestObject("apiextensions.k8s.io/v1", "CustomResourceDefinition", "widgets.example.com"),
+		newTestManifestObject("v1", "ConfigMap", "second"),
+		newTestManifestObject("v1", "Namespace", "payments"),
+	}
+
+	sortByApplyOrder(objects)
+
+	order := make([]string, 0)
+	for _, object := range objects {
+		order = append(order, object.object.GetKind()+"/"+object.object.GetName())
+	}
+	assert.Equal(t, []string{
+		"Namespace/payments",
+		"CustomResourceDefinition/widgets.example.com",
+		"ConfigMap/first",
+		"ConfigMap/second",
+		"Service/api",
+		"Deployment/api",
+		"Ingress/api",
+		"Widget/widget",
+	}, order)
+}
+
+func TestHasSameContent(t *testing.T) {
+	current := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "settings", "resourceVersion": "1"},
+		"data":       map[string]interface{}{"mode": "fast"},
+	}}
+	applied := current.DeepCopy()
+	applied.SetResourceVersion("2")
+	applied.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: FieldManager, Operation: metav1.ManagedFieldsOperationApply}})
+	assert.True(t, hasSameContent(current, applied))
+
+	assert.Nil(t, unstructured.SetNestedField(applied.Object, "slow", "data", "mode"))
+	assert.False(t, hasSameContent(current, applied))
+}
+
+func TestIsEstablished(t *testing.T) {
+	definition := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{"conditions": []interface{}{
+			map[string]interface{}{"type": "NamesAccepted", "status": "True"},
+			map[string]interface{}{"type": "Established", "status": "False"},
+		}},
+	}}
+	assert.False(t, isEstablished(definition))
+
+	conditions, _, _ := unstructured.NestedSlice(definition.Object, "status", "conditions")
+	conditions[1].(map[string]interface{})["status"] = "True"
+	assert.Nil(t, unstructured.SetNestedSlice(definition.Object, conditions, "status", "conditions"))
+	assert.True(t, isEstablished(definition))
+}
+
+func TestRollbackCreatedObjects(t *testing.T) {
+	resourceInterface := &deleteResourceInterface{}
+	namespace := newTestManifestObject("v1", "Namespace", "payments")
+	namespace.resourceInterface = resourceInterface
+	service := newTestManifestObject("v1", "Service", "api")
+	service.resourceInterface = resourceInterface
+	service.resultIndex = 1
+	results := []models.ApplyResult{{Action: applyActionCreated}, {Action: applyActionCreated}, {Action: applyActionFailed}}
+
+	rolledBack := rollbackCreatedObjects([]*manifestObject{namespace, service}, results)
+
+	assert.True(t, rolledBack)
+	assert.Equal(t, []string{"api", "payments"}, resourceInterface.deleted)
+	assert.True(t, results[0].RolledBack)
+	assert.True(t, results[1].RolledBack)
+	assert.False(t, results[2].RolledBack)
+}
+
+func TestRollbackCreatedObjectsError(t *testing.T) {
+	resourceInterface := &deleteResourceInterface{
+		err: apierrors.NewForbidden(schema.GroupResource{Resource: "services"}, "api", nil),
+	}
+	service := newTestManifestObject("v1", "Service", "api")
+	service.resourceInterface = resourceInterface
+	results := []models.ApplyResult{{Action: applyActionCreated}}
+
+	rolledBack := rollbackCreatedObjects([]*manifestObject{service}, results)
+
+	assert.False(t, rolledBack)
+	assert.False(t, results[0].RolledBack)
+	assert.NotNil(t, results[0].Error)
+	assert.Equal(t, int32(403), results[0].Error.Code)
+}
+
+func TestApplyManifestAtomicInvalidDocument(t *testing.T) {
+	getResourceInterface := func(resourceType, namespace, emptyNamespace string) (dynamic.ResourceInterface, *models.ModelError) {
+		t.Fatal("no object should be applied")
+		return nil, nil
+	}
+	documents := []interface{}{
+		map[string]interface{}{"apiVersion": "v1", "kind": "Service", "metadata": map[string]interface{}{"name": "api"}},
+		map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap"},
+	}
+
+	report := ApplyManifest(documents, ApplyOptions{Atomic: true}, nil, getResourceInterface)
+
+	assert.False(t, report.Succeeded)
+	assert.True(t, report.RolledBack)
+	assert.Len(t, report.Results, 2)
+	assert.Equal(t, 2, report.Results[0].Document)
+	assert.Equal(t, applyActionFailed, report.Results[0].Action)
+	assert.Equal(t, 1, report.Results[1].Document)
+	assert.Equal(t, applyActionSkipped, report.Results[1].Action)
+	assert.Equal(t, "api", report.Results[1].Name)
+}
@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/homedir"
 	"log"
@@ -17,6 +18,7 @@ import (
 type ClientSingleton struct {
 	config        *rest.Config
 	dynamicClient *dynamic.DynamicClient
+	clientset     *kubernetes.Clientset
 }
 
 var (
@@ -74,9 +76,16 @@ func GetInstance() (*ClientSingleton, error) {
 			return
 		}
 
+		clientset, innerErr := kubernetes.NewForConfig(config)
+		if innerErr != nil {
+			err = fmt.Errorf("failed to create clientset: %w", innerErr)
+			return
+		}
+
 		instance = &ClientSingleton{
 			config:        config,
 			dynamicClient: dynamicClient,
+			clientset:     clientset,
 		}
 	})
 
@@ -96,6 +105,15 @@ func GetClientSet() (*dynamic.DynamicClient, error) {
 	return singleton.dynamicClient, nil
 }
 
+func GetTypedClientSet() (*kubernetes.Clientset, error) {
+	singleton, err := GetInstance()
+	if err != nil {
+		return nil, err
+	}
+
+	return singleton.clientset, nil
+}
+
 func GetConfig() (*rest.Config, error) {
 	singleton, err := GetInstance()
 	if err != nil {
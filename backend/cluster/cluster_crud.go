@@ -2,16 +2,36 @@ package cluster
 
 import (
 	"context"
+	"fmt"
 	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
 const DefaultNamespace = "default"
 
+// getDryRunOption makes the API server validate and admit a change, running all admission plugins and webhooks,
+// without persisting it. The returned object shows the resource as it would have been stored.
+func getDryRunOption(dryRun bool) []string {
+	if dryRun {
+		return []string{metav1.DryRunAll}
+	}
+	return nil
+}
+
+// FieldManager is the name under which the fields set by patches of KAM are tracked by the API server
+const FieldManager = "kubernetes-access-manager"
+
 type ResourceInterfaceGetter func (resourceType string, namespace string, DefaultNamespace string) (dynamic.ResourceInterface, *models.ModelError)
 
+type ClientsetGetter func() (kubernetes.Interface, *models.ModelError)
+
+type ConfigGetter func() (*rest.Config, *models.ModelError)
+
 func GetResource(resourceType string, namespace string, resourceName string, getResourceInterface ResourceInterfaceGetter) (models.ResourceDetails, *models.ModelError) {
 	resourceInterface, err := getResourceInterface(resourceType, namespace, DefaultNamespace)
 	if err != nil {
@@ -24,10 +44,11 @@ func GetResource(resourceType string, namespace string, resourceName string, get
 		return models.ResourceDetails{}, handleKubernetesError(getErr)
 	}
 
+	resource = redactSecret(resourceType, resource)
 	return models.ResourceDetails{ResourceDetails: &resource}, nil
 }
 
-func CreateResource(resourceType string, namespace string, resource models.ResourceDetails, getResourceInterface ResourceInterfaceGetter) (models.ResourceDetails, *models.ModelError) {
+func CreateResource(resourceType string, namespace string, resource models.ResourceDetails, dryRun bool, getResourceInterface ResourceInterfaceGetter) (models.ResourceDetails, *models.ModelError) {
 	resourceInterface, err := getResourceInterface(resourceType, namespace, DefaultNamespace)
 	if err != nil {
 		return models.ResourceDetails{}, err
@@ -47,21 +68,43 @@ func CreateResource(resourceType string, namespace string, resource models.Resou
 	}
 
 	var createdResource interface{}
-	createdResource, createErr := resourceInterface.Create(context.TODO(), resourceDefinition, metav1.CreateOptions{})
+	createdResource, createErr := resourceInterface.Create(context.TODO(), resourceDefinition, metav1.CreateOptions{DryRun: getDryRunOption(dryRun)})
 	if createErr != nil {
 		return models.ResourceDetails{}, handleKubernetesError(createErr)
 	}
 
+	createdResource = redactSecret(resourceType, createdResource)
 	return models.ResourceDetails{ResourceDetails: &createdResource}, nil
 }
 
-func DeleteResource(resourceType string, namespace string, resourceName string, getResourceInterface ResourceInterfaceGetter) *models.ModelError {
+// DeleteOptions control how a resource is deleted. Empty fields leave the choice to the API server, e.g. the
+// default propagation policy of the resource type or the grace period from the Pod spec.
+type DeleteOptions struct {
+	DryRun bool
+	// PropagationPolicy is Foreground, Background or Orphan
+	PropagationPolicy  string
+	GracePeriodSeconds *int64
+	// Uid and ResourceVersion are preconditions, the resource is not deleted if it was replaced or changed
+	Uid             string
+	ResourceVersion string
+}
+
+// IsForce tells whether the resource is deleted without waiting for its graceful termination.
+func (o DeleteOptions) IsForce() bool {
+	return o.GracePeriodSeconds != nil && *o.GracePeriodSeconds == 0
+}
+
+func DeleteResource(resourceType string, namespace string, resourceName string, options DeleteOptions, getResourceInterface ResourceInterfaceGetter) *models.ModelError {
+	deleteOptions, err := toDeleteOptions(options)
+	if err != nil {
+		return err
+	}
 	resourceInterface, err := getResourceInterface(resourceType, namespace, DefaultNamespace)
 	if err != nil {
 		return err
 	}
 
-	deleteErr := resourceInterface.Delete(context.TODO(), resourceName, metav1.DeleteOptions{})
+	deleteErr := resourceInterface.Delete(context.TODO(), resourceName, deleteOptions)
 	if deleteErr != nil {
 		return handleKubernetesError(deleteErr)
 	}
@@ -69,7 +112,7 @@ func DeleteResource(resourceType string, namespace string, resourceName string,
 	return nil
 }
 
-func UpdateResource(resourceType string, namespace string, resourceName string, resource models.ResourceDetails, getResourceInterface ResourceInterfaceGetter) (models.ResourceDetails, *models.ModelError) {
+func UpdateResource(resourceType string, namespace string, resourceName string, resource models.ResourceDetails, dryRun bool, getResourceInterface ResourceInterfaceGetter) (models.ResourceDetails, *models.ModelError) {
 	resourceInterface, err := getResourceInterface(resourceType, namespace, DefaultNamespace)
 	if err != nil {
 		return models.ResourceDetails{}, err
@@ -87,11 +130,79 @@ func UpdateResource(resourceType string, namespace string, resourceName string,
 		return models.ResourceDetails{}, &models.ModelError{Code: 400, Message: "Invalid Input: Different resource names"}
 	}
 
+	if resourceType == secretString {
+		if err := restoreRedactedSecretValues(resourceInterface, unstructuredResource); err != nil {
+			return models.ResourceDetails{}, err
+		}
+	}
+
 	var updatedResource interface{}
-	updatedResource, updateErr := resourceInterface.Update(context.TODO(), unstructuredResource, metav1.UpdateOptions{})
+	updatedResource, updateErr := resourceInterface.Update(context.TODO(), unstructuredResource, metav1.UpdateOptions{DryRun: getDryRunOption(dryRun)})
 	if updateErr != nil {
 		return models.ResourceDetails{}, handleKubernetesError(updateErr)
 	}
 
+	updatedResource = redactSecret(resourceType, updatedResource)
 	return models.ResourceDetails{ResourceDetails: &updatedResource}, nil
 }
+
+// PatchResource changes only the fields of a resource given in the patch, so unlike UpdateResource it does not
+// conflict with changes made by controllers in the meantime. Force takes over fields owned by other managers
+// and is only allowed for server-side apply.
+func PatchResource(resourceType string, namespace string, resourceName string, patchType types.PatchType, patch []byte, force bool, dryRun bool, getResourceInterface ResourceInterfaceGetter) (models.ResourceDetails, *models.ModelError) {
+	if len(patch) == 0 {
+		return models.ResourceDetails{}, &models.ModelError{Code: 400, Message: "Empty patch"}
+	}
+	if force && patchType != types.ApplyPatchType {
+		return models.ResourceDetails{}, &models.ModelError{Code: 400, Message: "Force is only allowed for server-side apply"}
+	}
+
+	resourceInterface, err := getResourceInterface(resourceType, namespace, DefaultNamespace)
+	if err != nil {
+		return models.ResourceDetails{}, err
+	}
+
+	options := metav1.PatchOptions{FieldManager: FieldManager, DryRun: getDryRunOption(dryRun)}
+	if patchType == types.ApplyPatchType {
+		options.Force = &force
+	}
+
+	var patchedResource interface{}
+	patchedResource, patchErr := resourceInterface.Patch(context.TODO(), resourceName, patchType, patch, options)
+	if patchErr != nil {
+		return models.ResourceDetails{}, handleKubernetesError(patchErr)
+	}
+
+	patchedResource = redactSecret(resourceType, patchedResource)
+	return models.ResourceDetails{ResourceDetails: &patchedResource}, nil
+}
+
+func toDeleteOptions(options DeleteOptions) (metav1.DeleteOptions, *models.ModelError) {
+	if options.GracePeriodSeconds != nil && *options.GracePeriodSeconds < 0 {
+		return metav1.DeleteOptions{}, &models.ModelError{Code: 400, Message: "Grace period must not be negative"}
+	}
+	deleteOptions := metav1.DeleteOptions{DryRun: getDryRunOption(options.DryRun), GracePeriodSeconds: options.GracePeriodSeconds}
+
+	switch policy := metav1.DeletionPropagation(options.PropagationPolicy); policy {
+	case "":
+	case metav1.DeletePropagationForeground, metav1.DeletePropagationBackground, metav1.DeletePropagationOrphan:
+		deleteOptions.PropagationPolicy = &policy
+	default:
+		return metav1.DeleteOptions{}, &models.ModelError{
+			Code:    400,
+			Message: fmt.Sprintf("Invalid propagation policy: %s, expected Foreground, Background or Orphan", policy),
+		}
+	}
+
+	if options.Uid != "" || options.ResourceVersion != "" {
+		deleteOptions.Preconditions = &metav1.Preconditions{}
+		if options.Uid != "" {
+			uid := types.UID(options.Uid)
+			deleteOptions.Preconditions.UID = &uid
+		}
+		if options.ResourceVersion != "" {
+			deleteOptions.Preconditions.ResourceVersion = &options.ResourceVersion
+		}
+	}
+	return deleteOptions, nil
+}
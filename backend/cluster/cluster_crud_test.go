@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 )
 
@@ -17,6 +18,10 @@ type MockResourceInterface struct {
 	mock.Mock
 	ReturnedValue *unstructured.Unstructured
 	ReturnedError error
+	PatchType     types.PatchType
+	PatchOptions  metav1.PatchOptions
+	DryRun        []string
+	DeleteOptions metav1.DeleteOptions
 }
 
 func (m *MockResourceInterface) Get(ctx context.Context, name string, 
@@ -26,16 +31,28 @@ func (m *MockResourceInterface) Get(ctx context.Context, name string,
 
 func (m *MockResourceInterface) Create(ctx context.Context, obj *unstructured.Unstructured, 
 	options metav1.CreateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	m.DryRun = options.DryRun
 	return m.ReturnedValue, m.ReturnedError
 }
 
 func (m *MockResourceInterface) Delete(ctx context.Context, name string, 
 	options metav1.DeleteOptions, subresources ...string) error {
+	m.DryRun = options.DryRun
+	m.DeleteOptions = options
 	return m.ReturnedError
 }
 
 func (m *MockResourceInterface) Update(ctx context.Context, obj *unstructured.Unstructured, 
 	options metav1.UpdateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	m.DryRun = options.DryRun
+	return m.ReturnedValue, m.ReturnedError
+}
+
+func (m *MockResourceInterface) Patch(ctx context.Context, name string, pt types.PatchType, data []byte,
+	options metav1.PatchOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	m.PatchType = pt
+	m.PatchOptions = options
+	m.DryRun = options.DryRun
 	return m.ReturnedValue, m.ReturnedError
 }
 
@@ -132,7 +149,7 @@ func TestCreateResourceError(t *testing.T) {
 	}
 
 	t.Run("Test CreateResourceError", func(t *testing.T) {
-		_, err := CreateResource("Pod", "validNamespace", models.ResourceDetails{}, getResourceI)
+		_, err := CreateResource("Pod", "validNamespace", models.ResourceDetails{}, false, getResourceI)
 		assert.NotNil(t, err)
 		assert.Equal(t, &models.ModelError{Code: 404, Message: "Not found"}, err)
 	})
@@ -151,7 +168,7 @@ func TestCreateResourceSuccess(t *testing.T) {
 		result, err := CreateResource("Pod", "validNamespace", *MockResourceDetailsMap(map[string]interface{}{
 			"key": "value", 
 			"namespace": "validNamespace",
-			}), getResourceI)
+			}), false, getResourceI)
 		assert.Nil(t, err)
 
 		expectedResourceDetails := MockResourceDetails()
@@ -176,7 +193,7 @@ func TestCreateResourceErrorFromCreate(t *testing.T) {
 		res, err := CreateResource("Pod", "validNamespace", *MockResourceDetailsMap(map[string]interface{}{
 			"key": "value",
 			"namespace": "validNamespace",
-		}), getResourceI)
+		}), false, getResourceI)
 
 		assert.NotNil(t, err)
 		assert.EqualValues(t, 500, err.Code)
@@ -194,7 +211,7 @@ func TestDeleteResourceError(t *testing.T) {
 	}
 
 	t.Run("Test DeleteResourceError", func(t *testing.T) {
-		err := DeleteResource("Pod", "validNamespace", "validName", getResourceI)
+		err := DeleteResource("Pod", "validNamespace", "validName", DeleteOptions{}, getResourceI)
 		assert.NotNil(t, err)
 		assert.Equal(t, &models.ModelError{Code: 404, Message: "Not found"}, err)
 	})
@@ -209,7 +226,7 @@ func TestDeleteResourceSuccess(t *testing.T) {
 	}
 
 	t.Run("Test DeleteResource", func(t *testing.T) {
-		err := DeleteResource("Pod", "validNamespace", "validName", getResourceI)
+		err := DeleteResource("Pod", "validNamespace", "validName", DeleteOptions{}, getResourceI)
 		assert.Nil(t, err)
 	})
 }
@@ -222,7 +239,7 @@ func TestDeleteResourceErrorFromDelete(t *testing.T) {
 		return args.Get(0).(dynamic.ResourceInterface), nil
 	}
 	t.Run("Test DeleteResourceErrorFromDelete", func(t *testing.T) {
-		err := DeleteResource("Pod", "validNamespace", "validName", getResourceI)
+		err := DeleteResource("Pod", "validNamespace", "validName", DeleteOptions{}, getResourceI)
 
 		assert.NotNil(t, err)
 		assert.EqualValues(t, 500, err.Code)
@@ -239,7 +256,7 @@ func TestUpdateResourceError(t *testing.T) {
 	}
 
 	t.Run("Test UpdateResourceError", func(t *testing.T) {
-		_, err := UpdateResource("Pod", "validNamespace", "validName", *MockResourceDetails(), getResourceI)
+		_, err := UpdateResource("Pod", "validNamespace", "validName", *MockResourceDetails(), false, getResourceI)
 		assert.NotNil(t, err)
 		assert.Equal(t, &models.ModelError{Code: 404, Message: "Not found"}, err)
 	})
@@ -262,7 +279,7 @@ func TestUpdateResourceSuccess(t *testing.T) {
 	}
 
 	t.Run("Test UpdateResource", func(t *testing.T) {
-		result, err := UpdateResource("Pod", "validNamespace", "validName", *MockResourceDetailsMap(expected), getResourceI)
+		result, err := UpdateResource("Pod", "validNamespace", "validName", *MockResourceDetailsMap(expected), false, getResourceI)
 		assert.Nil(t, err)
 		resultObj := (*result.ResourceDetails).(*unstructured.Unstructured)
 		for key, value := range expected {
@@ -286,7 +303,7 @@ func TestUpdateResourceErrorFromUpdate (t *testing.T) {
 		return args.Get(0).(dynamic.ResourceInterface), nil
 	}
 	t.Run("Test UpdateResourceErrorFromUpdate", func(t *testing.T) {
-		res, err := UpdateResource("Pod", "validNamespace", "validName", *MockResourceDetailsMap(dummy), getResourceI)
+		res, err := UpdateResource("Pod", "validNamespace", "validName", *MockResourceDetailsMap(dummy), false, getResourceI)
 
 		assert.NotNil(t, err)
 		assert.EqualValues(t, 500, err.Code)
@@ -312,7 +329,7 @@ func TestUpdateResourceWrongName (t *testing.T) {
 	}
 
 	t.Run("Test UpdateResource", func(t *testing.T) {
-		_, err := UpdateResource("Pod", "validNamespace", "validName", *MockResourceDetailsMap(expected), getResourceI)
+		_, err := UpdateResource("Pod", "validNamespace", "validName", *MockResourceDetailsMap(expected), false, getResourceI)
 
 		assert.NotNil(t, err)
 		assert.EqualValues(t, 400, err.Code)
@@ -320,10 +337,155 @@ func TestUpdateResourceWrongName (t *testing.T) {
 	})
 }
 
+func TestPatchResourceSuccess(t *testing.T) {
+	expected := map[string]interface{}{
+		"key":       "value",
+		"namespace": "validNamespace",
+		"metadata":  map[string]interface{}{"name": "validName"},
+	}
+	resourceInterface := &MockResourceInterface{ReturnedValue: &unstructured.Unstructured{Object: expected}}
+	getResourceI := func(resourceType string, namespace string, emptyNamespace string) (dynamic.ResourceInterface, *models.ModelError) {
+		return resourceInterface, nil
+	}
 
+	t.Run("Test PatchResource", func(t *testing.T) {
+		result, err := PatchResource("Pod", "validNamespace", "validName", types.MergePatchType, []byte(`{"key":"value"}`), false, false, getResourceI)
+		assert.Nil(t, err)
+		resultObj := (*result.ResourceDetails).(*unstructured.Unstructured)
+		assert.EqualValues(t, expected, resultObj.Object)
+		assert.Equal(t, types.MergePatchType, resourceInterface.PatchType)
+		assert.Equal(t, FieldManager, resourceInterface.PatchOptions.FieldManager)
+		assert.Nil(t, resourceInterface.PatchOptions.Force)
+	})
 
+	t.Run("Test PatchResource with forced apply", func(t *testing.T) {
+		_, err := PatchResource("Pod", "validNamespace", "validName", types.ApplyPatchType, []byte(`{"key":"value"}`), true, false, getResourceI)
+		assert.Nil(t, err)
+		assert.Equal(t, types.ApplyPatchType, resourceInterface.PatchType)
+		assert.NotNil(t, resourceInterface.PatchOptions.Force)
+		assert.True(t, *resourceInterface.PatchOptions.Force)
+	})
+}
 
+func TestPatchResourceInvalidInput(t *testing.T) {
+	getResourceI := func(resourceType string, namespace string, emptyNamespace string) (dynamic.ResourceInterface, *models.ModelError) {
+		return &MockResourceInterface{}, nil
+	}
 
+	t.Run("Test PatchResource with empty patch", func(t *testing.T) {
+		_, err := PatchResource("Pod", "validNamespace", "validName", types.JSONPatchType, nil, false, false, getResourceI)
+		assert.NotNil(t, err)
+		assert.EqualValues(t, 400, err.Code)
+	})
+
+	t.Run("Test PatchResource with force without apply", func(t *testing.T) {
+		_, err := PatchResource("Pod", "validNamespace", "validName", types.StrategicMergePatchType, []byte(`{}`), true, false, getResourceI)
+		assert.NotNil(t, err)
+		assert.EqualValues(t, 400, err.Code)
+	})
+}
+
+func TestPatchResourceErrorFromPatch(t *testing.T) {
+	getResourceI := func(resourceType string, namespace string, emptyNamespace string) (dynamic.ResourceInterface, *models.ModelError) {
+		return &MockResourceInterface{ReturnedError: errors.New("error")}, nil
+	}
 
+	t.Run("Test PatchResourceErrorFromPatch", func(t *testing.T) {
+		res, err := PatchResource("Pod", "validNamespace", "validName", types.MergePatchType, []byte(`{}`), false, false, getResourceI)
 
+		assert.NotNil(t, err)
+		assert.EqualValues(t, 500, err.Code)
+		assert.EqualValues(t, "Internal server error: error", err.Message)
+		assert.EqualValues(t, res, models.ResourceDetails{})
+	})
+}
 
+func TestDryRun(t *testing.T) {
+	resource := map[string]interface{}{
+		"kind":     "Pod",
+		"metadata": map[string]interface{}{"name": "validName"},
+	}
+	resourceInterface := &MockResourceInterface{ReturnedValue: &unstructured.Unstructured{Object: resource}}
+	getResourceI := func(resourceType string, namespace string, emptyNamespace string) (dynamic.ResourceInterface, *models.ModelError) {
+		return resourceInterface, nil
+	}
+
+	t.Run("Test CreateResource with dry run", func(t *testing.T) {
+		resourceInterface.DryRun = nil
+		_, err := CreateResource("Pod", "validNamespace", *MockResourceDetailsMap(resource), true, getResourceI)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{metav1.DryRunAll}, resourceInterface.DryRun)
+	})
+
+	t.Run("Test UpdateResource with dry run", func(t *testing.T) {
+		resourceInterface.DryRun = nil
+		_, err := UpdateResource("Pod", "validNamespace", "validName", *MockResourceDetailsMap(resource), true, getResourceI)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{metav1.DryRunAll}, resourceInterface.DryRun)
+	})
+
+	t.Run("Test PatchResource with dry run", func(t *testing.T) {
+		resourceInterface.DryRun = nil
+		_, err := PatchResource("Pod", "validNamespace", "validName", types.MergePatchType, []byte(`{}`), false, true, getResourceI)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{metav1.DryRunAll}, resourceInterface.DryRun)
+	})
+
+	t.Run("Test DeleteResource with dry run", func(t *testing.T) {
+		resourceInterface.DryRun = nil
+		err := DeleteResource("Pod", "validNamespace", "validName", DeleteOptions{DryRun: true}, getResourceI)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{metav1.DryRunAll}, resourceInterface.DryRun)
+	})
+
+	t.Run("Test DeleteResource without dry run", func(t *testing.T) {
+		resourceInterface.DryRun = []string{metav1.DryRunAll}
+		err := DeleteResource("Pod", "validNamespace", "validName", DeleteOptions{}, getResourceI)
+		assert.Nil(t, err)
+		assert.Nil(t, resourceInterface.DryRun)
+	})
+}
+
+func TestDeleteResourceOptions(t *testing.T) {
+	resourceInterface := &MockResourceInterface{}
+	getResourceI := func(resourceType string, namespace string, emptyNamespace string) (dynamic.ResourceInterface, *models.ModelError) {
+		return resourceInterface, nil
+	}
+	gracePeriod := int64(0)
+
+	err := DeleteResource("Pod", "validNamespace", "validName", DeleteOptions{
+		PropagationPolicy:  "Orphan",
+		GracePeriodSeconds: &gracePeriod,
+		Uid:                "pod-uid",
+		ResourceVersion:    "42",
+	}, getResourceI)
+
+	assert.Nil(t, err)
+	options := resourceInterface.DeleteOptions
+	assert.Equal(t, metav1.DeletePropagationOrphan, *options.PropagationPolicy)
+	assert.Equal(t, int64(0), *options.GracePeriodSeconds)
+	assert.Equal(t, types.UID("pod-uid"), *options.Preconditions.UID)
+	assert.Equal(t, "42", *options.Preconditions.ResourceVersion)
+}
+
+func TestDeleteResourceInvalidOptions(t *testing.T) {
+	resourceInterface := &MockResourceInterface{}
+	getResourceI := func(resourceType string, namespace string, emptyNamespace string) (dynamic.ResourceInterface, *models.ModelError) {
+		return resourceInterface, nil
+	}
+	negativeGracePeriod := int64(-1)
+
+	err := DeleteResource("Pod", "validNamespace", "validName", DeleteOptions{PropagationPolicy: "Cascade"}, getResourceI)
+	assert.Equal(t, int32(400), err.Code)
+
+	err = DeleteResource("Pod", "validNamespace", "validName", DeleteOptions{GracePeriodSeconds: &negativeGracePeriod}, getResourceI)
+	assert.Equal(t, int32(400), err.Code)
+}
+
+func TestDeleteOptionsIsForce(t *testing.T) {
+	zero, thirty := int64(0), int64(30)
+
+	assert.False(t, DeleteOptions{}.IsForce())
+	assert.False(t, DeleteOptions{GracePeriodSeconds: &thirty}.IsForce())
+	assert.True(t, DeleteOptions{GracePeriodSeconds: &zero}.IsForce())
+}
@@ -0,0 +1,226 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+)
+
+const (
+	invalidationReasonPeriodic = "periodic"
+	invalidationReasonCRD      = "crd"
+	invalidationReasonMiss     = "miss"
+
+	// cacheMissRetryInterval limits how often an unknown resource type may force a discovery refresh
+	cacheMissRetryInterval = 10 * time.Second
+)
+
+// crdWatchBackoff spaces out attempts to restore the CRD watch, from one second up to two minutes.
+var crdWatchBackoff = wait.Backoff{Duration: time.Second, Factor: 2, Jitter: 0.1, Steps: 10, Cap: 2 * time.Minute}
+
+var customResourceDefinitionGVR = schema.GroupVersionResource{
+	Group:    "apiextensions.k8s.io",
+	Version:  "v1",
+	Resource: "customresourcedefinitions",
+}
+
+type discoveryCache struct {
+	client           discovery.CachedDiscoveryInterface
+	mapper           *restmapper.DeferredDiscoveryRESTMapper
+	mutex            sync.Mutex
+	lastInvalidation time.Time
+}
+
+var (
+	discoveryCacheInstance *discoveryCache
+	discoveryCacheOnce     sync.Once
+	discoveryCacheErr      error
+)
+
+func getDiscoveryCache() (*discoveryCache, error) {
+	discoveryCacheOnce.Do(func() {
+		config, err := GetConfig()
+		if err != nil {
+			discoveryCacheErr = fmt.Errorf("failed to get config: %w", err)
+			return
+		}
+
+		discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+		if err != nil {
+			discoveryCacheErr = fmt.Errorf("failed to get discovery client: %w", err)
+			return
+		}
+
+		cachedClient := memory.NewMemCacheClient(discoveryClient)
+		discoveryCacheInstance = &discoveryCache{
+			client: cachedClient,
+			mapper: restmapper.NewDeferredDiscoveryRESTMapper(cachedClient),
+		}
+	})
+
+	if discoveryCacheInstance == nil {
+		return nil, discoveryCacheErr
+	}
+	return discoveryCacheInstance, nil
+}
+
+// restMapping resolves a kind of a given group through the REST mapper. The version is optional, the
+// preferred one is used without it. found is false when the group does not serve the kind.
+func (c *discoveryCache) restMapping(name resourceTypeName) (resolved resolvedResourceType, found bool, err error) {
+	defer observeDiscoveryDuration(c.client.Fresh(), time.Now())
+
+	var versions []string
+	if name.Version != "" {
+		versions = append(versions, name.Version)
+	}
+	mapping, err := c.mapper.RESTMapping(schema.GroupKind{Group: name.Group, Kind: name.Kind}, versions...)
+	if meta.IsNoMatchError(err) {
+		return resolvedResourceType{}, false, nil
+	}
+	if err != nil {
+		return resolvedResourceType{}, false, err
+	}
+	return resolvedResourceType{
+		GroupVersionResource: mapping.Resource,
+		Kind:                 mapping.GroupVersionKind.Kind,
+		Namespaced:           mapping.Scope.Name() == meta.RESTScopeNameNamespace,
+	}, true, nil
+}
+
+// serverResourcesForGroupVersion returns the cached resources of one group version, nil when it is not served.
+func (c *discoveryCache) serverResourcesForGroupVersion(groupVersion string) ([]*metav1.APIResourceList, error) {
+	defer observeDiscoveryDuration(c.client.Fresh(), time.Now())
+
+	apiResourceList, err := c.client.ServerResourcesForGroupVersion(groupVersion)
+	if err == memory.ErrCacheNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []*metav1.APIResourceList{apiResourceList}, nil
+}
+
+// serverPreferredResources returns the cached resources of the preferred version of every group.
+// Groups that failed discovery, typically unavailable aggregated APIs, are logged and left out.
+func (c *discoveryCache) serverPreferredResources() ([]*metav1.APIResourceList, error) {
+	defer observeDiscoveryDuration(c.client.Fresh(), time.Now())
+
+	apiResourceLists, err := c.client.ServerPreferredResources()
+	if err != nil {
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			return nil, err
+		}
+		log.Printf("Partial discovery failure: %v", err)
+	}
+	return apiResourceLists, nil
+}
+
+func (c *discoveryCache) invalidate(reason string) {
+	c.mutex.Lock()
+	c.lastInvalidation = time.Now()
+	c.mutex.Unlock()
+
+	c.mapper.Reset()
+	discoveryCacheInvalidations.WithLabelValues(reason).Inc()
+}
+
+// invalidateAfterMiss invalidates the cache for a resource type missing from it, unless that
+// already happened recently. It reports whether resolution is worth retrying.
+func (c *discoveryCache) invalidateAfterMiss() bool {
+	c.mutex.Lock()
+	if time.Since(c.lastInvalidation) < cacheMissRetryInterval {
+		c.mutex.Unlock()
+		return false
+	}
+	c.mutex.Unlock()
+
+	c.invalidate(invalidationReasonMiss)
+	return true
+}
+
+// RefreshDiscoveryCachePeriodically invalidates the discovery cache every interval, so that changes
+// not covered by the CRD watch, e.g. new aggregated APIs, are eventually picked up.
+func RefreshDiscoveryCachePeriodically(interval time.Duration) {
+	cache, err := getDiscoveryCache()
+	if err != nil {
+		log.Printf("Discovery cache: %v", err)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		cache.invalidate(invalidationReasonPeriodic)
+	}
+}
+
+// WatchForCRDChanges invalidates the discovery cache whenever a CustomResourceDefinition
+// is added, changed or removed. Failed attempts to watch are retried with a growing delay.
+func WatchForCRDChanges() {
+	cache, err := getDiscoveryCache()
+	if err != nil {
+		log.Printf("Discovery cache: %v", err)
+		return
+	}
+
+	backoff := crdWatchBackoff
+	for {
+		dynamicClient, err := GetClientSet()
+		if err == nil {
+			err = watchCRDs(dynamicClient, cache)
+		}
+		if err != nil {
+			log.Printf("CRD watch: %v", err)
+			time.Sleep(backoff.Step())
+			continue
+		}
+		backoff = crdWatchBackoff
+		// Changes made while reconnecting would be missed otherwise
+		cache.invalidate(invalidationReasonCRD)
+	}
+}
+
+// watchCRDs invalidates the cache on CRD events until the server closes the watch.
+func watchCRDs(dynamicClient dynamic.Interface, cache *discoveryCache) error {
+	// Start from the current state, so the watch does not replay every existing CRD
+	crds, err := dynamicClient.Resource(customResourceDefinitionGVR).List(context.TODO(), metav1.ListOptions{Limit: 1})
+	if err != nil {
+		return fmt.Errorf("listing CRDs: %w", err)
+	}
+	watcher, err := dynamicClient.Resource(customResourceDefinitionGVR).Watch(context.TODO(),
+		metav1.ListOptions{ResourceVersion: crds.GetResourceVersion()})
+	if err != nil {
+		return fmt.Errorf("creating watcher: %w", err)
+	}
+	defer watcher.Stop()
+	invalidateOnCRDEvents(watcher.ResultChan(), cache)
+	return nil
+}
+
+func invalidateOnCRDEvents(eventChannel <-chan watch.Event, cache *discoveryCache) {
+	for {
+		event, open := <-eventChannel
+		if !open {
+			// The server has closed the connection.
+			return
+		}
+		switch event.Type {
+		case watch.Added, watch.Modified, watch.Deleted:
+			cache.invalidate(invalidationReasonCRD)
+		default:
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package cluster
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/discovery/cached/memory"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/restmapper"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newTestDiscoveryCache(resources []*metav1.APIResourceList) (*discoveryCache, *fakediscovery.FakeDiscovery) {
+	fakeDiscovery := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{Resources: resources}}
+	cachedClient := memory.NewMemCacheClient(fakeDiscovery)
+	return &discoveryCache{
+		client: cachedClient,
+		mapper: restmapper.NewDeferredDiscoveryRESTMapper(cachedClient),
+	}, fakeDiscovery
+}
+
+func TestDiscoveryCacheServesFromMemory(t *testing.T) {
+	cache, fakeDiscovery := newTestDiscoveryCache(mockAPIResourceLists())
+
+	lists, err := cache.serverResourcesForGroupVersion("apps/v1")
+	assert.NoError(t, err)
+	assert.Len(t, lists, 1)
+	callsAfterFirstLookup := len(fakeDiscovery.Actions())
+
+	lists, err = cache.serverResourcesForGroupVersion("apps/v1")
+	assert.NoError(t, err)
+	assert.Len(t, lists, 1)
+	assert.Equal(t, callsAfterFirstLookup, len(fakeDiscovery.Actions()))
+}
+
+func TestDiscoveryCacheUnknownGroupVersion(t *testing.T) {
+	cache, _ := newTestDiscoveryCache(mockAPIResourceLists())
+
+	lists, err := cache.serverResourcesForGroupVersion("unknown.example.com/v1")
+	assert.NoError(t, err)
+	assert.Nil(t, lists)
+}
+
+func TestDiscoveryCacheInvalidate(t *testing.T) {
+	cache, fakeDiscovery := newTestDiscoveryCache(mockAPIResourceLists())
+
+	_, err := cache.serverPreferredResources()
+	assert.NoError(t, err)
+	assert.True(t, cache.client.Fresh())
+
+	fakeDiscovery.Resources = append(fakeDiscovery.Resources, &metav1.APIResourceList{
+		GroupVersion: "kafka.strimzi.io/v1beta2",
+		APIResources: []metav1.APIResource{{Name: "kafkatopics", Kind: "KafkaTopic", Namespaced: true}},
+	})
+	cache.invalidate(invalidationReasonCRD)
+	assert.False(t, cache.client.Fresh())
+
+	lists, err := cache.serverResourcesForGroupVersion("kafka.strimzi.io/v1beta2")
+	assert.NoError(t, err)
+	assert.Len(t, lists, 1)
+}
+
+func TestDiscoveryCacheInvalidateAfterMiss(t *testing.T) {
+	cache, _ := newTestDiscoveryCache(mockAPIResourceLists())
+
+	assert.True(t, cache.invalidateAfterMiss())
+	assert.False(t, cache.invalidateAfterMiss())
+
+	cache.lastInvalidation = time.Now().Add(-cacheMissRetryInterval)
+	assert.True(t, cache.invalidateAfterMiss())
+}
+
+func TestDiscoveryCacheRESTMapping(t *testing.T) {
+	cache, _ := newTestDiscoveryCache(mockAPIResourceLists())
+
+	tests := []struct {
+		name          string
+		resourceType  resourceTypeName
+		expectedFound bool
+		expected      resolvedResourceType
+	}{
+		{
+			name:          "Core group with version",
+			resourceType:  resourceTypeName{Version: "v1", Kind: "Pod", GroupSet: true},
+			expectedFound: true,
+			expected: resolvedResourceType{
+				GroupVersionResource: schema.GroupVersionResource{Version: "v1", Resource: "pods"},
+				Kind:                 "Pod",
+				Namespaced:           true,
+			},
+		},
+		{
+			name:          "Cluster scoped kind of another group",
+			resourceType:  resourceTypeName{Group: "example.com", Kind: "Certificate", GroupSet: true},
+			expectedFound: true,
+			expected: resolvedResourceType{
+				GroupVersionResource: schema.GroupVersionResource{Group: "example.com", Version: "v1alpha1", Resource: "certificates"},
+				Kind:                 "Certificate",
+				Namespaced:           false,
+			},
+		},
+		{
+			name:          "Kind of a subresource",
+			resourceType:  resourceTypeName{Group: "apps", Kind: "Scale", GroupSet: true},
+			expectedFound: false,
+		},
+		{
+			name:          "Version not served",
+			resourceType:  resourceTypeName{Group: "apps", Version: "v1beta1", Kind: "Deployment", GroupSet: true},
+			expectedFound: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved, found, err := cache.restMapping(tt.resourceType)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedFound, found)
+			if tt.expectedFound {
+				assert.Equal(t, tt.expected, resolved)
+			}
+		})
+	}
+}
+
+func TestInvalidateOnCRDEvents(t *testing.T) {
+	cache, _ := newTestDiscoveryCache(mockAPIResourceLists())
+	_, err := cache.serverPreferredResources()
+	assert.NoError(t, err)
+
+	events := make(chan watch.Event, 1)
+	events <- watch.Event{Type: watch.Added}
+	close(events)
+
+	invalidateOnCRDEvents(events, cache)
+	assert.False(t, cache.client.Fresh())
+}
+
+func newTestCRDClient() *fakedynamic.FakeDynamicClient {
+	return fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{customResourceDefinitionGVR: "CustomResourceDefinitionList"})
+}
+
+func TestWatchCRDsListFailure(t *testing.T) {
+	cache, _ := newTestDiscoveryCache(mockAPIResourceLists())
+	client := newTestCRDClient()
+	client.PrependReactor("list", "customresourcedefinitions", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+
+	err := watchCRDs(client, cache)
+	assert.ErrorContains(t, err, "connection refused")
+}
+
+func TestWatchCRDsInvalidatesUntilClosed(t *testing.T) {
+	cache, _ := newTestDiscoveryCache(mockAPIResourceLists())
+	_, err := cache.serverPreferredResources()
+	assert.NoError(t, err)
+
+	client := newTestCRDClient()
+	watcher := watch.NewFakeWithChanSize(1, false)
+	client.PrependWatchReactor("customresourcedefinitions", k8stesting.DefaultWatchReactor(watcher, nil))
+	watcher.Add(&unstructured.Unstructured{})
+	watcher.Stop()
+
+	assert.NoError(t, watchCRDs(client, cache))
+	assert.False(t, cache.client.Fresh())
+}
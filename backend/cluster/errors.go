@@ -0,0 +1,97 @@
+package cluster
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// statusErrorTranslation is the HTTP code and message prefix of a reason reported by the API server.
+type statusErrorTranslation struct {
+	code   int32
+	prefix string
+}
+
+var statusErrorTranslations = map[metav1.StatusReason]statusErrorTranslation{
+	metav1.StatusReasonNotFound:              {http.StatusNotFound, "Resource not found"},
+	metav1.StatusReasonForbidden:             {http.StatusForbidden, "Forbidden"},
+	metav1.StatusReasonUnauthorized:          {http.StatusUnauthorized, "Unauthorized"},
+	metav1.StatusReasonExpired:               {http.StatusGone, "Continue token expired"},
+	metav1.StatusReasonGone:                  {http.StatusGone, "Gone"},
+	metav1.StatusReasonConflict:              {http.StatusConflict, "Conflict"},
+	metav1.StatusReasonAlreadyExists:         {http.StatusConflict, "Already exists"},
+	metav1.StatusReasonInvalid:               {http.StatusUnprocessableEntity, "Invalid resource"},
+	metav1.StatusReasonUnsupportedMediaType:  {http.StatusUnsupportedMediaType, "Unsupported media type"},
+	metav1.StatusReasonBadRequest:            {http.StatusBadRequest, "Bad request"},
+	metav1.StatusReasonMethodNotAllowed:      {http.StatusMethodNotAllowed, "Method not allowed"},
+	metav1.StatusReasonNotAcceptable:         {http.StatusNotAcceptable, "Not acceptable"},
+	metav1.StatusReasonRequestEntityTooLarge: {http.StatusRequestEntityTooLarge, "Request entity too large"},
+	metav1.StatusReasonTooManyRequests:       {http.StatusTooManyRequests, "Too many requests"},
+	// The API server reports ServerTimeout with 500, although like Timeout the request may be retried
+	metav1.StatusReasonServerTimeout:      {http.StatusGatewayTimeout, "Server timeout"},
+	metav1.StatusReasonTimeout:            {http.StatusGatewayTimeout, "Timeout"},
+	metav1.StatusReasonServiceUnavailable: {http.StatusServiceUnavailable, "Service unavailable"},
+	metav1.StatusReasonInternalError:      {http.StatusInternalServerError, "Internal server error"},
+}
+
+// handleKubernetesError translates an error of a request to the API server, falling back to 500 for errors
+// which did not come from the API server.
+func handleKubernetesError(err error) *models.ModelError {
+	return handleKubernetesErrorOr(err, 500, "Internal server error")
+}
+
+// handleKubernetesErrorOr translates an error of a request to the API server like handleKubernetesError,
+// falling back to the code and message prefix for errors which did not come from the API server.
+func handleKubernetesErrorOr(err error, code int32, prefix string) *models.ModelError {
+	if modelErr := FromKubernetesError(err); modelErr != nil {
+		return modelErr
+	}
+	return &models.ModelError{Code: code, Message: fmt.Sprintf("%s: %s", prefix, err)}
+}
+
+// FromKubernetesError translates a Status returned by the API server, also when wrapped, e.g. by Helm, into an error
+// with the matching HTTP code, the reason, the field-level causes and the suggested retry delay. It returns nil for
+// errors which did not come from the API server, except for timeouts of the request itself.
+func FromKubernetesError(err error) *models.ModelError {
+	var apiStatus errors.APIStatus
+	if !stderrors.As(err, &apiStatus) {
+		if stderrors.Is(err, context.DeadlineExceeded) {
+			return &models.ModelError{Code: http.StatusGatewayTimeout, Message: fmt.Sprintf("Timeout: %s", err), Reason: string(metav1.StatusReasonTimeout)}
+		}
+		return nil
+	}
+
+	status := apiStatus.Status()
+	translation, known := statusErrorTranslations[status.Reason]
+	if !known {
+		// Admission webhooks may reject requests with any code and reason
+		translation = statusErrorTranslation{code: status.Code, prefix: "Request rejected"}
+		if translation.code < 400 {
+			translation.code = http.StatusInternalServerError
+		}
+	}
+
+	modelErr := &models.ModelError{
+		Code:    translation.code,
+		Message: fmt.Sprintf("%s: %s", translation.prefix, err),
+		Reason:  string(status.Reason),
+	}
+	if status.Details != nil {
+		for _, cause := range status.Details.Causes {
+			modelErr.Causes = append(modelErr.Causes, models.ErrorCause{
+				Type:    string(cause.Type),
+				Message: cause.Message,
+				Field:   cause.Field,
+			})
+		}
+	}
+	if retryAfter, suggested := errors.SuggestsClientDelay(err); suggested {
+		modelErr.RetryAfterSeconds = int32(retryAfter)
+	}
+	return modelErr
+}
@@ -0,0 +1,102 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var testGroupResource = schema.GroupResource{Group: "apps", Resource: "deployments"}
+
+func TestFromKubernetesErrorCodes(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int32
+		reason       metav1.StatusReason
+	}{
+		{"Already exists", apierrors.NewAlreadyExists(testGroupResource, "api"), 409, metav1.StatusReasonAlreadyExists},
+		{"Conflict", apierrors.NewConflict(testGroupResource, "api", errors.New("the object has been modified")), 409, metav1.StatusReasonConflict},
+		{"Gone", apierrors.NewGone("too old resource version"), 410, metav1.StatusReasonGone},
+		{"Method not allowed", apierrors.NewMethodNotSupported(testGroupResource, "patch"), 405, metav1.StatusReasonMethodNotAllowed},
+		{"Request entity too large", apierrors.NewRequestEntityTooLargeError("limit is 3145728"), 413, metav1.StatusReasonRequestEntityTooLarge},
+		{"Server timeout", apierrors.NewServerTimeout(testGroupResource, "create", 2), 504, metav1.StatusReasonServerTimeout},
+		{"Timeout", apierrors.NewTimeoutError("request did not complete", 0), 504, metav1.StatusReasonTimeout},
+		{"Service unavailable", apierrors.NewServiceUnavailable("etcd is down"), 503, metav1.StatusReasonServiceUnavailable},
+		{"Internal error", apierrors.NewInternalError(errors.New("etcd is down")), 500, metav1.StatusReasonInternalError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := FromKubernetesError(tt.err)
+
+			assert.Equal(t, tt.expectedCode, result.Code)
+			assert.Equal(t, string(tt.reason), result.Reason)
+		})
+	}
+}
+
+func TestFromKubernetesErrorCauses(t *testing.T) {
+	err := apierrors.NewInvalid(schema.GroupKind{Group: "apps", Kind: "Deployment"}, "api", field.ErrorList{
+		field.Invalid(field.NewPath("spec", "replicas"), -1, "must be greater than or equal to 0"),
+	})
+
+	result := FromKubernetesError(err)
+
+	assert.Equal(t, int32(422), result.Code)
+	assert.Equal(t, "Invalid", result.Reason)
+	assert.Equal(t, []models.ErrorCause{{
+		Type:    "FieldValueInvalid",
+		Message: "Invalid value: -1: must be greater than or equal to 0",
+		Field:   "spec.replicas",
+	}}, result.Causes)
+}
+
+func TestFromKubernetesErrorRetryAfter(t *testing.T) {
+	err := apierrors.NewTooManyRequests("the server has received too many requests", 5)
+
+	result := FromKubernetesError(err)
+
+	assert.Equal(t, int32(429), result.Code)
+	assert.Equal(t, int32(5), result.RetryAfterSeconds)
+}
+
+func TestFromKubernetesErrorWebhookRejection(t *testing.T) {
+	err := &apierrors.StatusError{ErrStatus: metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    400,
+		Message: `admission webhook "policy.example.com" denied the request: image tag latest is not allowed`,
+	}}
+
+	result := FromKubernetesError(err)
+
+	assert.Equal(t, int32(400), result.Code)
+	assert.Equal(t, "", result.Reason)
+	assert.Contains(t, result.Message, "denied the request")
+}
+
+func TestFromKubernetesErrorWrapped(t *testing.T) {
+	err := fmt.Errorf("query: failed to query with labels: %w", apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "", errors.New("no access")))
+
+	result := FromKubernetesError(err)
+
+	assert.Equal(t, int32(403), result.Code)
+	assert.Equal(t, "Forbidden", result.Reason)
+}
+
+func TestFromKubernetesErrorOtherErrors(t *testing.T) {
+	assert.Nil(t, FromKubernetesError(errors.New("connection refused")))
+
+	result := FromKubernetesError(fmt.Errorf("watch: %w", context.DeadlineExceeded))
+	assert.Equal(t, int32(504), result.Code)
+
+	assert.Equal(t, int32(500), handleKubernetesError(errors.New("connection refused")).Code)
+}
@@ -0,0 +1,251 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ResourceFilter decides whether resources of the type, given by its canonical name, in the namespace may be shown.
+type ResourceFilter func(resourceType string, namespace string) (bool, *models.ModelError)
+
+// eventSelector narrows down the listed events, empty fields match all events.
+type eventSelector struct {
+	UID    types.UID
+	Type   string
+	Reason string
+}
+
+// GetResourceEvents returns the events about a resource, oldest first. Events are matched by the UID of the
+// resource, so events of a deleted resource with the same name are left out.
+func GetResourceEvents(resourceType string, namespace string, resourceName string, getResourceInterface ResourceInterfaceGetter, getClientset ClientsetGetter) ([]models.KubernetesEvent, *models.ModelError) {
+	resourceInterface, err := getResourceInterface(resourceType, namespace, DefaultNamespace)
+	if err != nil {
+		return nil, err
+	}
+	resource, getErr := resourceInterface.Get(context.TODO(), resourceName, metav1.GetOptions{})
+	if getErr != nil {
+		return nil, handleKubernetesError(getErr)
+	}
+	clientset, err := getClientset()
+	if err != nil {
+		return nil, err
+	}
+
+	uids := []types.UID{resource.GetUID()}
+	// The kubelet reports events about its Node with the name of the Node in place of the UID
+	if resource.GetKind() == nodeString {
+		uids = append(uids, types.UID(resource.GetName()))
+	}
+	// Events about cluster-scoped resources may be in any namespace, the empty namespace lists all of them
+	events := make([]models.KubernetesEvent, 0)
+	for _, uid := range uids {
+		uidEvents, err := listEvents(clientset, resource.GetNamespace(), eventSelector{UID: uid})
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, uidEvents...)
+	}
+	sortEvents(events)
+	return events, nil
+}
+
+// ListNamespaceEvents returns the events of a namespace, oldest first, optionally only those of a type and reason.
+func ListNamespaceEvents(namespace string, eventType string, reason string, getClientset ClientsetGetter) ([]models.KubernetesEvent, *models.ModelError) {
+	clientset, err := getClientset()
+	if err != nil {
+		return nil, err
+	}
+	events, err := listEvents(clientset, namespace, eventSelector{Type: eventType, Reason: reason})
+	if err != nil {
+		return nil, err
+	}
+	sortEvents(events)
+	return events, nil
+}
+
+// FilterEventsByInvolvedObject keeps the events about resources which pass the filter. Events about resources
+// of types which are not served, e.g. excluded by the allowlist, are left out.
+func FilterEventsByInvolvedObject(events []models.KubernetesEvent, filter ResourceFilter) ([]models.KubernetesEvent, *models.ModelError) {
+	resourceTypes := make(map[string]string)
+	allowed := make(map[string]bool)
+	result := make([]models.KubernetesEvent, 0, len(events))
+	for _, event := range events {
+		typeName := event.InvolvedObject.Kind
+		if event.InvolvedObject.ApiVersion != "" {
+			typeName = fmt.Sprintf("%s/%s", event.InvolvedObject.ApiVersion, event.InvolvedObject.Kind)
+		}
+		resourceType, resolved := resourceTypes[typeName]
+		if !resolved {
+			resourceType, _ = NormalizeResourceType(typeName)
+			resourceTypes[typeName] = resourceType
+		}
+		if resourceType == "" {
+			continue
+		}
+
+		// Events about cluster-scoped resources are checked in the namespace of the event
+		namespace := event.InvolvedObject.Namespace
+		if namespace == "" {
+			namespace = event.Namespace
+		}
+		key := resourceType + "/" + namespace
+		isAllowed, checked := allowed[key]
+		if !checked {
+			var err *models.ModelError
+			if isAllowed, err = filter(resourceType, namespace); err != nil {
+				return nil, err
+			}
+			allowed[key] = isAllowed
+		}
+		if isAllowed {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+// listEvents lists the events of both the core and the events.k8s.io API. Both APIs serve the same events,
+// so the events are merged by UID, preferring events.k8s.io, which reports series of repeated events.
+func listEvents(clientset kubernetes.Interface, namespace string, selector eventSelector) ([]models.KubernetesEvent, *models.ModelError) {
+	coreFields, eventsFields := fields.Set{}, fields.Set{}
+	if selector.UID != "" {
+		coreFields["involvedObject.uid"] = string(selector.UID)
+		eventsFields["regarding.uid"] = string(selector.UID)
+	}
+	if selector.Type != "" {
+		coreFields["type"] = selector.Type
+		eventsFields["type"] = selector.Type
+	}
+	if selector.Reason != "" {
+		coreFields["reason"] = selector.Reason
+		eventsFields["reason"] = selector.Reason
+	}
+
+	coreEvents, listErr := clientset.CoreV1().Events(namespace).List(context.TODO(), metav1.ListOptions{FieldSelector: coreFields.AsSelector().String()})
+	if listErr != nil {
+		return nil, handleKubernetesError(listErr)
+	}
+	eventsByUID := make(map[types.UID]models.KubernetesEvent)
+	order := make([]types.UID, 0, len(coreEvents.Items))
+	for _, event := range coreEvents.Items {
+		eventsByUID[event.UID] = fromCoreEvent(event)
+		order = append(order, event.UID)
+	}
+
+	newEvents, listErr := clientset.EventsV1().Events(namespace).List(context.TODO(), metav1.ListOptions{FieldSelector: eventsFields.AsSelector().String()})
+	if listErr != nil && !errors.IsNotFound(listErr) {
+		return nil, handleKubernetesError(listErr)
+	}
+	if listErr == nil {
+		for _, event := range newEvents.Items {
+			if _, found := eventsByUID[event.UID]; !found {
+				order = append(order, event.UID)
+			}
+			eventsByUID[event.UID] = fromEventsV1Event(event)
+		}
+	}
+
+	events := make([]models.KubernetesEvent, 0, len(order))
+	for _, uid := range order {
+		events = append(events, eventsByUID[uid])
+	}
+	return events, nil
+}
+
+// sortEvents orders events by the time they last occurred, like kubectl describe does.
+func sortEvents(events []models.KubernetesEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].LastTimestamp.Before(events[j].LastTimestamp)
+	})
+}
+
+func fromCoreEvent(event corev1.Event) models.KubernetesEvent {
+	source := event.Source.Component
+	if source == "" {
+		source = event.ReportingController
+	}
+	var series *eventsv1.EventSeries
+	if event.Series != nil {
+		series = &eventsv1.EventSeries{Count: event.Series.Count, LastObservedTime: event.Series.LastObservedTime}
+	}
+	return models.KubernetesEvent{
+		Name:           event.Name,
+		Namespace:      event.Namespace,
+		Type:           event.Type,
+		Reason:         event.Reason,
+		Message:        event.Message,
+		InvolvedObject: toEventObject(event.InvolvedObject),
+		Source:         source,
+		Count:          getEventCount(event.Count, series),
+		FirstTimestamp: firstNonZeroTime(event.FirstTimestamp.Time, event.EventTime.Time),
+		LastTimestamp:  getEventLastTimestamp(event.LastTimestamp.Time, event.EventTime.Time, series),
+	}
+}
+
+func fromEventsV1Event(event eventsv1.Event) models.KubernetesEvent {
+	source := event.ReportingController
+	if source == "" {
+		source = event.DeprecatedSource.Component
+	}
+	return models.KubernetesEvent{
+		Name:           event.Name,
+		Namespace:      event.Namespace,
+		Type:           event.Type,
+		Reason:         event.Reason,
+		Message:        event.Note,
+		InvolvedObject: toEventObject(event.Regarding),
+		Source:         source,
+		Count:          getEventCount(event.DeprecatedCount, event.Series),
+		FirstTimestamp: firstNonZeroTime(event.DeprecatedFirstTimestamp.Time, event.EventTime.Time),
+		LastTimestamp:  getEventLastTimestamp(event.DeprecatedLastTimestamp.Time, event.EventTime.Time, event.Series),
+	}
+}
+
+func toEventObject(reference corev1.ObjectReference) models.EventObject {
+	return models.EventObject{
+		ApiVersion: reference.APIVersion,
+		Kind:       reference.Kind,
+		Name:       reference.Name,
+		Namespace:  reference.Namespace,
+		Uid:        string(reference.UID),
+		FieldPath:  reference.FieldPath,
+	}
+}
+
+// getEventCount prefers the count of a series, which newer reporters use in place of the deprecated count.
+func getEventCount(count int32, series *eventsv1.EventSeries) int32 {
+	if series != nil && series.Count > 0 {
+		return series.Count
+	}
+	if count > 0 {
+		return count
+	}
+	return 1
+}
+
+func getEventLastTimestamp(lastTimestamp time.Time, eventTime time.Time, series *eventsv1.EventSeries) time.Time {
+	if series != nil && !series.LastObservedTime.IsZero() {
+		return series.LastObservedTime.Time
+	}
+	return firstNonZeroTime(lastTimestamp, eventTime)
+}
+
+func firstNonZeroTime(times ...time.Time) time.Time {
+	for _, t := range times {
+		if !t.IsZero() {
+			return t
+		}
+	}
+	return time.Time{}
+}
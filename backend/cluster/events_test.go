@@ -0,0 +1,139 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var testEventTime = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestCoreEvent(name string, reason string, lastTimestamp time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "payments", UID: types.UID(name + "-uid")},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:      "Pod",
+			Name:      "api-0",
+			Namespace: "payments",
+			UID:       "pod-uid",
+		},
+		Type:           corev1.EventTypeWarning,
+		Reason:         reason,
+		Message:        "0/3 nodes are available",
+		Source:         corev1.EventSource{Component: "default-scheduler"},
+		Count:          2,
+		FirstTimestamp: metav1.NewTime(testEventTime),
+		LastTimestamp:  metav1.NewTime(lastTimestamp),
+	}
+}
+
+// recordFieldSelectors records the field selectors of event lists, which the fake clientset does not apply.
+func recordFieldSelectors(clientset *fake.Clientset) *[]string {
+	selectors := make([]string, 0)
+	clientset.PrependReactor("list", "events", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		selectors = append(selectors, action.(k8stesting.ListAction).GetListRestrictions().Fields.String())
+		return false, nil, nil
+	})
+	return &selectors
+}
+
+func TestListNamespaceEventsMergesBothAPIs(t *testing.T) {
+	scheduled := newTestCoreEvent("scheduled", "Scheduled", testEventTime.Add(2*time.Minute))
+	failed := newTestCoreEvent("failed", "FailedScheduling", testEventTime.Add(time.Minute))
+	// The same event served by events.k8s.io, with a series of repeated occurrences
+	failedSeries := &eventsv1.Event{
+		ObjectMeta:          failed.ObjectMeta,
+		EventTime:           metav1.NewMicroTime(testEventTime),
+		Series:              &eventsv1.EventSeries{Count: 5, LastObservedTime: metav1.NewMicroTime(testEventTime.Add(3 * time.Minute))},
+		ReportingController: "default-scheduler",
+		Reason:              failed.Reason,
+		Regarding:           failed.InvolvedObject,
+		Note:                failed.Message,
+		Type:                failed.Type,
+	}
+	clientset := fake.NewSimpleClientset(scheduled, failed, failedSeries)
+	selectors := recordFieldSelectors(clientset)
+
+	events, err := ListNamespaceEvents("payments", corev1.EventTypeWarning, "", getTestClientset(clientset))
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"type=Warning", "type=Warning"}, *selectors)
+	assert.Len(t, events, 2)
+	assert.Equal(t, "scheduled", events[0].Name)
+	assert.Equal(t, int32(2), events[0].Count)
+	assert.Equal(t, models.KubernetesEvent{
+		Name:      "failed",
+		Namespace: "payments",
+		Type:      corev1.EventTypeWarning,
+		Reason:    "FailedScheduling",
+		Message:   "0/3 nodes are available",
+		InvolvedObject: models.EventObject{
+			Kind:      "Pod",
+			Name:      "api-0",
+			Namespace: "payments",
+			Uid:       "pod-uid",
+		},
+		Source:         "default-scheduler",
+		Count:          5,
+		FirstTimestamp: testEventTime,
+		LastTimestamp:  testEventTime.Add(3 * time.Minute),
+	}, events[1])
+}
+
+func TestGetResourceEventsSelectsByUID(t *testing.T) {
+	clientset := fake.NewSimpleClientset(newTestCoreEvent("scheduled", "Scheduled", testEventTime))
+	selectors := recordFieldSelectors(clientset)
+	pod := &unstructured.Unstructured{}
+	pod.SetKind("Pod")
+	pod.SetName("api-0")
+	pod.SetNamespace("payments")
+	pod.SetUID("pod-uid")
+
+	events, err := GetResourceEvents("Pod", "payments", "api-0", getTestScaleResourceInterface(&MockResourceInterface{ReturnedValue: pod}), getTestClientset(clientset))
+
+	assert.Nil(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, []string{"involvedObject.uid=pod-uid", "regarding.uid=pod-uid"}, *selectors)
+}
+
+func TestGetResourceEventsOfNode(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	selectors := recordFieldSelectors(clientset)
+	node := &unstructured.Unstructured{}
+	node.SetKind("Node")
+	node.SetName("node-1")
+	node.SetUID("node-uid")
+
+	_, err := GetResourceEvents("Node", "", "node-1", getTestScaleResourceInterface(&MockResourceInterface{ReturnedValue: node}), getTestClientset(clientset))
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"involvedObject.uid=node-uid", "regarding.uid=node-uid",
+		"involvedObject.uid=node-1", "regarding.uid=node-1",
+	}, *selectors)
+}
+
+func TestFromCoreEventWithoutCount(t *testing.T) {
+	event := corev1.Event{
+		ObjectMeta:          metav1.ObjectMeta{Name: "pulled", Namespace: "payments"},
+		EventTime:           metav1.NewMicroTime(testEventTime),
+		ReportingController: "kubelet",
+	}
+
+	result := fromCoreEvent(event)
+
+	assert.Equal(t, int32(1), result.Count)
+	assert.Equal(t, "kubelet", result.Source)
+	assert.Equal(t, testEventTime, result.FirstTimestamp)
+	assert.Equal(t, testEventTime, result.LastTimestamp)
+}
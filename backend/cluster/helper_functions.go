@@ -11,9 +11,9 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/watch"
-	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
 func getAllowedResourceTypes() map[string]struct{} {
@@ -41,44 +41,37 @@ func getAllowedResourceTypes() map[string]struct{} {
 	}
 }
 
-func isResourceTypeAllowed(resourceType string) bool {
-	allowedResourceTypes := getAllowedResourceTypes()
-	_, exists := allowedResourceTypes[resourceType]
-	return exists
-}
-
 func GetResourceGroupVersion(resourceType string) (output schema.GroupVersionResource, namespaced bool, error *models.ModelError) {
-	if !isResourceTypeAllowed(resourceType) {
-		return schema.GroupVersionResource{}, false, &models.ModelError{Code: 400, Message: fmt.Sprintf("Invalid Resource Type")}
-	}
-
-	config, err := GetConfig()
+	resolved, err := resolveResourceType(resourceType)
 	if err != nil {
-		return schema.GroupVersionResource{}, false, &models.ModelError{Code: 500, Message: fmt.Sprintf("Failed to get config: %s", err)}
+		return schema.GroupVersionResource{}, false, err
 	}
 
-	discoveryClient, _ := discovery.NewDiscoveryClientForConfig(config)
-
-	apiResourceLists, _ := discoveryClient.ServerPreferredResources()
+	return resolved.GroupVersionResource, resolved.Namespaced, nil
+}
 
-	for _, apiResourceList := range apiResourceLists {
-		for _, apiResource := range apiResourceList.APIResources {
-			if apiResource.Kind == resourceType {
-				groupVersion, err := schema.ParseGroupVersion(apiResourceList.GroupVersion)
-				if err != nil {
-					return schema.GroupVersionResource{}, false, &models.ModelError{Code: 500, Message: fmt.Sprintf("%s", err.Error())}
-				}
+// discoverAPIResources returns the resources served in the requested group version,
+// or the preferred version of every group when no version is given.
+func discoverAPIResources(name resourceTypeName) ([]*metav1.APIResourceList, *models.ModelError) {
+	cache, err := getDiscoveryCache()
+	if err != nil {
+		return nil, &models.ModelError{Code: 500, Message: fmt.Sprintf("Failed to get discovery client: %s", err)}
+	}
 
-				return schema.GroupVersionResource{
-					Group:    groupVersion.Group,
-					Version:  groupVersion.Version,
-					Resource: apiResource.Name,
-				}, apiResource.Namespaced, nil
-			}
+	var apiResourceLists []*metav1.APIResourceList
+	if name.Version != "" {
+		groupVersion := schema.GroupVersion{Group: name.Group, Version: name.Version}
+		apiResourceLists, err = cache.serverResourcesForGroupVersion(groupVersion.String())
+	} else {
+		apiResourceLists, err = cache.serverPreferredResources()
+	}
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
 		}
+		return nil, handleKubernetesError(err)
 	}
-
-	return schema.GroupVersionResource{}, false, &models.ModelError{Code: 400, Message: fmt.Sprintf("Invalid Resource Type")}
+	return apiResourceLists, nil
 }
 
 func GetResourceInterface(resourceType string, namespace string, emptyNamespace string) (dynamic.ResourceInterface, *models.ModelError) {
@@ -92,25 +85,36 @@ func GetResourceInterface(resourceType string, namespace string, emptyNamespace
 		return nil, &models.ModelError{Code: 500, Message: fmt.Sprintf("Failed to get client: %s", err)}
 	}
 
+	return getNamespaceableResourceInterface(dynamicClient, gvr, namespaced, namespace, emptyNamespace), nil
+}
+
+// GetClientset returns the typed client used for subresources, such as logs, which the dynamic client does not cover.
+func GetClientset() (kubernetes.Interface, *models.ModelError) {
+	clientset, err := GetTypedClientSet()
+	if err != nil {
+		return nil, &models.ModelError{Code: 500, Message: fmt.Sprintf("Failed to get client: %s", err)}
+	}
+	return clientset, nil
+}
+
+// GetRESTConfig returns the shared config for clients which stream subresources, such as exec.
+func GetRESTConfig() (*rest.Config, *models.ModelError) {
+	config, err := GetConfig()
+	if err != nil {
+		return nil, &models.ModelError{Code: 500, Message: fmt.Sprintf("Failed to get config: %s", err)}
+	}
+	return config, nil
+}
+
+func getNamespaceableResourceInterface(dynamicClient dynamic.Interface, gvr schema.GroupVersionResource, namespaced bool, namespace string, emptyNamespace string) dynamic.ResourceInterface {
 	if namespaced {
 		if namespace == "" {
 			namespace = emptyNamespace
 		}
-		return dynamicClient.Resource(gvr).Namespace(namespace), nil
+		return dynamicClient.Resource(gvr).Namespace(namespace)
 	} else {
-		return dynamicClient.Resource(gvr), nil
-	}
-}
-
-func handleKubernetesError(err error) *models.ModelError {
-	if errors.IsNotFound(err) {
-		return &models.ModelError{Code: 404, Message: fmt.Sprintf("Resource not found: %s", err)}
-	} else if errors.IsForbidden(err) {
-		return &models.ModelError{Code: 403, Message: fmt.Sprintf("Forbidden: %s", err)}
-	} else if errors.IsUnauthorized(err) {
-		return &models.ModelError{Code: 401, Message: fmt.Sprintf("Unauthorized: %s", err)}
+		return dynamicClient.Resource(gvr)
 	}
-	return &models.ModelError{Code: 500, Message: fmt.Sprintf("Internal server error: %s", err)}
 }
 
 func WatchForChanges(namespace, resourceName string, mutex *sync.Mutex, updateFunc func(<-chan watch.Event, *sync.Mutex, string, string)) {
@@ -41,7 +41,7 @@ func TestGetAllowedResourceTypes(t *testing.T) {
 	}
 }
 
-func TestIsResourceTypeAllowed(t *testing.T) {
+func TestIsResourceAllowed(t *testing.T) {
 	allowedTypes := []string{
 		"Pod",
 		"Service",
@@ -66,7 +66,7 @@ func TestIsResourceTypeAllowed(t *testing.T) {
 	}
 
 	for _, resourceType := range allowedTypes {
-		if !isResourceTypeAllowed(resourceType) {
+		if !isResourceAllowed("", resourceType) {
 			t.Errorf("Expected resource type '%s' to be allowed", resourceType)
 		}
 	}
@@ -78,7 +78,7 @@ func TestIsResourceTypeAllowed(t *testing.T) {
 	}
 
 	for _, resourceType := range disallowedTypes {
-		if isResourceTypeAllowed(resourceType) {
+		if isResourceAllowed("", resourceType) {
 			t.Errorf("Expected resource type '%s' to be disallowed", resourceType)
 		}
 	}
@@ -109,6 +109,22 @@ func TestHandleKubernetesError(t *testing.T) {
 		t.Errorf("Expected %v, got %v", expectedUnauthorizedError, result)
 	}
 
+	expiredErr := apierrors.NewResourceExpired("continue token expired")
+	expectedExpiredError := &models.ModelError{Code: 410, Message: fmt.Sprintf("Continue token expired: %s", expiredErr.Error())}
+
+	result = handleKubernetesError(expiredErr)
+	if result.Code != expectedExpiredError.Code || result.Message != expectedExpiredError.Message {
+		t.Errorf("Expected %v, got %v", expectedExpiredError, result)
+	}
+
+	badRequestErr := apierrors.NewBadRequest("invalid continue token")
+	expectedBadRequestError := &models.ModelError{Code: 400, Message: fmt.Sprintf("Bad request: %s", badRequestErr.Error())}
+
+	result = handleKubernetesError(badRequestErr)
+	if result.Code != expectedBadRequestError.Code || result.Message != expectedBadRequestError.Message {
+		t.Errorf("Expected %v, got %v", expectedBadRequestError, result)
+	}
+
 	otherErr := errors.New("some other error")
 	expectedOtherError := &models.ModelError{Code: 500, Message: fmt.Sprintf("Internal server error: %s", otherErr.Error())}
 
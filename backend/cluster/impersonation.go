@@ -0,0 +1,80 @@
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// GetImpersonatedConfig returns a copy of the shared config which sends Impersonate-User and Impersonate-Group
+// headers, so the API server authorizes the request with native RBAC of the given user.
+func GetImpersonatedConfig(username string, groups []string) (*rest.Config, error) {
+	config, err := GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return impersonateConfig(config, username, groups), nil
+}
+
+func impersonateConfig(config *rest.Config, username string, groups []string) *rest.Config {
+	impersonatedConfig := rest.CopyConfig(config)
+	impersonatedConfig.Impersonate = rest.ImpersonationConfig{
+		UserName: username,
+		Groups:   groups,
+	}
+	return impersonatedConfig
+}
+
+// GetImpersonatedResourceInterfaceGetter works like GetResourceInterface, but every call made through
+// the returned resource interface is executed on behalf of the given user.
+func GetImpersonatedResourceInterfaceGetter(username string, groups []string) ResourceInterfaceGetter {
+	return func(resourceType string, namespace string, emptyNamespace string) (dynamic.ResourceInterface, *models.ModelError) {
+		gvr, namespaced, httpErr := GetResourceGroupVersion(resourceType)
+		if httpErr != nil {
+			return nil, httpErr
+		}
+
+		config, err := GetImpersonatedConfig(username, groups)
+		if err != nil {
+			return nil, &models.ModelError{Code: 500, Message: fmt.Sprintf("Failed to get config: %s", err)}
+		}
+
+		dynamicClient, err := dynamic.NewForConfig(config)
+		if err != nil {
+			return nil, &models.ModelError{Code: 500, Message: fmt.Sprintf("Failed to get client: %s", err)}
+		}
+
+		return getNamespaceableResourceInterface(dynamicClient, gvr, namespaced, namespace, emptyNamespace), nil
+	}
+}
+
+// GetImpersonatedClientsetGetter is the ClientsetGetter counterpart of GetImpersonatedResourceInterfaceGetter.
+func GetImpersonatedClientsetGetter(username string, groups []string) ClientsetGetter {
+	return func() (kubernetes.Interface, *models.ModelError) {
+		config, err := GetImpersonatedConfig(username, groups)
+		if err != nil {
+			return nil, &models.ModelError{Code: 500, Message: fmt.Sprintf("Failed to get config: %s", err)}
+		}
+
+		clientset, err := kubernetes.NewForConfig(config)
+		if err != nil {
+			return nil, &models.ModelError{Code: 500, Message: fmt.Sprintf("Failed to get client: %s", err)}
+		}
+		return clientset, nil
+	}
+}
+
+// GetImpersonatedConfigGetter is the ConfigGetter counterpart of GetImpersonatedResourceInterfaceGetter.
+func GetImpersonatedConfigGetter(username string, groups []string) ConfigGetter {
+	return func() (*rest.Config, *models.ModelError) {
+		config, err := GetImpersonatedConfig(username, groups)
+		if err != nil {
+			return nil, &models.ModelError{Code: 500, Message: fmt.Sprintf("Failed to get config: %s", err)}
+		}
+		return config, nil
+	}
+}
@@ -0,0 +1,32 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/rest"
+)
+
+func TestImpersonateConfig(t *testing.T) {
+	config := &rest.Config{
+		Host:        "https://cluster.local",
+		BearerToken: "service-account-token",
+	}
+
+	impersonated := impersonateConfig(config, "jane", []string{"developers", "on-call"})
+
+	t.Run("Sets impersonation headers", func(t *testing.T) {
+		assert.Equal(t, "jane", impersonated.Impersonate.UserName)
+		assert.Equal(t, []string{"developers", "on-call"}, impersonated.Impersonate.Groups)
+	})
+
+	t.Run("Keeps credentials of the service account", func(t *testing.T) {
+		assert.Equal(t, config.Host, impersonated.Host)
+		assert.Equal(t, config.BearerToken, impersonated.BearerToken)
+	})
+
+	t.Run("Does not modify shared config", func(t *testing.T) {
+		assert.Empty(t, config.Impersonate.UserName)
+		assert.Empty(t, config.Impersonate.Groups)
+	})
+}
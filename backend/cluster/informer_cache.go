@@ -0,0 +1,315 @@
+package cluster
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/common"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// cacheContinuePrefix marks continue tokens issued by the informer cache, API server tokens never start with it
+	cacheContinuePrefix = "cache:"
+
+	informerCacheResultHit  = "hit"
+	informerCacheResultMiss = "miss"
+)
+
+// informerCache keeps one informer per resource type. Informers are started by the first request
+// for their resource type and stopped after idleTimeout without requests.
+type informerCache struct {
+	client      dynamic.Interface
+	idleTimeout time.Duration
+	informers   map[schema.GroupVersionResource]*cachedInformer
+	mutex       sync.Mutex
+}
+
+type cachedInformer struct {
+	informer informers.GenericInformer
+	stop     chan struct{}
+	// lastUsed is guarded by the mutex of informerCache
+	lastUsed time.Time
+}
+
+var (
+	informerCacheInstance *informerCache
+	informerCacheOnce     sync.Once
+	informerCacheErr      error
+)
+
+func getInformerCache() (*informerCache, error) {
+	informerCacheOnce.Do(func() {
+		client, err := GetClientSet()
+		if err != nil {
+			informerCacheErr = fmt.Errorf("failed to get client: %w", err)
+			return
+		}
+		informerCacheInstance = newInformerCache(client, time.Duration(common.InformerCacheIdleTimeout)*time.Second)
+	})
+
+	if informerCacheInstance == nil {
+		return nil, informerCacheErr
+	}
+	return informerCacheInstance, nil
+}
+
+func newInformerCache(client dynamic.Interface, idleTimeout time.Duration) *informerCache {
+	return &informerCache{
+		client:      client,
+		idleTimeout: idleTimeout,
+		informers:   make(map[schema.GroupVersionResource]*cachedInformer),
+	}
+}
+
+// informerFor returns the informer of the resource type, starting it if it is not running yet.
+func (c *informerCache) informerFor(gvr schema.GroupVersionResource) *cachedInformer {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	cached, exists := c.informers[gvr]
+	if !exists {
+		informer := dynamicinformer.NewFilteredDynamicInformer(c.client, gvr, metav1.NamespaceAll, 0,
+			cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, nil)
+		cached = &cachedInformer{informer: informer, stop: make(chan struct{})}
+		c.informers[gvr] = cached
+		go informer.Informer().Run(cached.stop)
+		activeInformers.Inc()
+		log.Printf("Informer cache: started informer for %s", gvr)
+	}
+	cached.lastUsed = time.Now()
+	return cached
+}
+
+func (c *informerCache) stopIdleInformers(now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for gvr, cached := range c.informers {
+		if now.Sub(cached.lastUsed) < c.idleTimeout {
+			continue
+		}
+		close(cached.stop)
+		delete(c.informers, gvr)
+		activeInformers.Dec()
+		log.Printf("Informer cache: stopped idle informer for %s", gvr)
+	}
+}
+
+func (c *informerCache) resourceInterface(gvr schema.GroupVersionResource, namespaced bool, namespace string, emptyNamespace string) dynamic.ResourceInterface {
+	if !namespaced {
+		namespace = ""
+	} else if namespace == "" {
+		namespace = emptyNamespace
+	}
+	return &cachedResourceInterface{
+		ResourceInterface: getNamespaceableResourceInterface(c.client, gvr, namespaced, namespace, emptyNamespace),
+		cache:             c,
+		gvr:               gvr,
+		namespace:         namespace,
+	}
+}
+
+// isCacheable reports whether objects of the resource type may be kept in backend memory.
+// Secrets never are, and neither are types excluded by RESOURCE_TYPES_EXCLUDE.
+func isCacheable(group string, kind string) bool {
+	if group == "" && kind == "Secret" {
+		return false
+	}
+	for _, pattern := range splitResourcePatterns(common.ResourceTypesExclude) {
+		if matchesResourcePattern(pattern, group, kind) {
+			return false
+		}
+	}
+	return true
+}
+
+// StopIdleInformersPeriodically stops informers which have not been used for the configured idle timeout.
+func StopIdleInformersPeriodically() {
+	informerCache, err := getInformerCache()
+	if err != nil {
+		log.Printf("Informer cache: unable to start: %v", err)
+		return
+	}
+
+	ticker := time.NewTicker(informerCache.idleTimeout / 2)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		informerCache.stopIdleInformers(now)
+	}
+}
+
+// GetCachedResourceInterface works like GetResourceInterface, but List and Get calls are served from
+// the informer cache once its informer for the resource type has synced. The informer is started by the
+// first List or Get, until it has synced and for all other calls requests go to the API server.
+// Types which must not be kept in memory, such as Secrets, are never cached.
+func GetCachedResourceInterface(resourceType string, namespace string, emptyNamespace string) (dynamic.ResourceInterface, *models.ModelError) {
+	resolved, httpErr := resolveResourceType(resourceType)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+	gvr, namespaced := resolved.GroupVersionResource, resolved.Namespaced
+
+	informerCache, err := getInformerCache()
+	if err != nil {
+		return nil, &models.ModelError{Code: 500, Message: fmt.Sprintf("Failed to get client: %s", err)}
+	}
+
+	if !isCacheable(gvr.Group, resolved.Kind) {
+		return getNamespaceableResourceInterface(informerCache.client, gvr, namespaced, namespace, emptyNamespace), nil
+	}
+	return informerCache.resourceInterface(gvr, namespaced, namespace, emptyNamespace), nil
+}
+
+// cachedResourceInterface overrides List and Get of the API server resource interface it embeds.
+type cachedResourceInterface struct {
+	dynamic.ResourceInterface
+	cache *informerCache
+	gvr   schema.GroupVersionResource
+	// namespace is empty for cluster scoped resources and for lists across all namespaces
+	namespace string
+}
+
+func (r *cachedResourceInterface) List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	fromCache := strings.HasPrefix(opts.Continue, cacheContinuePrefix)
+	// Field selectors and exact resource versions cannot be answered from memory, so no informer is started for them
+	if !fromCache && (opts.Continue != "" || opts.FieldSelector != "" || !isAnyResourceVersion(opts.ResourceVersion)) {
+		informerCacheRequests.WithLabelValues(informerCacheResultMiss).Inc()
+		return r.ResourceInterface.List(ctx, opts)
+	}
+	informer := r.cache.informerFor(r.gvr)
+	if !informer.informer.Informer().HasSynced() {
+		if fromCache {
+			return nil, apierrors.NewResourceExpired("the informer cache issuing the continue token is no longer available")
+		}
+		informerCacheRequests.WithLabelValues(informerCacheResultMiss).Inc()
+		return r.ResourceInterface.List(ctx, opts)
+	}
+
+	selector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid label selector: %s", err))
+	}
+	var objects []runtime.Object
+	lister := informer.informer.Lister()
+	if r.namespace != "" {
+		objects, err = lister.ByNamespace(r.namespace).List(selector)
+	} else {
+		objects, err = lister.List(selector)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	informerCacheRequests.WithLabelValues(informerCacheResultHit).Inc()
+	return paginateCachedObjects(objects, opts, informer.informer.Informer().LastSyncResourceVersion())
+}
+
+func (r *cachedResourceInterface) Get(ctx context.Context, name string, opts metav1.GetOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	// Subresources and exact resource versions cannot be answered from memory, so no informer is started for them
+	if len(subresources) > 0 || !isAnyResourceVersion(opts.ResourceVersion) {
+		informerCacheRequests.WithLabelValues(informerCacheResultMiss).Inc()
+		return r.ResourceInterface.Get(ctx, name, opts, subresources...)
+	}
+	informer := r.cache.informerFor(r.gvr)
+	if !informer.informer.Informer().HasSynced() {
+		informerCacheRequests.WithLabelValues(informerCacheResultMiss).Inc()
+		return r.ResourceInterface.Get(ctx, name, opts, subresources...)
+	}
+
+	var object runtime.Object
+	var err error
+	lister := informer.informer.Lister()
+	if r.namespace != "" {
+		object, err = lister.ByNamespace(r.namespace).Get(name)
+	} else {
+		object, err = lister.Get(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	resource, ok := object.(*unstructured.Unstructured)
+	if !ok {
+		return r.ResourceInterface.Get(ctx, name, opts, subresources...)
+	}
+
+	informerCacheRequests.WithLabelValues(informerCacheResultHit).Inc()
+	return resource.DeepCopy(), nil
+}
+
+// isAnyResourceVersion reports whether the resource version allows serving data of any age.
+func isAnyResourceVersion(resourceVersion string) bool {
+	return resourceVersion == "" || resourceVersion == "0"
+}
+
+// paginateCachedObjects orders the objects by namespace and name, so that the continue token can hold the key
+// of the last returned object. Objects added or removed between pages are then neither repeated nor skipped.
+func paginateCachedObjects(objects []runtime.Object, opts metav1.ListOptions, resourceVersion string) (*unstructured.UnstructuredList, error) {
+	resources := make([]*unstructured.Unstructured, 0, len(objects))
+	for _, object := range objects {
+		if resource, ok := object.(*unstructured.Unstructured); ok {
+			resources = append(resources, resource)
+		}
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return cacheKey(resources[i]) < cacheKey(resources[j])
+	})
+
+	if opts.Continue != "" {
+		startAfter, err := decodeCacheContinueToken(opts.Continue)
+		if err != nil {
+			return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid continue token: %s", err))
+		}
+		start := sort.Search(len(resources), func(i int) bool {
+			return cacheKey(resources[i]) > startAfter
+		})
+		resources = resources[start:]
+	}
+
+	list := &unstructured.UnstructuredList{Object: map[string]interface{}{}}
+	list.SetResourceVersion(resourceVersion)
+	if opts.Limit > 0 && int64(len(resources)) > opts.Limit {
+		remaining := int64(len(resources)) - opts.Limit
+		resources = resources[:opts.Limit]
+		list.SetContinue(encodeCacheContinueToken(cacheKey(resources[len(resources)-1])))
+		list.SetRemainingItemCount(&remaining)
+	}
+
+	list.Items = make([]unstructured.Unstructured, 0, len(resources))
+	for _, resource := range resources {
+		list.Items = append(list.Items, *resource.DeepCopy())
+	}
+	return list, nil
+}
+
+func cacheKey(resource *unstructured.Unstructured) string {
+	return resource.GetNamespace() + "/" + resource.GetName()
+}
+
+func encodeCacheContinueToken(key string) string {
+	return cacheContinuePrefix + base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+func decodeCacheContinueToken(token string) (string, error) {
+	key, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(token, cacheContinuePrefix))
+	if err != nil {
+		return "", err
+	}
+	return string(key), nil
+}
@@ -0,0 +1,218 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/common"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var podGVR = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+
+func newTestPod(namespace string, name string, labels map[string]string) *unstructured.Unstructured {
+	pod := &unstructured.Unstructured{}
+	pod.SetAPIVersion("v1")
+	pod.SetKind("Pod")
+	pod.SetNamespace(namespace)
+	pod.SetName(name)
+	pod.SetLabels(labels)
+	return pod
+}
+
+func newTestInformerCache(t *testing.T) (*informerCache, *fakedynamic.FakeDynamicClient) {
+	client := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{podGVR: "PodList"},
+		newTestPod("default", "payments", map[string]string{"app": "payments"}),
+		newTestPod("default", "frontend", map[string]string{"app": "frontend"}),
+		newTestPod("kube-system", "coredns", nil),
+	)
+	informerCache := newInformerCache(client, time.Minute)
+	t.Cleanup(func() {
+		informerCache.stopIdleInformers(time.Now().Add(time.Hour))
+	})
+	return informerCache, client
+}
+
+func getSyncedResourceInterface(t *testing.T, informerCache *informerCache, namespace string) *cachedResourceInterface {
+	resourceInterface := informerCache.resourceInterface(podGVR, true, namespace, emptyNamespace).(*cachedResourceInterface)
+	assert.Eventually(t, informerCache.informerFor(podGVR).informer.Informer().HasSynced, 5*time.Second, 10*time.Millisecond)
+	return resourceInterface
+}
+
+func countListActions(client *fakedynamic.FakeDynamicClient) int {
+	count := 0
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "list" {
+			count++
+		}
+	}
+	return count
+}
+
+func getItemNames(list *unstructured.UnstructuredList) []string {
+	names := make([]string, 0)
+	for _, item := range list.Items {
+		names = append(names, item.GetName())
+	}
+	return names
+}
+
+func TestCachedListServedFromMemory(t *testing.T) {
+	informerCache, client := newTestInformerCache(t)
+	resourceInterface := getSyncedResourceInterface(t, informerCache, emptyNamespace)
+	listsAfterSync := countListActions(client)
+
+	list, err := resourceInterface.List(context.TODO(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"frontend", "payments", "coredns"}, getItemNames(list))
+	assert.Equal(t, listsAfterSync, countListActions(client))
+
+	list, err = resourceInterface.List(context.TODO(), metav1.ListOptions{LabelSelector: "app=payments"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"payments"}, getItemNames(list))
+}
+
+func TestCachedListInNamespace(t *testing.T) {
+	informerCache, _ := newTestInformerCache(t)
+	resourceInterface := getSyncedResourceInterface(t, informerCache, "kube-system")
+
+	list, err := resourceInterface.List(context.TODO(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"coredns"}, getItemNames(list))
+}
+
+func TestCachedListPagination(t *testing.T) {
+	informerCache, _ := newTestInformerCache(t)
+	resourceInterface := getSyncedResourceInterface(t, informerCache, emptyNamespace)
+
+	firstPage, err := resourceInterface.List(context.TODO(), metav1.ListOptions{Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"frontend", "payments"}, getItemNames(firstPage))
+	assert.Equal(t, int64(1), *firstPage.GetRemainingItemCount())
+	assert.Contains(t, firstPage.GetContinue(), cacheContinuePrefix)
+
+	secondPage, err := resourceInterface.List(context.TODO(), metav1.ListOptions{Limit: 2, Continue: firstPage.GetContinue()})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"coredns"}, getItemNames(secondPage))
+	assert.Empty(t, secondPage.GetContinue())
+}
+
+func TestCachedListFallsBackToAPIServer(t *testing.T) {
+	informerCache, client := newTestInformerCache(t)
+	resourceInterface := getSyncedResourceInterface(t, informerCache, emptyNamespace)
+	listsAfterSync := countListActions(client)
+
+	_, err := resourceInterface.List(context.TODO(), metav1.ListOptions{FieldSelector: "metadata.name=payments"})
+	assert.NoError(t, err)
+	assert.Equal(t, listsAfterSync+1, countListActions(client))
+}
+
+func TestCachedListBeforeSync(t *testing.T) {
+	client := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{podGVR: "PodList"},
+		newTestPod("default", "payments", map[string]string{"app": "payments"}),
+	)
+	// The informer lists without a selector and never syncs while such lists fail
+	client.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.(k8stesting.ListAction).GetListRestrictions().Labels.Empty() {
+			return true, nil, apierrors.NewServiceUnavailable("unavailable")
+		}
+		return false, nil, nil
+	})
+	informerCache := newInformerCache(client, time.Minute)
+	defer informerCache.stopIdleInformers(time.Now().Add(time.Hour))
+	resourceInterface := informerCache.resourceInterface(podGVR, true, emptyNamespace, emptyNamespace)
+
+	list, err := resourceInterface.List(context.TODO(), metav1.ListOptions{LabelSelector: "app=payments"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"payments"}, getItemNames(list))
+
+	_, err = resourceInterface.List(context.TODO(), metav1.ListOptions{Continue: encodeCacheContinueToken("default/a")})
+	assert.True(t, apierrors.IsResourceExpired(err))
+}
+
+func TestCachedGet(t *testing.T) {
+	informerCache, client := newTestInformerCache(t)
+	resourceInterface := getSyncedResourceInterface(t, informerCache, "default")
+	actionsAfterSync := len(client.Actions())
+
+	pod, err := resourceInterface.Get(context.TODO(), "payments", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "payments", pod.GetName())
+
+	_, err = resourceInterface.Get(context.TODO(), "coredns", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+	assert.Equal(t, actionsAfterSync, len(client.Actions()))
+}
+
+func TestStopIdleInformers(t *testing.T) {
+	informerCache, _ := newTestInformerCache(t)
+	informerCache.informerFor(podGVR)
+
+	informerCache.stopIdleInformers(time.Now())
+	assert.Len(t, informerCache.informers, 1)
+
+	informerCache.stopIdleInformers(time.Now().Add(informerCache.idleTimeout))
+	assert.Empty(t, informerCache.informers)
+}
+
+func TestInformerStartedOnlyByReads(t *testing.T) {
+	informerCache, _ := newTestInformerCache(t)
+	resourceInterface := informerCache.resourceInterface(podGVR, true, "default", emptyNamespace)
+
+	_, err := resourceInterface.Create(context.TODO(), newTestPod("default", "worker", nil), metav1.CreateOptions{})
+	assert.NoError(t, err)
+	err = resourceInterface.Delete(context.TODO(), "worker", metav1.DeleteOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, informerCache.informers)
+
+	_, _ = resourceInterface.Get(context.TODO(), "payments", metav1.GetOptions{})
+	assert.Len(t, informerCache.informers, 1)
+}
+
+func TestInformerNotStartedByUncacheableReads(t *testing.T) {
+	informerCache, _ := newTestInformerCache(t)
+	resourceInterface := informerCache.resourceInterface(podGVR, true, "default", emptyNamespace)
+
+	_, err := resourceInterface.List(context.TODO(), metav1.ListOptions{FieldSelector: "metadata.name=payments"})
+	assert.NoError(t, err)
+	_, err = resourceInterface.List(context.TODO(), metav1.ListOptions{ResourceVersion: "12"})
+	assert.NoError(t, err)
+	_, err = resourceInterface.Get(context.TODO(), "payments", metav1.GetOptions{ResourceVersion: "12"})
+	assert.NoError(t, err)
+	assert.Empty(t, informerCache.informers)
+}
+
+func TestIsCacheable(t *testing.T) {
+	defer func(exclude string) { common.ResourceTypesExclude = exclude }(common.ResourceTypesExclude)
+	common.ResourceTypesExclude = "ConfigMap,*.example.com"
+
+	tests := []struct {
+		name     string
+		group    string
+		kind     string
+		expected bool
+	}{
+		{"Pod", "", "Pod", true},
+		{"Secret", "", "Secret", false},
+		{"Excluded kind", "", "ConfigMap", false},
+		{"Excluded group", "example.com", "Certificate", false},
+		{"Secret of another group", "example.com", "Secret", false},
+		{"Deployment", "apps", "Deployment", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isCacheable(tt.group, tt.kind))
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 var (
@@ -20,6 +21,7 @@ var (
 
 const (
 	emptyNamespace    = ""
+	podString         = "Pod"
 	serviceString     = "Service"
 	deploymentString  = "Deployment"
 	statefulSetString = "StatefulSet"
@@ -29,13 +31,17 @@ const (
 	secretString      = "Secret"
 )
 
-func ListResources(resourceType string, namespace string, getResourceInterface ResourceInterfaceGetter) (models.ResourceList, *models.ModelError) {
+// ListResources lists resources of the given type. Pagination options (Limit and Continue) are passed
+// through to the API server, and the continue token of the next page is returned in the list.
+// Rows of Pods and Nodes get their usage from metrics.k8s.io read with getMetricsInterface, unless it is nil;
+// without metrics the usage columns stay empty and a warning is added to the list.
+func ListResources(ctx context.Context, resourceType string, namespace string, listOptions metav1.ListOptions, getResourceInterface ResourceInterfaceGetter, getMetricsInterface ResourceInterfaceGetter) (models.ResourceList, *models.ModelError) {
 	resourceInterface, err := getResourceInterface(resourceType, namespace, emptyNamespace)
 	if err != nil {
 		return models.ResourceList{}, err
 	}
 
-	resources, listErr := resourceInterface.List(context.TODO(), metav1.ListOptions{})
+	resources, listErr := resourceInterface.List(ctx, listOptions)
 
 	if listErr != nil {
 		return models.ResourceList{}, handleKubernetesError(listErr)
@@ -44,100 +50,115 @@ func ListResources(resourceType string, namespace string, getResourceInterface R
 	var resourceList models.ResourceList
 
 	resourceList.Columns = GetResourceListColumns(resourceType)
+	resourceList.ColumnTypes = GetResourceListColumnTypes(resourceType)
 	resourceList.ResourceList = []models.ResourceListResourceList{}
+	resourceList.ResourceVersion = resources.GetResourceVersion()
+	resourceList.Continue = resources.GetContinue()
+	resourceList.RemainingItemCount = resources.GetRemainingItemCount()
 
+	resourceType = getColumnsResourceType(resourceType)
 	for _, resource := range resources.Items {
-		var resourceDetailsTruncated models.ResourceListResourceList
+		resourceList.ResourceList = append(resourceList.ResourceList, extractResourceRow(resource, resourceType))
+	}
+	if warning := addResourceUsage(ctx, resourceType, namespace, resources.Items, resourceList.ResourceList, getMetricsInterface); warning != "" {
+		resourceList.Warnings = append(resourceList.Warnings, warning)
+	}
+	return resourceList, nil
+}
 
-		extractActive(resource, resourceType, &resourceDetailsTruncated)
+// extractResourceRow converts a resource into a list row holding the columns of its type.
+func extractResourceRow(resource unstructured.Unstructured, resourceType string) models.ResourceListResourceList {
+	var resourceDetailsTruncated models.ResourceListResourceList
 
-		extractAge(resource, resourceType, &resourceDetailsTruncated)
+	extractActive(resource, resourceType, &resourceDetailsTruncated)
 
-		extractBindings(resource, resourceType, &resourceDetailsTruncated)
+	extractAge(resource, resourceType, &resourceDetailsTruncated)
 
-		extractCapacity(resource, resourceType, &resourceDetailsTruncated)
+	extractBindings(resource, resourceType, &resourceDetailsTruncated)
 
-		extractClaim(resource, resourceType, &resourceDetailsTruncated)
+	extractCapacity(resource, resourceType, &resourceDetailsTruncated)
 
-		extractClusterIp(resource, resourceType, &resourceDetailsTruncated)
+	extractClaim(resource, resourceType, &resourceDetailsTruncated)
 
-		extractCompletions(resource, resourceType, &resourceDetailsTruncated)
+	extractClusterIp(resource, resourceType, &resourceDetailsTruncated)
 
-		extractConditions(resource, resourceType, &resourceDetailsTruncated)
+	extractCompletions(resource, resourceType, &resourceDetailsTruncated)
 
-		extractContainers(resource, resourceType, &resourceDetailsTruncated)
+	extractConditions(resource, resourceType, &resourceDetailsTruncated)
 
-		extractControlledBy(resource, resourceType, &resourceDetailsTruncated)
+	extractContainers(resource, resourceType, &resourceDetailsTruncated)
 
-		extractCurrent(resource, resourceType, &resourceDetailsTruncated)
+	extractControlledBy(resource, resourceType, &resourceDetailsTruncated)
 
-		extractDefault(resource, resourceType, &resourceDetailsTruncated)
+	extractCurrent(resource, resourceType, &resourceDetailsTruncated)
 
-		extractDesired(resource, resourceType, &resourceDetailsTruncated)
+	extractDefault(resource, resourceType, &resourceDetailsTruncated)
 
-		extractExternalIp(resource, resourceType, &resourceDetailsTruncated)
+	extractDesired(resource, resourceType, &resourceDetailsTruncated)
 
-		extractGroup(resource, resourceType, &resourceDetailsTruncated)
+	extractDuration(resource, resourceType, &resourceDetailsTruncated)
 
-		extractKeys(resource, resourceType, &resourceDetailsTruncated)
+	extractExternalIp(resource, resourceType, &resourceDetailsTruncated)
 
-		extractLabels(resource, resourceType, &resourceDetailsTruncated)
+	extractGroup(resource, resourceType, &resourceDetailsTruncated)
 
-		extractLastSchedule(resource, resourceType, &resourceDetailsTruncated)
+	extractKeys(resource, resourceType, &resourceDetailsTruncated)
 
-		extractLoadbalancers(resource, resourceType, &resourceDetailsTruncated)
+	extractLabels(resource, resourceType, &resourceDetailsTruncated)
 
-		extractName(resource, resourceType, &resourceDetailsTruncated)
+	extractLastSchedule(resource, resourceType, &resourceDetailsTruncated)
 
-		extractNamespace(resource, resourceType, &resourceDetailsTruncated)
+	extractLoadbalancers(resource, resourceType, &resourceDetailsTruncated)
 
-		extractNode(resource, resourceType, &resourceDetailsTruncated)
+	extractName(resource, resourceType, &resourceDetailsTruncated)
 
-		extractNodeSelector(resource, resourceType, &resourceDetailsTruncated)
+	extractNamespace(resource, resourceType, &resourceDetailsTruncated)
 
-		extractPods(resource, resourceType, &resourceDetailsTruncated)
+	extractNode(resource, resourceType, &resourceDetailsTruncated)
 
-		extractPorts(resource, resourceType, &resourceDetailsTruncated)
+	extractNodeSelector(resource, resourceType, &resourceDetailsTruncated)
 
-		extractProvisioner(resource, resourceType, &resourceDetailsTruncated)
+	extractPods(resource, resourceType, &resourceDetailsTruncated)
 
-		extractQos(resource, resourceType, &resourceDetailsTruncated)
+	extractPorts(resource, resourceType, &resourceDetailsTruncated)
 
-		extractReady(resource, resourceType, &resourceDetailsTruncated)
+	extractProvisioner(resource, resourceType, &resourceDetailsTruncated)
 
-		extractReclaimPolicy(resource, resourceType, &resourceDetailsTruncated)
+	extractQos(resource, resourceType, &resourceDetailsTruncated)
 
-		extractReplicas(resource, resourceType, &resourceDetailsTruncated)
+	extractReady(resource, resourceType, &resourceDetailsTruncated)
 
-		extractResource(resource, resourceType, &resourceDetailsTruncated)
+	extractReclaimPolicy(resource, resourceType, &resourceDetailsTruncated)
 
-		extractRestarts(resource, resourceType, &resourceDetailsTruncated)
+	extractReplicas(resource, resourceType, &resourceDetailsTruncated)
 
-		extractRoles(resource, resourceType, &resourceDetailsTruncated)
+	extractResource(resource, resourceType, &resourceDetailsTruncated)
 
-		extractSchedule(resource, resourceType, &resourceDetailsTruncated)
+	extractRestarts(resource, resourceType, &resourceDetailsTruncated)
 
-		extractScope(resource, resourceType, &resourceDetailsTruncated)
+	extractRoles(resource, resourceType, &resourceDetailsTruncated)
 
-		extractSelector(resource, resourceType, &resourceDetailsTruncated)
+	extractSchedule(resource, resourceType, &resourceDetailsTruncated)
 
-		extractSize(resource, resourceType, &resourceDetailsTruncated)
+	extractScope(resource, resourceType, &resourceDetailsTruncated)
 
-		extractStatus(resource, resourceType, &resourceDetailsTruncated)
+	extractSelector(resource, resourceType, &resourceDetailsTruncated)
 
-		extractStorageClass(resource, resourceType, &resourceDetailsTruncated)
+	extractSize(resource, resourceType, &resourceDetailsTruncated)
 
-		extractSuspend(resource, resourceType, &resourceDetailsTruncated)
+	extractStatus(resource, resourceType, &resourceDetailsTruncated)
 
-		extractTaints(resource, resourceType, &resourceDetailsTruncated)
+	extractStorageClass(resource, resourceType, &resourceDetailsTruncated)
 
-		extractType(resource, resourceType, &resourceDetailsTruncated)
+	extractSuspend(resource, resourceType, &resourceDetailsTruncated)
 
-		extractVersion(resource, resourceType, &resourceDetailsTruncated)
+	extractTaints(resource, resourceType, &resourceDetailsTruncated)
 
-		resourceList.ResourceList = append(resourceList.ResourceList, resourceDetailsTruncated)
-	}
-	return resourceList, nil
+	extractType(resource, resourceType, &resourceDetailsTruncated)
+
+	extractVersion(resource, resourceType, &resourceDetailsTruncated)
+
+	return resourceDetailsTruncated
 }
 
 func extractActive(resource unstructured.Unstructured, resourceType string, resourceDetailsTruncated *models.ResourceListResourceList) {
@@ -353,6 +374,31 @@ func extractDesired(resource unstructured.Unstructured, resourceType string, res
 	}
 }
 
+// extractDuration gives the time a Job ran for, like kubectl: until its completion, or until now while it runs.
+func extractDuration(resource unstructured.Unstructured, resourceType string, resourceDetailsTruncated *models.ResourceListResourceList) {
+	if slices.Contains(transposedResourceListColumns["duration"], resourceType) {
+		status, statusExists := resource.Object["status"].(map[string]interface{})
+		if !statusExists {
+			return
+		}
+		startTimeStr, found := status["startTime"].(string)
+		if !found {
+			return
+		}
+		startTime, err := time.Parse(time.RFC3339, startTimeStr)
+		if err != nil {
+			return
+		}
+		endTime := time.Now()
+		if completionTimeStr, found := status["completionTime"].(string); found {
+			if endTime, err = time.Parse(time.RFC3339, completionTimeStr); err != nil {
+				return
+			}
+		}
+		resourceDetailsTruncated.Duration = endTime.Sub(startTime).Round(time.Second).String()
+	}
+}
+
 func extractExternalIp(resource unstructured.Unstructured, resourceType string, resourceDetailsTruncated *models.ResourceListResourceList) {
 	if slices.Contains(transposedResourceListColumns["external_ip"], resourceType) {
 		resourceDetailsTruncated.ExternalIp = "-"
@@ -1,5 +1,7 @@
 package cluster
 
+import "github.com/ZPI-2024-25/KubernetesAccessManager/models"
+
 const (
 	activeStr        = "active"
 	ageStr           = "age"
@@ -11,15 +13,20 @@ const (
 	conditionsStr    = "conditions"
 	containersStr    = "containers"
 	controlledByStr  = "controlled_by"
+	cpuStr           = "cpu"
+	cpuPercentStr    = "cpu_percent"
 	currentStr       = "current"
 	defaultStr       = "default"
 	desiredStr       = "desired"
+	durationStr      = "duration"
 	externalIpStr    = "external_ip"
 	groupStr         = "group"
 	keysStr          = "keys"
 	labelsStr        = "labels"
 	lastScheduleStr  = "last_schedule"
 	loadbalancersStr = "loadbalancers"
+	memoryStr        = "memory"
+	memoryPercentStr = "memory_percent"
 	nameStr          = "name"
 	namespaceStr     = "namespace"
 	nodeStr          = "node"
@@ -48,7 +55,7 @@ const (
 
 var resourceListColumns = map[string][]string{
 	"ReplicaSet":               {nameStr, namespaceStr, desiredStr, currentStr, readyStr, ageStr},
-	"Pod":                      {nameStr, namespaceStr, containersStr, restartsStr, controlledByStr, nodeStr, qosStr, ageStr, statusStr},
+	"Pod":                      {nameStr, namespaceStr, containersStr, restartsStr, cpuStr, cpuPercentStr, memoryStr, memoryPercentStr, controlledByStr, nodeStr, qosStr, ageStr, statusStr},
 	"Deployment":               {nameStr, namespaceStr, podsStr, replicasStr, ageStr, conditionsStr},
 	"ConfigMap":                {nameStr, namespaceStr, keysStr, ageStr},
 	"Secret":                   {nameStr, namespaceStr, labelsStr, keysStr, typeStr, ageStr},
@@ -56,24 +63,66 @@ var resourceListColumns = map[string][]string{
 	"PersistentVolumeClaim":    {nameStr, namespaceStr, storageClassStr, sizeStr, ageStr, statusStr},
 	"StatefulSet":              {nameStr, namespaceStr, podsStr, replicasStr, ageStr},
 	"DaemonSet":                {nameStr, namespaceStr, podsStr, nodeSelectorStr, ageStr},
-	"Job":                      {nameStr, namespaceStr, completionsStr, ageStr, conditionsStr},
+	"Job":                      {nameStr, namespaceStr, completionsStr, durationStr, ageStr, conditionsStr},
 	"CronJob":                  {nameStr, namespaceStr, scheduleStr, suspendStr, activeStr, lastScheduleStr, ageStr},
 	"Service":                  {nameStr, namespaceStr, typeStr, clusterIpStr, portsStr, externalIpStr, selectorStr, ageStr, statusStr},
 	"ServiceAccount":           {nameStr, namespaceStr, ageStr},
-	"Node":                     {nameStr, taintsStr, rolesStr, versionStr, ageStr, conditionsStr},
+	"Node":                     {nameStr, taintsStr, rolesStr, versionStr, cpuStr, cpuPercentStr, memoryStr, memoryPercentStr, ageStr, conditionsStr},
 	"Namespace":                {nameStr, labelsStr, ageStr, statusStr},
 	"CustomResourceDefinition": {resourceStr, groupStr, versionStr, scopeStr, ageStr},
 	"PersistentVolume":         {nameStr, storageClassStr, capacityStr, claimStr, ageStr, statusStr},
 	"StorageClass":             {nameStr, provisionerStr, reclaimPolicyStr, defaultStr, ageStr},
 	"ClusterRole":              {nameStr, ageStr},
 	"ClusterRoleBinding":       {nameStr, bindingsStr, ageStr},
+	genericResourceType:        {nameStr, namespaceStr, ageStr},
+}
+
+// resourceListColumnTypes lists the columns that are not plain strings.
+var resourceListColumnTypes = map[string]models.ColumnType{
+	activeStr:        models.ColumnTypeInteger,
+	ageStr:           models.ColumnTypeTimestamp,
+	capacityStr:      models.ColumnTypeQuantity,
+	cpuStr:           models.ColumnTypeQuantity,
+	cpuPercentStr:    models.ColumnTypeInteger,
+	currentStr:       models.ColumnTypeInteger,
+	desiredStr:       models.ColumnTypeInteger,
+	durationStr:      models.ColumnTypeDuration,
+	lastScheduleStr:  models.ColumnTypeTimestamp,
+	memoryStr:        models.ColumnTypeQuantity,
+	memoryPercentStr: models.ColumnTypeInteger,
+	readyStr:         models.ColumnTypeInteger,
+	replicasStr:      models.ColumnTypeInteger,
+	restartsStr:      models.ColumnTypeInteger,
+	sizeStr:          models.ColumnTypeQuantity,
 }
 
+// genericResourceType keys the columns used for kinds without a dedicated entry, e.g. custom resources.
+const genericResourceType = "*"
+
 func GetResourceListColumns(resourceType string) []string {
-	if columns, ok := resourceListColumns[resourceType]; ok {
-		return columns
+	return resourceListColumns[getColumnsResourceType(resourceType)]
+}
+
+func GetResourceListColumnTypes(resourceType string) map[string]models.ColumnType {
+	columnTypes := make(map[string]models.ColumnType)
+	for _, column := range GetResourceListColumns(resourceType) {
+		columnTypes[column] = getColumnType(column)
+	}
+	return columnTypes
+}
+
+func getColumnType(column string) models.ColumnType {
+	if columnType, ok := resourceListColumnTypes[column]; ok {
+		return columnType
+	}
+	return models.ColumnTypeString
+}
+
+func getColumnsResourceType(resourceType string) string {
+	if _, ok := resourceListColumns[resourceType]; ok {
+		return resourceType
 	}
-	return []string{}
+	return genericResourceType
 }
 
 func transposeResourceListColumns(input map[string][]string) map[string][]string {
@@ -0,0 +1,258 @@
+package cluster
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/strings/slices"
+)
+
+const (
+	sortOrderAsc  = "asc"
+	sortOrderDesc = "desc"
+	// creationTimestampSortKey is accepted as sortBy in place of the age column
+	creationTimestampSortKey = "creationTimestamp"
+	containsOperator         = "~"
+)
+
+// filterOperators are ordered so that two character operators are matched first.
+var filterOperators = []string{">=", "<=", "!=", "=", ">", "<", containsOperator}
+
+var resourceListFieldIndexes = getResourceListFieldIndexes()
+
+// ColumnFilter keeps rows whose column value compares to Value with Operator.
+type ColumnFilter struct {
+	Column   string
+	Operator string
+	Value    string
+}
+
+// ResourceListQuery holds the sorting and filtering requested for a resource list.
+type ResourceListQuery struct {
+	SortBy  string
+	Order   string
+	Filters []ColumnFilter
+}
+
+// ValidateQueryPagination rejects sorting of paginated lists. The API server returns pages in its own
+// order, so a sorted page would not line up with the next one.
+func ValidateQueryPagination(query ResourceListQuery, listOptions metav1.ListOptions) *models.ModelError {
+	if query.SortBy != "" && (listOptions.Limit > 0 || listOptions.Continue != "") {
+		return &models.ModelError{Code: 400, Message: "sortBy cannot be combined with limit or continue"}
+	}
+	return nil
+}
+
+// ParseResourceListQuery validates sortBy, order and filters against the columns of the resource type.
+// Filters have the form "column<operator>value", e.g. "restarts>=3", "name~payments" or
+// "age<2024-01-01T00:00:00Z". Values are compared according to the column type.
+func ParseResourceListQuery(resourceType string, sortBy string, order string, filters []string) (ResourceListQuery, *models.ModelError) {
+	columns := GetResourceListColumns(resourceType)
+	query := ResourceListQuery{Order: sortOrderAsc}
+
+	if sortBy == creationTimestampSortKey {
+		sortBy = ageStr
+	}
+	if sortBy != "" && !slices.Contains(columns, sortBy) {
+		return ResourceListQuery{}, &models.ModelError{Code: 400, Message: fmt.Sprintf("Invalid sortBy column: %s", sortBy)}
+	}
+	query.SortBy = sortBy
+
+	switch order {
+	case "", sortOrderAsc:
+	case sortOrderDesc:
+		query.Order = sortOrderDesc
+	default:
+		return ResourceListQuery{}, &models.ModelError{Code: 400, Message: fmt.Sprintf("Invalid order: %s", order)}
+	}
+
+	for _, filter := range filters {
+		columnFilter, ok := parseColumnFilter(filter)
+		if !ok {
+			return ResourceListQuery{}, &models.ModelError{Code: 400, Message: fmt.Sprintf("Invalid filter: %s", filter)}
+		}
+		if !slices.Contains(columns, columnFilter.Column) {
+			return ResourceListQuery{}, &models.ModelError{Code: 400, Message: fmt.Sprintf("Invalid filter column: %s", columnFilter.Column)}
+		}
+		columnType := getColumnType(columnFilter.Column)
+		if columnFilter.Operator != containsOperator {
+			if _, ok := parseColumnValue(columnType, columnFilter.Value); !ok {
+				return ResourceListQuery{}, &models.ModelError{Code: 400, Message: fmt.Sprintf("Invalid %s value in filter: %s", columnType, filter)}
+			}
+		}
+		query.Filters = append(query.Filters, columnFilter)
+	}
+
+	return query, nil
+}
+
+func parseColumnFilter(filter string) (ColumnFilter, bool) {
+	operatorStart := strings.IndexAny(filter, "=!<>~")
+	if operatorStart <= 0 {
+		return ColumnFilter{}, false
+	}
+	column, rest := filter[:operatorStart], filter[operatorStart:]
+	for _, operator := range filterOperators {
+		if strings.HasPrefix(rest, operator) {
+			return ColumnFilter{Column: column, Operator: operator, Value: rest[len(operator):]}, true
+		}
+	}
+	return ColumnFilter{}, false
+}
+
+// FilterResourceList removes the rows that do not match every filter of the query.
+func FilterResourceList(resourceList *models.ResourceList, query ResourceListQuery) {
+	if len(query.Filters) == 0 {
+		return
+	}
+	filtered := make([]models.ResourceListResourceList, 0)
+	for _, row := range resourceList.ResourceList {
+		if matchesColumnFilters(row, query.Filters) {
+			filtered = append(filtered, row)
+		}
+	}
+	resourceList.ResourceList = filtered
+}
+
+// MatchesResourceListQuery reports whether a row passes all column filters of the query.
+func MatchesResourceListQuery(row models.ResourceListResourceList, query ResourceListQuery) bool {
+	return matchesColumnFilters(row, query.Filters)
+}
+
+func matchesColumnFilters(row models.ResourceListResourceList, filters []ColumnFilter) bool {
+	for _, filter := range filters {
+		value := getColumnValue(row, filter.Column)
+		if filter.Operator == containsOperator {
+			if !strings.Contains(strings.ToLower(value), strings.ToLower(filter.Value)) {
+				return false
+			}
+			continue
+		}
+
+		comparison, ok := compareTypedValues(getColumnType(filter.Column), value, filter.Value)
+		if !ok {
+			return false
+		}
+		if !matchesComparison(filter.Operator, comparison) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesComparison(operator string, comparison int) bool {
+	switch operator {
+	case "=":
+		return comparison == 0
+	case "!=":
+		return comparison != 0
+	case ">":
+		return comparison > 0
+	case ">=":
+		return comparison >= 0
+	case "<":
+		return comparison < 0
+	case "<=":
+		return comparison <= 0
+	}
+	return false
+}
+
+// SortResourceList sorts the rows by the query column. Values that cannot be parsed as the
+// column type, e.g. empty ones, are placed before all others in ascending order.
+func SortResourceList(resourceList *models.ResourceList, query ResourceListQuery) {
+	if query.SortBy == "" {
+		return
+	}
+	columnType := getColumnType(query.SortBy)
+	rows := resourceList.ResourceList
+	sort.SliceStable(rows, func(i, j int) bool {
+		comparison := compareColumnValues(columnType, getColumnValue(rows[i], query.SortBy), getColumnValue(rows[j], query.SortBy))
+		if query.Order == sortOrderDesc {
+			return comparison > 0
+		}
+		return comparison < 0
+	})
+}
+
+func compareColumnValues(columnType models.ColumnType, a string, b string) int {
+	if comparison, ok := compareTypedValues(columnType, a, b); ok {
+		return comparison
+	}
+	_, aValid := parseColumnValue(columnType, a)
+	_, bValid := parseColumnValue(columnType, b)
+	switch {
+	case aValid && !bValid:
+		return 1
+	case !aValid && bValid:
+		return -1
+	}
+	return strings.Compare(a, b)
+}
+
+// compareTypedValues compares two values of the column type, reporting false if either does not parse.
+func compareTypedValues(columnType models.ColumnType, a string, b string) (int, bool) {
+	aValue, aValid := parseColumnValue(columnType, a)
+	bValue, bValid := parseColumnValue(columnType, b)
+	if !aValid || !bValid {
+		return 0, false
+	}
+
+	switch columnType {
+	case models.ColumnTypeInteger:
+		return cmp.Compare(aValue.(int64), bValue.(int64)), true
+	case models.ColumnTypeDuration:
+		return cmp.Compare(aValue.(time.Duration), bValue.(time.Duration)), true
+	case models.ColumnTypeTimestamp:
+		return aValue.(time.Time).Compare(bValue.(time.Time)), true
+	case models.ColumnTypeQuantity:
+		aQuantity := aValue.(resource.Quantity)
+		return aQuantity.Cmp(bValue.(resource.Quantity)), true
+	}
+	return strings.Compare(a, b), true
+}
+
+func parseColumnValue(columnType models.ColumnType, value string) (interface{}, bool) {
+	var parsed interface{}
+	var err error
+	switch columnType {
+	case models.ColumnTypeInteger:
+		parsed, err = strconv.ParseInt(value, 10, 64)
+	case models.ColumnTypeDuration:
+		parsed, err = time.ParseDuration(value)
+	case models.ColumnTypeTimestamp:
+		parsed, err = time.Parse(time.RFC3339, value)
+	case models.ColumnTypeQuantity:
+		parsed, err = resource.ParseQuantity(value)
+	default:
+		parsed = value
+	}
+	return parsed, err == nil
+}
+
+func getColumnValue(row models.ResourceListResourceList, column string) string {
+	index, ok := resourceListFieldIndexes[column]
+	if !ok {
+		return ""
+	}
+	return reflect.ValueOf(row).Field(index).String()
+}
+
+// getResourceListFieldIndexes maps column names to fields of models.ResourceListResourceList by their JSON names.
+func getResourceListFieldIndexes() map[string]int {
+	indexes := make(map[string]int)
+	rowType := reflect.TypeOf(models.ResourceListResourceList{})
+	for i := 0; i < rowType.NumField(); i++ {
+		name, _, _ := strings.Cut(rowType.Field(i).Tag.Get("json"), ",")
+		indexes[name] = i
+	}
+	return indexes
+}
@@ -0,0 +1,233 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func mockPodResourceList() *models.ResourceList {
+	return &models.ResourceList{
+		Columns: GetResourceListColumns("Pod"),
+		ResourceList: []models.ResourceListResourceList{
+			{Name: "payments-1", Restarts: "10", Age: "2024-03-01T10:00:00Z", Status: "Running"},
+			{Name: "frontend", Restarts: "2", Age: "2024-01-01T10:00:00Z", Status: "Pending"},
+			{Name: "payments-2", Restarts: "", Age: "2024-02-01T10:00:00Z", Status: "Running"},
+			{Name: "backend", Restarts: "9", Age: "2024-04-01T10:00:00Z", Status: "Running"},
+		},
+	}
+}
+
+func getNames(resourceList *models.ResourceList) []string {
+	names := make([]string, 0)
+	for _, row := range resourceList.ResourceList {
+		names = append(names, row.Name)
+	}
+	return names
+}
+
+func TestParseResourceListQuery(t *testing.T) {
+	tests := []struct {
+		name          string
+		sortBy        string
+		order         string
+		filters       []string
+		expected      ResourceListQuery
+		expectedError *models.ModelError
+	}{
+		{
+			name:     "Defaults",
+			expected: ResourceListQuery{Order: sortOrderAsc},
+		},
+		{
+			name:     "Sort by column descending",
+			sortBy:   restartsStr,
+			order:    sortOrderDesc,
+			expected: ResourceListQuery{SortBy: restartsStr, Order: sortOrderDesc},
+		},
+		{
+			name:     "Sort by creationTimestamp",
+			sortBy:   creationTimestampSortKey,
+			expected: ResourceListQuery{SortBy: ageStr, Order: sortOrderAsc},
+		},
+		{
+			name:    "Filters",
+			filters: []string{"restarts>=3", "name~pay", "status!=Pending", "age<2024-03-01T00:00:00Z"},
+			expected: ResourceListQuery{Order: sortOrderAsc, Filters: []ColumnFilter{
+				{Column: restartsStr, Operator: ">=", Value: "3"},
+				{Column: nameStr, Operator: "~", Value: "pay"},
+				{Column: statusStr, Operator: "!=", Value: "Pending"},
+				{Column: ageStr, Operator: "<", Value: "2024-03-01T00:00:00Z"},
+			}},
+		},
+		{
+			name:          "Unknown sort column",
+			sortBy:        capacityStr,
+			expectedError: &models.ModelError{Code: 400, Message: "Invalid sortBy column: capacity"},
+		},
+		{
+			name:          "Invalid order",
+			order:         "up",
+			expectedError: &models.ModelError{Code: 400, Message: "Invalid order: up"},
+		},
+		{
+			name:          "Filter without operator",
+			filters:       []string{"restarts"},
+			expectedError: &models.ModelError{Code: 400, Message: "Invalid filter: restarts"},
+		},
+		{
+			name:          "Filter without column",
+			filters:       []string{"=3"},
+			expectedError: &models.ModelError{Code: 400, Message: "Invalid filter: =3"},
+		},
+		{
+			name:          "Unknown filter column",
+			filters:       []string{"replicas>1"},
+			expectedError: &models.ModelError{Code: 400, Message: "Invalid filter column: replicas"},
+		},
+		{
+			name:          "Value not matching column type",
+			filters:       []string{"restarts>many"},
+			expectedError: &models.ModelError{Code: 400, Message: "Invalid integer value in filter: restarts>many"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := ParseResourceListQuery("Pod", tt.sortBy, tt.order, tt.filters)
+			assert.Equal(t, tt.expectedError, err)
+			if tt.expectedError == nil {
+				assert.Equal(t, tt.expected, query)
+			}
+		})
+	}
+}
+
+func TestValidateQueryPagination(t *testing.T) {
+	sorted := ResourceListQuery{SortBy: restartsStr, Order: sortOrderAsc}
+	// The list spans two pages, the second one is requested with the continue token of the first
+	firstPage := metav1.ListOptions{Limit: 2}
+	secondPage := metav1.ListOptions{Limit: 2, Continue: "token"}
+	expectedError := &models.ModelError{Code: 400, Message: "sortBy cannot be combined with limit or continue"}
+
+	tests := []struct {
+		name          string
+		query         ResourceListQuery
+		listOptions   metav1.ListOptions
+		expectedError *models.ModelError
+	}{
+		{"Sorted whole list", sorted, metav1.ListOptions{}, nil},
+		{"Unsorted first page", ResourceListQuery{}, firstPage, nil},
+		{"Unsorted second page", ResourceListQuery{}, secondPage, nil},
+		{"Sorted first page", sorted, firstPage, expectedError},
+		{"Sorted second page", sorted, secondPage, expectedError},
+		{"Sorted continue without limit", sorted, metav1.ListOptions{Continue: "token"}, expectedError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedError, ValidateQueryPagination(tt.query, tt.listOptions))
+		})
+	}
+}
+
+func TestSortResourceList(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    ResourceListQuery
+		expected []string
+	}{
+		{"No sorting", ResourceListQuery{}, []string{"payments-1", "frontend", "payments-2", "backend"}},
+		{"Integer ascending", ResourceListQuery{SortBy: restartsStr, Order: sortOrderAsc}, []string{"payments-2", "frontend", "backend", "payments-1"}},
+		{"Integer descending", ResourceListQuery{SortBy: restartsStr, Order: sortOrderDesc}, []string{"payments-1", "backend", "frontend", "payments-2"}},
+		{"Timestamp ascending", ResourceListQuery{SortBy: ageStr, Order: sortOrderAsc}, []string{"frontend", "payments-2", "payments-1", "backend"}},
+		{"String ascending", ResourceListQuery{SortBy: nameStr, Order: sortOrderAsc}, []string{"backend", "frontend", "payments-1", "payments-2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resourceList := mockPodResourceList()
+			SortResourceList(resourceList, tt.query)
+			assert.Equal(t, tt.expected, getNames(resourceList))
+		})
+	}
+}
+
+func TestSortResourceListByDuration(t *testing.T) {
+	resourceList := &models.ResourceList{
+		Columns: GetResourceListColumns("Job"),
+		ResourceList: []models.ResourceListResourceList{
+			{Name: "backup", Duration: "2m0s"},
+			{Name: "migrate", Duration: "1h0m0s"},
+			{Name: "pending"},
+			{Name: "report", Duration: "45s"},
+		},
+	}
+
+	SortResourceList(resourceList, ResourceListQuery{SortBy: durationStr, Order: sortOrderAsc})
+
+	// Compared as durations, not as strings, and rows without one come first
+	assert.Equal(t, []string{"pending", "report", "backup", "migrate"}, getNames(resourceList))
+}
+
+func TestFilterResourceList(t *testing.T) {
+	tests := []struct {
+		name     string
+		filters  []ColumnFilter
+		expected []string
+	}{
+		{"No filters", nil, []string{"payments-1", "frontend", "payments-2", "backend"}},
+		{"Integer comparison", []ColumnFilter{{restartsStr, ">", "5"}}, []string{"payments-1", "backend"}},
+		{"Empty values never match comparisons", []ColumnFilter{{restartsStr, "<", "5"}}, []string{"frontend"}},
+		{"Contains is case insensitive", []ColumnFilter{{nameStr, "~", "PAY"}}, []string{"payments-1", "payments-2"}},
+		{"Timestamp comparison", []ColumnFilter{{ageStr, ">=", "2024-03-01T10:00:00Z"}}, []string{"payments-1", "backend"}},
+		{"All filters must match", []ColumnFilter{{statusStr, "=", "Running"}, {restartsStr, "!=", "9"}}, []string{"payments-1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resourceList := mockPodResourceList()
+			FilterResourceList(resourceList, ResourceListQuery{Filters: tt.filters})
+			assert.Equal(t, tt.expected, getNames(resourceList))
+		})
+	}
+}
+
+func TestCompareTypedValues(t *testing.T) {
+	tests := []struct {
+		columnType    models.ColumnType
+		a             string
+		b             string
+		expected      int
+		expectedValid bool
+	}{
+		{models.ColumnTypeInteger, "9", "10", -1, true},
+		{models.ColumnTypeQuantity, "1Gi", "1000Mi", 1, true},
+		{models.ColumnTypeQuantity, "500m", "0.5", 0, true},
+		{models.ColumnTypeDuration, "90s", "1m", 1, true},
+		{models.ColumnTypeTimestamp, "2024-01-01T00:00:00Z", "2024-01-01T01:00:00+01:00", 0, true},
+		{models.ColumnTypeString, "b", "a", 1, true},
+		{models.ColumnTypeInteger, "", "1", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.columnType)+" "+tt.a+" "+tt.b, func(t *testing.T) {
+			result, valid := compareTypedValues(tt.columnType, tt.a, tt.b)
+			assert.Equal(t, tt.expectedValid, valid)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestGetResourceListColumnTypes(t *testing.T) {
+	columnTypes := GetResourceListColumnTypes("Pod")
+	assert.Len(t, columnTypes, len(GetResourceListColumns("Pod")))
+	assert.Equal(t, models.ColumnTypeInteger, columnTypes[restartsStr])
+	assert.Equal(t, models.ColumnTypeTimestamp, columnTypes[ageStr])
+	assert.Equal(t, models.ColumnTypeString, columnTypes[nameStr])
+
+	assert.Equal(t, models.ColumnTypeQuantity, GetResourceListColumnTypes("PersistentVolumeClaim")[sizeStr])
+	assert.Equal(t, models.ColumnTypeDuration, GetResourceListColumnTypes("Job")[durationStr])
+}
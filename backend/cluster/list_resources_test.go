@@ -10,16 +10,19 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/dynamic"
 	"testing"
+	"time"
 )
 
 type MockListResourceInterface struct {
 	dynamic.ResourceInterface
 	mock.Mock
-	ReturnedList  *unstructured.UnstructuredList
-	ReturnedError error
+	ReturnedList    *unstructured.UnstructuredList
+	ReturnedError   error
+	ReceivedOptions metav1.ListOptions
 }
 
 func (m *MockListResourceInterface) List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	m.ReceivedOptions = opts
 	return m.ReturnedList, m.ReturnedError
 }
 
@@ -57,7 +60,7 @@ func TestListResourcesError(t *testing.T) {
 	}
 
 	t.Run("Test ListResources Error", func(t *testing.T) {
-		result, err := ListResources("Pod", "validNamespace", getResourceI)
+		result, err := ListResources(context.TODO(), "Pod", "validNamespace", metav1.ListOptions{}, getResourceI, nil)
 		assert.NotNil(t, err)
 		assert.Equal(t, expectedModelError, err)
 		assert.Equal(t, models.ResourceList{}, result)
@@ -78,7 +81,7 @@ func TestListResourcesSuccess(t *testing.T) {
 	}
 
 	t.Run("Test ListResources Success", func(t *testing.T) {
-		result, err := ListResources("Pod", "validNamespace", getResourceI)
+		result, err := ListResources(context.TODO(), "Pod", "validNamespace", metav1.ListOptions{}, getResourceI, nil)
 		assert.Nil(t, err)
 		assert.Equal(t, 2, len(result.ResourceList))
 		for _, resource := range result.ResourceList {
@@ -87,6 +90,30 @@ func TestListResourcesSuccess(t *testing.T) {
 	})
 }
 
+func TestListResourcesPagination(t *testing.T) {
+	remainingItemCount := int64(5)
+	returnedList := MockUnstructuredList()
+	returnedList.SetContinue("next-page-token")
+	returnedList.SetRemainingItemCount(&remainingItemCount)
+	mockResourceInterface := &MockListResourceInterface{
+		ReturnedList: returnedList,
+	}
+
+	getResourceI := func(resourceType string, namespace string, emptyNamespace string) (dynamic.ResourceInterface, *models.ModelError) {
+		return mockResourceInterface, nil
+	}
+
+	t.Run("Test ListResources passes pagination options and returns continue token", func(t *testing.T) {
+		listOptions := metav1.ListOptions{Limit: 2, Continue: "current-page-token"}
+		result, err := ListResources(context.TODO(), "Pod", "validNamespace", listOptions, getResourceI, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, listOptions, mockResourceInterface.ReceivedOptions)
+		assert.Equal(t, 2, len(result.ResourceList))
+		assert.Equal(t, "next-page-token", result.Continue)
+		assert.Equal(t, &remainingItemCount, result.RemainingItemCount)
+	})
+}
+
 func TestListResourcesErrorFromList(t *testing.T) {
 	mockGetResourceI := new(mock.Mock)
 	expectedError := errors.New("failed to list resources")
@@ -102,7 +129,7 @@ func TestListResourcesErrorFromList(t *testing.T) {
 	}
 
 	t.Run("Test ListResources Error from List", func(t *testing.T) {
-		result, err := ListResources("Pod", "validNamespace", getResourceI)
+		result, err := ListResources(context.TODO(), "Pod", "validNamespace", metav1.ListOptions{}, getResourceI, nil)
 		expectedModelError := &models.ModelError{Code: 500, Message: "Internal server error: " + expectedError.Error()}
 		assert.NotNil(t, err)
 		assert.Equal(t, expectedModelError, err)
@@ -1063,6 +1090,88 @@ func TestExtractDesired(t *testing.T) {
 	}
 }
 
+func TestExtractDuration(t *testing.T) {
+	tests := []struct {
+		name           string
+		resource       unstructured.Unstructured
+		resourceType   string
+		expectedResult string
+	}{
+		{
+			name:           "ResourceType not in transposedResourceListColumns['duration']",
+			resource:       unstructured.Unstructured{},
+			resourceType:   "ConfigMap",
+			expectedResult: "",
+		},
+		{
+			name: "Job not started",
+			resource: unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"status": map[string]interface{}{},
+				},
+			},
+			resourceType:   "Job",
+			expectedResult: "",
+		},
+		{
+			name: "Job completed",
+			resource: unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"status": map[string]interface{}{
+						"startTime":      "2024-01-01T10:00:00Z",
+						"completionTime": "2024-01-01T10:01:30Z",
+					},
+				},
+			},
+			resourceType:   "Job",
+			expectedResult: "1m30s",
+		},
+		{
+			name: "Invalid completion time",
+			resource: unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"status": map[string]interface{}{
+						"startTime":      "2024-01-01T10:00:00Z",
+						"completionTime": "soon",
+					},
+				},
+			},
+			resourceType:   "Job",
+			expectedResult: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resourceDetailsTruncated := &models.ResourceListResourceList{}
+
+			extractDuration(tt.resource, tt.resourceType, resourceDetailsTruncated)
+
+			if resourceDetailsTruncated.Duration != tt.expectedResult {
+				t.Errorf("Expected Duration to be '%s', got '%s'", tt.expectedResult, resourceDetailsTruncated.Duration)
+			}
+		})
+	}
+}
+
+func TestExtractDurationOfRunningJob(t *testing.T) {
+	resource := unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"status": map[string]interface{}{
+				"startTime": time.Now().Add(-time.Hour).UTC().Format(time.RFC3339),
+			},
+		},
+	}
+	resourceDetailsTruncated := &models.ResourceListResourceList{}
+
+	extractDuration(resource, "Job", resourceDetailsTruncated)
+
+	duration, err := time.ParseDuration(resourceDetailsTruncated.Duration)
+	if err != nil || duration < time.Hour {
+		t.Errorf("Expected Duration of at least an hour, got '%s'", resourceDetailsTruncated.Duration)
+	}
+}
+
 func TestExtractExternalIp(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -3423,3 +3532,8 @@ func TestExtractVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestGetResourceListColumns(t *testing.T) {
+	assert.Equal(t, resourceListColumns["Pod"], GetResourceListColumns("Pod"))
+	assert.Equal(t, []string{nameStr, namespaceStr, ageStr}, GetResourceListColumns("Certificate.cert-manager.io"))
+}
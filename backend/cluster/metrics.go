@@ -0,0 +1,42 @@
+package cluster
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	discoveryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kam_discovery_duration_seconds",
+		Help:    "Time spent reading API discovery data, by whether the cache was fresh before the call.",
+		Buckets: prometheus.ExponentialBuckets(0.0001, 4, 10),
+	}, []string{"cache"})
+
+	discoveryCacheInvalidations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kam_discovery_cache_invalidations_total",
+		Help: "Number of discovery cache invalidations, by reason.",
+	}, []string{"reason"})
+
+	informerCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kam_informer_cache_requests_total",
+		Help: "Number of list and get requests handled by the informer cache, by whether they were served from memory.",
+	}, []string{"result"})
+
+	activeInformers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "kam_informer_cache_active_informers",
+		Help: "Number of running informers backing the informer cache.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(discoveryDuration, discoveryCacheInvalidations, informerCacheRequests, activeInformers)
+}
+
+func observeDiscoveryDuration(cacheFresh bool, start time.Time) {
+	cache := "miss"
+	if cacheFresh {
+		cache = "hit"
+	}
+	discoveryDuration.WithLabelValues(cache).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,296 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	DrainPodEvicting = "evicting"
+	DrainPodWaiting  = "waiting"
+	DrainPodEvicted  = "evicted"
+	DrainPodSkipped  = "skipped"
+	DrainPodFailed   = "failed"
+
+	// mirrorPodAnnotation marks the API objects of static Pods, which the kubelet runs from files on the Node
+	mirrorPodAnnotation = "kubernetes.io/config.mirror"
+)
+
+var (
+	// drainPollInterval is how often blocked evictions are retried and evicted Pods are checked for being gone.
+	drainPollInterval = 5 * time.Second
+	// defaultDrainTimeout bounds drains started without a timeout, as they keep running when the stream is closed.
+	defaultDrainTimeout = time.Hour
+)
+
+// DrainOptions control which Pods are evicted from a Node and how long draining may take.
+type DrainOptions struct {
+	// GracePeriodSeconds overrides the grace period of the evicted Pods, nil keeps their own
+	GracePeriodSeconds *int64
+	Timeout            time.Duration
+	// Force also evicts Pods not managed by a controller, which are not recreated anywhere else
+	Force bool
+	// DeleteEmptyDirData also evicts Pods with emptyDir volumes, whose data is lost
+	DeleteEmptyDirData bool
+	// AuthorizeForceEviction is called with the namespace of every Pod to be evicted when GracePeriodSeconds is 0,
+	// as evicting with a zero grace period is a force deletion of the Pod
+	AuthorizeForceEviction func(namespace string) *models.ModelError
+}
+
+// DrainUpdate is sent while draining a Node, either with the progress of a single Pod or with the final report.
+type DrainUpdate struct {
+	Progress *models.DrainPodStatus
+	Report   *models.DrainReport
+}
+
+// CordonNode marks a Node as unschedulable, or schedulable again when unschedulable is false, like kubectl cordon and uncordon.
+func CordonNode(nodeName string, unschedulable bool, getClientset ClientsetGetter) *models.ModelError {
+	clientset, err := getClientset()
+	if err != nil {
+		return err
+	}
+	return setNodeUnschedulable(context.TODO(), clientset, nodeName, unschedulable)
+}
+
+// DrainNode cordons a Node and evicts its Pods through the Eviction API, so PodDisruptionBudgets are respected:
+// evictions they block are retried until the timeout. Pods of DaemonSets and static Pods are skipped, as they
+// would be recreated on the Node at once. The progress of every Pod is sent as it changes and the report is sent
+// last. Once started, the evictions are independent of ctx: when it is done updates are no longer sent, but
+// draining goes on until it finishes or times out, so the Node is not left half-drained. The Node stays cordoned
+// when draining fails.
+func DrainNode(ctx context.Context, nodeName string, options DrainOptions, getClientset ClientsetGetter) (<-chan DrainUpdate, *models.ModelError) {
+	if options.GracePeriodSeconds != nil && *options.GracePeriodSeconds < 0 {
+		return nil, &models.ModelError{Code: 400, Message: "Grace period must not be negative"}
+	}
+	clientset, err := getClientset()
+	if err != nil {
+		return nil, err
+	}
+	pods, listErr := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+	})
+	if listErr != nil {
+		return nil, handleKubernetesError(listErr)
+	}
+	if err := authorizeForceEvictions(pods.Items, options); err != nil {
+		return nil, err
+	}
+	// Cordoning before the response starts reports a missing Node or missing permissions
+	if err := setNodeUnschedulable(ctx, clientset, nodeName, true); err != nil {
+		return nil, err
+	}
+
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = defaultDrainTimeout
+	}
+	updates := make(chan DrainUpdate)
+	go func() {
+		defer close(updates)
+		// The timeout only stops the evictions, the report is still sent afterwards
+		drainCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+		defer cancel()
+		drain := &nodeDrain{
+			ctx:       drainCtx,
+			done:      ctx.Done(),
+			clientset: clientset,
+			options:   options,
+			updates:   updates,
+			report:    models.DrainReport{Node: nodeName, Evicted: []models.DrainPodStatus{}, Skipped: []models.DrainPodStatus{}, Failed: []models.DrainPodStatus{}},
+		}
+		drain.run(pods.Items)
+		log.Printf("Drain of Node %s finished: %d evicted, %d skipped, %d failed",
+			nodeName, len(drain.report.Evicted), len(drain.report.Skipped), len(drain.report.Failed))
+	}()
+	return updates, nil
+}
+
+// authorizeForceEvictions checks the namespace of every Pod which a drain with a zero grace period would evict.
+func authorizeForceEvictions(pods []corev1.Pod, options DrainOptions) *models.ModelError {
+	if options.GracePeriodSeconds == nil || *options.GracePeriodSeconds != 0 || options.AuthorizeForceEviction == nil {
+		return nil
+	}
+	checked := make(map[string]struct{})
+	for i := range pods {
+		pod := &pods[i]
+		if getDrainSkipReason(pod) != "" || getDrainBlockReason(pod, options) != "" {
+			continue
+		}
+		if _, found := checked[pod.Namespace]; found {
+			continue
+		}
+		checked[pod.Namespace] = struct{}{}
+		if err := options.AuthorizeForceEviction(pod.Namespace); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nodeDrain evicts the Pods of a Node in parallel, collecting the final state of every Pod in the report.
+type nodeDrain struct {
+	ctx       context.Context
+	done      <-chan struct{}
+	clientset kubernetes.Interface
+	options   DrainOptions
+	updates   chan<- DrainUpdate
+	mutex     sync.Mutex
+	report    models.DrainReport
+}
+
+func (d *nodeDrain) run(pods []corev1.Pod) {
+	var wg sync.WaitGroup
+	for i := range pods {
+		pod := &pods[i]
+		if reason := getDrainSkipReason(pod); reason != "" {
+			d.finish(pod, DrainPodSkipped, reason)
+			continue
+		}
+		if reason := getDrainBlockReason(pod, d.options); reason != "" {
+			d.finish(pod, DrainPodFailed, reason)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.evict(pod)
+		}()
+	}
+	wg.Wait()
+
+	d.report.Succeeded = len(d.report.Failed) == 0
+	d.send(DrainUpdate{Report: &d.report})
+}
+
+// evict retries an eviction blocked by a PodDisruptionBudget until the drain times out, then waits for the Pod to be gone.
+func (d *nodeDrain) evict(pod *corev1.Pod) {
+	d.progress(pod, DrainPodEvicting, "")
+	eviction := &policyv1.Eviction{
+		ObjectMeta:    metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+		DeleteOptions: &metav1.DeleteOptions{GracePeriodSeconds: d.options.GracePeriodSeconds},
+	}
+	for {
+		evictErr := d.clientset.PolicyV1().Evictions(pod.Namespace).Evict(d.ctx, eviction)
+		if evictErr == nil || errors.IsNotFound(evictErr) {
+			break
+		}
+		if !errors.IsTooManyRequests(evictErr) {
+			d.finish(pod, DrainPodFailed, handleKubernetesError(evictErr).Message)
+			return
+		}
+		d.progress(pod, DrainPodWaiting, evictErr.Error())
+		if !d.wait() {
+			d.finish(pod, DrainPodFailed, fmt.Sprintf("Eviction was blocked until the drain stopped: %s", evictErr))
+			return
+		}
+	}
+
+	for {
+		current, getErr := d.clientset.CoreV1().Pods(pod.Namespace).Get(d.ctx, pod.Name, metav1.GetOptions{})
+		if errors.IsNotFound(getErr) || (getErr == nil && current.UID != pod.UID) {
+			d.finish(pod, DrainPodEvicted, "")
+			return
+		}
+		if getErr != nil && d.ctx.Err() == nil {
+			d.finish(pod, DrainPodFailed, handleKubernetesError(getErr).Message)
+			return
+		}
+		if !d.wait() {
+			d.finish(pod, DrainPodFailed, "Pod was evicted, but did not terminate before the drain stopped")
+			return
+		}
+	}
+}
+
+func (d *nodeDrain) wait() bool {
+	select {
+	case <-time.After(drainPollInterval):
+		return true
+	case <-d.ctx.Done():
+		return false
+	}
+}
+
+func (d *nodeDrain) progress(pod *corev1.Pod, status string, message string) {
+	d.send(DrainUpdate{Progress: &models.DrainPodStatus{Name: pod.Name, Namespace: pod.Namespace, Status: status, Message: message}})
+}
+
+func (d *nodeDrain) finish(pod *corev1.Pod, status string, message string) {
+	podStatus := models.DrainPodStatus{Name: pod.Name, Namespace: pod.Namespace, Status: status, Message: message}
+	d.mutex.Lock()
+	switch status {
+	case DrainPodEvicted:
+		d.report.Evicted = append(d.report.Evicted, podStatus)
+	case DrainPodSkipped:
+		d.report.Skipped = append(d.report.Skipped, podStatus)
+	default:
+		d.report.Failed = append(d.report.Failed, podStatus)
+	}
+	d.mutex.Unlock()
+	d.send(DrainUpdate{Progress: &podStatus})
+}
+
+// send drops updates once nobody reads them anymore.
+func (d *nodeDrain) send(update DrainUpdate) {
+	select {
+	case d.updates <- update:
+	case <-d.done:
+	}
+}
+
+// getDrainSkipReason tells why a Pod stays on the Node, or returns an empty string for Pods to evict.
+func getDrainSkipReason(pod *corev1.Pod) string {
+	if _, found := pod.Annotations[mirrorPodAnnotation]; found {
+		return "Static Pod, managed by the kubelet"
+	}
+	if controller := metav1.GetControllerOfNoCopy(pod); controller != nil && controller.Kind == daemonSetString {
+		return fmt.Sprintf("Managed by DaemonSet %s", controller.Name)
+	}
+	return ""
+}
+
+// getDrainBlockReason tells why a Pod may not be evicted without an explicit option, like kubectl drain does.
+// Finished Pods are always evicted, as nothing is lost with them.
+func getDrainBlockReason(pod *corev1.Pod, options DrainOptions) string {
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return ""
+	}
+	if !options.Force && metav1.GetControllerOfNoCopy(pod) == nil {
+		return "Pod is not managed by a controller and would not be recreated, use force to evict it"
+	}
+	if !options.DeleteEmptyDirData {
+		for _, volume := range pod.Spec.Volumes {
+			if volume.EmptyDir != nil {
+				return fmt.Sprintf("Pod has the emptyDir volume %s, whose data would be lost, use deleteEmptyDirData to evict it", volume.Name)
+			}
+		}
+	}
+	return ""
+}
+
+func setNodeUnschedulable(ctx context.Context, clientset kubernetes.Interface, nodeName string, unschedulable bool) *models.ModelError {
+	patch, marshalErr := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{"unschedulable": unschedulable},
+	})
+	if marshalErr != nil {
+		return &models.ModelError{Code: 500, Message: fmt.Sprintf("Failed to create patch: %s", marshalErr)}
+	}
+	_, patchErr := clientset.CoreV1().Nodes().Patch(ctx, nodeName, types.StrategicMergePatchType, patch, metav1.PatchOptions{FieldManager: FieldManager})
+	if patchErr != nil {
+		return handleKubernetesError(patchErr)
+	}
+	return nil
+}
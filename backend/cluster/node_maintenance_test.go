@@ -0,0 +1,244 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newTestNode() *corev1.Node {
+	return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}}
+}
+
+func newTestNodePod(name string, controllerKind string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "payments", UID: types.UID("uid-" + name)},
+		Spec:       corev1.PodSpec{NodeName: "worker-1"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	if controllerKind != "" {
+		controller := true
+		pod.OwnerReferences = []metav1.OwnerReference{{Kind: controllerKind, Name: "owner", Controller: &controller}}
+	}
+	return pod
+}
+
+// evictByDeleting makes evictions delete the Pod, unless blocked returns true for the attempt.
+func evictByDeleting(clientset *fake.Clientset, blocked func(attempt int) bool) {
+	attempts := map[string]int{}
+	clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(k8stesting.CreateAction).GetObject().(*policyv1.Eviction)
+		attempts[eviction.Name]++
+		if blocked != nil && blocked(attempts[eviction.Name]) {
+			return true, nil, apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 10)
+		}
+		return true, nil, clientset.Tracker().Delete(podGVR, eviction.Namespace, eviction.Name)
+	})
+}
+
+func collectDrainUpdates(t *testing.T, updates <-chan DrainUpdate) ([]models.DrainPodStatus, *models.DrainReport) {
+	progress := make([]models.DrainPodStatus, 0)
+	for {
+		select {
+		case update, open := <-updates:
+			if !open {
+				t.Fatal("Drain ended without a report")
+			}
+			if update.Report != nil {
+				return progress, update.Report
+			}
+			progress = append(progress, *update.Progress)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Drain did not finish")
+		}
+	}
+}
+
+func useTestDrainPollInterval(t *testing.T) {
+	interval := drainPollInterval
+	drainPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { drainPollInterval = interval })
+}
+
+func TestCordonNode(t *testing.T) {
+	clientset := fake.NewSimpleClientset(newTestNode())
+
+	err := CordonNode("worker-1", true, getTestClientset(clientset))
+
+	assert.Nil(t, err)
+	node, _ := clientset.CoreV1().Nodes().Get(context.TODO(), "worker-1", metav1.GetOptions{})
+	assert.True(t, node.Spec.Unschedulable)
+
+	err = CordonNode("worker-1", false, getTestClientset(clientset))
+
+	assert.Nil(t, err)
+	node, _ = clientset.CoreV1().Nodes().Get(context.TODO(), "worker-1", metav1.GetOptions{})
+	assert.False(t, node.Spec.Unschedulable)
+}
+
+func TestCordonNodeNotFound(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	err := CordonNode("worker-1", true, getTestClientset(clientset))
+
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(404), err.Code)
+}
+
+func TestDrainNode(t *testing.T) {
+	useTestDrainPollInterval(t)
+	mirrorPod := newTestNodePod("kube-proxy-worker-1", "")
+	mirrorPod.Annotations = map[string]string{mirrorPodAnnotation: "hash"}
+	emptyDirPod := newTestNodePod("cache-0", "StatefulSet")
+	emptyDirPod.Spec.Volumes = []corev1.Volume{{Name: "scratch", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+	finishedPod := newTestNodePod("migration", "")
+	finishedPod.Status.Phase = corev1.PodSucceeded
+	clientset := fake.NewSimpleClientset(
+		newTestNode(),
+		newTestNodePod("api-5d8f-x2x9", "ReplicaSet"),
+		newTestNodePod("fluentd-7k2p", daemonSetString),
+		mirrorPod,
+		newTestNodePod("debug", ""),
+		emptyDirPod,
+		finishedPod,
+	)
+	evictByDeleting(clientset, nil)
+
+	updates, err := DrainNode(context.Background(), "worker-1", DrainOptions{}, getTestClientset(clientset))
+
+	assert.Nil(t, err)
+	progress, report := collectDrainUpdates(t, updates)
+	assert.False(t, report.Succeeded)
+	assert.ElementsMatch(t, []string{"api-5d8f-x2x9", "migration"}, drainPodNames(report.Evicted))
+	assert.ElementsMatch(t, []string{"fluentd-7k2p", "kube-proxy-worker-1"}, drainPodNames(report.Skipped))
+	assert.ElementsMatch(t, []string{"debug", "cache-0"}, drainPodNames(report.Failed))
+	assert.NotEmpty(t, progress)
+	node, _ := clientset.CoreV1().Nodes().Get(context.TODO(), "worker-1", metav1.GetOptions{})
+	assert.True(t, node.Spec.Unschedulable)
+	_, getErr := clientset.CoreV1().Pods("payments").Get(context.TODO(), "fluentd-7k2p", metav1.GetOptions{})
+	assert.Nil(t, getErr)
+}
+
+func TestDrainNodeWithForceAndDeleteEmptyDirData(t *testing.T) {
+	useTestDrainPollInterval(t)
+	emptyDirPod := newTestNodePod("cache-0", "StatefulSet")
+	emptyDirPod.Spec.Volumes = []corev1.Volume{{Name: "scratch", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+	clientset := fake.NewSimpleClientset(newTestNode(), newTestNodePod("debug", ""), emptyDirPod)
+	evictByDeleting(clientset, nil)
+
+	updates, err := DrainNode(context.Background(), "worker-1", DrainOptions{Force: true, DeleteEmptyDirData: true}, getTestClientset(clientset))
+
+	assert.Nil(t, err)
+	_, report := collectDrainUpdates(t, updates)
+	assert.True(t, report.Succeeded)
+	assert.ElementsMatch(t, []string{"debug", "cache-0"}, drainPodNames(report.Evicted))
+	assert.Empty(t, report.Failed)
+}
+
+func TestDrainNodeRetriesEvictionBlockedByDisruptionBudget(t *testing.T) {
+	useTestDrainPollInterval(t)
+	clientset := fake.NewSimpleClientset(newTestNode(), newTestNodePod("api-5d8f-x2x9", "ReplicaSet"))
+	evictByDeleting(clientset, func(attempt int) bool { return attempt < 3 })
+
+	updates, err := DrainNode(context.Background(), "worker-1", DrainOptions{}, getTestClientset(clientset))
+
+	assert.Nil(t, err)
+	progress, report := collectDrainUpdates(t, updates)
+	assert.True(t, report.Succeeded)
+	assert.Equal(t, []string{"api-5d8f-x2x9"}, drainPodNames(report.Evicted))
+	statuses := make([]string, 0, len(progress))
+	for _, status := range progress {
+		statuses = append(statuses, status.Status)
+	}
+	assert.Equal(t, []string{DrainPodEvicting, DrainPodWaiting, DrainPodWaiting, DrainPodEvicted}, statuses)
+}
+
+func TestDrainNodeReportsBlockedEvictionAfterTimeout(t *testing.T) {
+	useTestDrainPollInterval(t)
+	clientset := fake.NewSimpleClientset(newTestNode(), newTestNodePod("api-5d8f-x2x9", "ReplicaSet"))
+	evictByDeleting(clientset, func(attempt int) bool { return true })
+
+	updates, err := DrainNode(context.Background(), "worker-1", DrainOptions{Timeout: 50 * time.Millisecond}, getTestClientset(clientset))
+
+	assert.Nil(t, err)
+	_, report := collectDrainUpdates(t, updates)
+	assert.False(t, report.Succeeded)
+	assert.Equal(t, []string{"api-5d8f-x2x9"}, drainPodNames(report.Failed))
+	assert.Contains(t, report.Failed[0].Message, "disruption budget")
+}
+
+func TestDrainNodeNotFound(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	updates, err := DrainNode(context.Background(), "worker-1", DrainOptions{}, getTestClientset(clientset))
+
+	assert.Nil(t, updates)
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(404), err.Code)
+}
+
+func TestDrainNodeNegativeGracePeriod(t *testing.T) {
+	gracePeriod := int64(-1)
+
+	_, err := DrainNode(context.Background(), "worker-1", DrainOptions{GracePeriodSeconds: &gracePeriod}, getTestClientset(fake.NewSimpleClientset(newTestNode())))
+
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(400), err.Code)
+}
+
+func TestDrainNodeZeroGracePeriodRequiresForceEviction(t *testing.T) {
+	clientset := fake.NewSimpleClientset(newTestNode(), newTestNodePod("api-5d8f-x2x9", "ReplicaSet"), newTestNodePod("fluentd-7k2p", daemonSetString))
+	gracePeriod := int64(0)
+	checked := make([]string, 0)
+	options := DrainOptions{GracePeriodSeconds: &gracePeriod, AuthorizeForceEviction: func(namespace string) *models.ModelError {
+		checked = append(checked, namespace)
+		return &models.ModelError{Code: 403, Message: "Forbidden"}
+	}}
+
+	updates, err := DrainNode(context.Background(), "worker-1", options, getTestClientset(clientset))
+
+	assert.Nil(t, updates)
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(403), err.Code)
+	assert.Equal(t, []string{"payments"}, checked)
+	node, _ := clientset.CoreV1().Nodes().Get(context.TODO(), "worker-1", metav1.GetOptions{})
+	assert.False(t, node.Spec.Unschedulable)
+}
+
+func TestDrainNodeContinuesAfterStreamIsClosed(t *testing.T) {
+	useTestDrainPollInterval(t)
+	clientset := fake.NewSimpleClientset(newTestNode(), newTestNodePod("api-5d8f-x2x9", "ReplicaSet"))
+	evictByDeleting(clientset, func(attempt int) bool { return attempt < 3 })
+	ctx, cancel := context.WithCancel(context.Background())
+
+	updates, err := DrainNode(ctx, "worker-1", DrainOptions{}, getTestClientset(clientset))
+	cancel()
+
+	assert.Nil(t, err)
+	for range updates {
+	}
+	_, getErr := clientset.CoreV1().Pods("payments").Get(context.TODO(), "api-5d8f-x2x9", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(getErr))
+}
+
+func drainPodNames(statuses []models.DrainPodStatus) []string {
+	names := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		names = append(names, status.Name)
+	}
+	return names
+}
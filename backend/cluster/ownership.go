@@ -0,0 +1,376 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// HelmReleaseKind is the kind of the nodes of ownership graphs which stand for Helm releases
+	HelmReleaseKind = "HelmRelease"
+	// helmResourceType is the resource under which Helm releases are authorized in the role map
+	helmResourceType = "Helm"
+
+	helmReleaseNameAnnotation      = "meta.helm.sh/release-name"
+	helmReleaseNamespaceAnnotation = "meta.helm.sh/release-namespace"
+	// maxOwnershipDepth guards against owner references forming a cycle
+	maxOwnershipDepth = 10
+)
+
+// ownedResourceTypes lists the types of resources which the controllers of a kind create and own. Only these
+// types are searched for owned resources, as finding resources of any type would require listing all types.
+var ownedResourceTypes = map[string][]string{
+	deploymentString:  {"apps/v1/ReplicaSet"},
+	"ReplicaSet":      {"v1/Pod"},
+	statefulSetString: {"v1/Pod"},
+	daemonSetString:   {"v1/Pod"},
+	"CronJob":         {"batch/v1/Job"},
+	jobString:         {"v1/Pod"},
+}
+
+// ownershipGraphBuilder builds ownership graphs, remembering the lists and permission checks already done.
+type ownershipGraphBuilder struct {
+	namespace             string
+	getResourceInterface  ResourceInterfaceGetter
+	filter                ResourceFilter
+	normalizeResourceType func(string) (string, *models.ModelError)
+	resourceTypes         map[string]string
+	visible               map[string]bool
+	ownedResources        map[string][]unstructured.Unstructured
+}
+
+func newOwnershipGraphBuilder(namespace string, getResourceInterface ResourceInterfaceGetter, filter ResourceFilter) *ownershipGraphBuilder {
+	return &ownershipGraphBuilder{
+		namespace:             namespace,
+		getResourceInterface:  getResourceInterface,
+		filter:                filter,
+		normalizeResourceType: NormalizeResourceType,
+		resourceTypes:         make(map[string]string),
+		visible:               make(map[string]bool),
+		ownedResources:        make(map[string][]unstructured.Unstructured),
+	}
+}
+
+// GetOwnershipGraph returns the owners of a resource, following the controller references up to the topmost owner
+// and the Helm release which installed it, and the tree of resources the resource owns. Resources which do not
+// pass the filter are left out, the resources they own take their place in the tree.
+func GetOwnershipGraph(resourceType string, namespace string, resourceName string, getResourceInterface ResourceInterfaceGetter, filter ResourceFilter) (models.OwnershipGraph, *models.ModelError) {
+	resourceInterface, err := getResourceInterface(resourceType, namespace, DefaultNamespace)
+	if err != nil {
+		return models.OwnershipGraph{}, err
+	}
+	resource, getErr := resourceInterface.Get(context.TODO(), resourceName, metav1.GetOptions{})
+	if getErr != nil {
+		return models.OwnershipGraph{}, handleKubernetesError(getErr)
+	}
+
+	builder := newOwnershipGraphBuilder(namespace, getResourceInterface, filter)
+	owners, err := builder.buildOwners(resource)
+	if err != nil {
+		return models.OwnershipGraph{}, err
+	}
+	node, err := builder.buildNode(resource, 0)
+	if err != nil {
+		return models.OwnershipGraph{}, err
+	}
+	return models.OwnershipGraph{Owners: owners, Resource: node}, nil
+}
+
+// GetOwnershipTrees returns the trees of resources owned by the objects, e.g. those of a Helm release manifest.
+// Objects without a namespace are looked for in the given namespace, missing objects are left out.
+func GetOwnershipTrees(objects []*unstructured.Unstructured, namespace string, getResourceInterface ResourceInterfaceGetter, filter ResourceFilter) ([]models.OwnershipNode, *models.ModelError) {
+	builder := newOwnershipGraphBuilder(namespace, getResourceInterface, filter)
+	nodes := make([]models.OwnershipNode, 0)
+	for _, object := range objects {
+		objectNamespace := object.GetNamespace()
+		if objectNamespace == "" {
+			objectNamespace = namespace
+		}
+		resource, err := builder.getResource(object.GetAPIVersion(), object.GetKind(), objectNamespace, object.GetName())
+		if err != nil {
+			return nil, err
+		}
+		if resource == nil {
+			continue
+		}
+		objectNodes, err := builder.buildVisibleNodes(resource, 0)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, objectNodes...)
+	}
+	return nodes, nil
+}
+
+// buildOwners follows the controller references of the resource, or its first owner references when there is no
+// controller. The chain ends at an owner which is missing, may not be read or was replaced by another with the same name.
+func (b *ownershipGraphBuilder) buildOwners(resource *unstructured.Unstructured) ([]models.OwnershipNode, *models.ModelError) {
+	owners := make([]models.OwnershipNode, 0)
+	top := resource
+	for depth := 0; depth < maxOwnershipDepth; depth++ {
+		ownerReference := getOwnerReference(top)
+		if ownerReference == nil {
+			break
+		}
+		owner, err := b.getResource(ownerReference.APIVersion, ownerReference.Kind, top.GetNamespace(), ownerReference.Name)
+		if err != nil {
+			return nil, err
+		}
+		if owner == nil || owner.GetUID() != ownerReference.UID {
+			break
+		}
+		visible, err := b.isVisible(owner)
+		if err != nil {
+			return nil, err
+		}
+		if visible {
+			owners = append(owners, toOwnershipNode(owner))
+		}
+		top = owner
+	}
+
+	releaseName := top.GetAnnotations()[helmReleaseNameAnnotation]
+	releaseNamespace := top.GetAnnotations()[helmReleaseNamespaceAnnotation]
+	if releaseName != "" && releaseNamespace != "" {
+		visible, err := b.filter(helmResourceType, releaseNamespace)
+		if err != nil {
+			return nil, err
+		}
+		if visible {
+			owners = append(owners, models.OwnershipNode{Kind: HelmReleaseKind, Name: releaseName, Namespace: releaseNamespace})
+		}
+	}
+	return owners, nil
+}
+
+// buildNode returns the node of the resource with the visible resources it owns.
+func (b *ownershipGraphBuilder) buildNode(resource *unstructured.Unstructured, depth int) (models.OwnershipNode, *models.ModelError) {
+	node := toOwnershipNode(resource)
+	if depth >= maxOwnershipDepth {
+		return node, nil
+	}
+	for _, ownedResourceType := range ownedResourceTypes[resource.GetKind()] {
+		candidates, err := b.listOwnedResources(ownedResourceType, resource.GetNamespace())
+		if err != nil {
+			return models.OwnershipNode{}, err
+		}
+		for i := range candidates {
+			if !isOwnedBy(&candidates[i], resource.GetUID()) {
+				continue
+			}
+			children, err := b.buildVisibleNodes(&candidates[i], depth+1)
+			if err != nil {
+				return models.OwnershipNode{}, err
+			}
+			node.Children = append(node.Children, children...)
+		}
+	}
+	return node, nil
+}
+
+// buildVisibleNodes returns the node of the resource, or the visible resources it owns in place of a resource which may not be read.
+func (b *ownershipGraphBuilder) buildVisibleNodes(resource *unstructured.Unstructured, depth int) ([]models.OwnershipNode, *models.ModelError) {
+	node, err := b.buildNode(resource, depth)
+	if err != nil {
+		return nil, err
+	}
+	visible, err := b.isVisible(resource)
+	if err != nil {
+		return nil, err
+	}
+	if visible {
+		return []models.OwnershipNode{node}, nil
+	}
+	return node.Children, nil
+}
+
+// isVisible checks the resource against the filter. Cluster-scoped resources are checked in the namespace of the
+// request, and resources of types which are not served, e.g. excluded by the allowlist, are never visible.
+func (b *ownershipGraphBuilder) isVisible(resource *unstructured.Unstructured) (bool, *models.ModelError) {
+	typeName := fmt.Sprintf("%s/%s", resource.GetAPIVersion(), resource.GetKind())
+	resourceType, resolved := b.resourceTypes[typeName]
+	if !resolved {
+		resourceType, _ = b.normalizeResourceType(typeName)
+		b.resourceTypes[typeName] = resourceType
+	}
+	if resourceType == "" {
+		return false, nil
+	}
+
+	namespace := resource.GetNamespace()
+	if namespace == "" {
+		namespace = b.namespace
+	}
+	key := resourceType + "/" + namespace
+	if visible, checked := b.visible[key]; checked {
+		return visible, nil
+	}
+	visible, err := b.filter(resourceType, namespace)
+	if err != nil {
+		return false, err
+	}
+	b.visible[key] = visible
+	return visible, nil
+}
+
+// getResource returns nil for resources which are missing, may not be read from the cluster or are of unknown types.
+func (b *ownershipGraphBuilder) getResource(apiVersion string, kind string, namespace string, name string) (*unstructured.Unstructured, *models.ModelError) {
+	resourceInterface, err := b.getResourceInterface(fmt.Sprintf("%s/%s", apiVersion, kind), namespace, DefaultNamespace)
+	if err != nil {
+		if err.Code == 400 {
+			return nil, nil
+		}
+		return nil, err
+	}
+	resource, getErr := resourceInterface.Get(context.TODO(), name, metav1.GetOptions{})
+	if getErr != nil {
+		if errors.IsNotFound(getErr) || errors.IsForbidden(getErr) {
+			return nil, nil
+		}
+		return nil, handleKubernetesError(getErr)
+	}
+	return resource, nil
+}
+
+// listOwnedResources lists the resources of a type in the namespace once, as they are searched for the resources
+// owned by every sibling, e.g. all ReplicaSets of a Deployment. Types which may not be listed give no resources.
+func (b *ownershipGraphBuilder) listOwnedResources(resourceType string, namespace string) ([]unstructured.Unstructured, *models.ModelError) {
+	key := resourceType + "/" + namespace
+	if resources, listed := b.ownedResources[key]; listed {
+		return resources, nil
+	}
+
+	resourceInterface, err := b.getResourceInterface(resourceType, namespace, DefaultNamespace)
+	if err != nil {
+		return nil, err
+	}
+	list, listErr := resourceInterface.List(context.TODO(), metav1.ListOptions{})
+	if listErr != nil && !errors.IsForbidden(listErr) {
+		return nil, handleKubernetesError(listErr)
+	}
+	var resources []unstructured.Unstructured
+	if listErr == nil {
+		resources = list.Items
+	}
+	b.ownedResources[key] = resources
+	return resources, nil
+}
+
+// getOwnerReference returns the controller reference of the resource, or its first owner reference if it has no controller.
+func getOwnerReference(resource *unstructured.Unstructured) *metav1.OwnerReference {
+	ownerReferences := resource.GetOwnerReferences()
+	if len(ownerReferences) == 0 {
+		return nil
+	}
+	if controllerReference := metav1.GetControllerOfNoCopy(resource); controllerReference != nil {
+		return controllerReference
+	}
+	return &ownerReferences[0]
+}
+
+func isOwnedBy(resource *unstructured.Unstructured, uid types.UID) bool {
+	for _, ownerReference := range resource.GetOwnerReferences() {
+		if ownerReference.UID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+func toOwnershipNode(resource *unstructured.Unstructured) models.OwnershipNode {
+	return models.OwnershipNode{
+		ApiVersion: resource.GetAPIVersion(),
+		Kind:       resource.GetKind(),
+		Name:       resource.GetName(),
+		Namespace:  resource.GetNamespace(),
+		Uid:        string(resource.GetUID()),
+		Status:     getStatusSummary(resource),
+	}
+}
+
+// getStatusSummary describes the state of a resource in a few words, the way kubectl get does in its status columns.
+func getStatusSummary(resource *unstructured.Unstructured) string {
+	if resource.GetDeletionTimestamp() != nil {
+		return "Terminating"
+	}
+
+	switch resource.GetKind() {
+	case "Pod":
+		return getPodStatusSummary(resource)
+	case deploymentString, "ReplicaSet", statefulSetString:
+		desired, found, _ := unstructured.NestedInt64(resource.Object, "spec", "replicas")
+		if !found {
+			desired = 1
+		}
+		ready, _, _ := unstructured.NestedInt64(resource.Object, "status", "readyReplicas")
+		return fmt.Sprintf("%d/%d ready", ready, desired)
+	case daemonSetString:
+		desired, _, _ := unstructured.NestedInt64(resource.Object, "status", "desiredNumberScheduled")
+		ready, _, _ := unstructured.NestedInt64(resource.Object, "status", "numberReady")
+		return fmt.Sprintf("%d/%d ready", ready, desired)
+	case jobString:
+		for _, conditionType := range []string{"Complete", "Failed", "Suspended"} {
+			if hasTrueCondition(resource, conditionType) {
+				return conditionType
+			}
+		}
+		return "Running"
+	case "CronJob":
+		if suspend, _, _ := unstructured.NestedBool(resource.Object, "spec", "suspend"); suspend {
+			return "Suspended"
+		}
+		if active, _, _ := unstructured.NestedSlice(resource.Object, "status", "active"); len(active) > 0 {
+			return "Active"
+		}
+		return "Scheduled"
+	}
+
+	if phase, _, _ := unstructured.NestedString(resource.Object, "status", "phase"); phase != "" {
+		return phase
+	}
+	if _, found, _ := unstructured.NestedSlice(resource.Object, "status", "conditions"); found {
+		if hasTrueCondition(resource, "Ready") {
+			return "Ready"
+		}
+		return "NotReady"
+	}
+	return ""
+}
+
+// getPodStatusSummary prefers the reason a container is waiting or has terminated with, e.g. CrashLoopBackOff, to the phase.
+func getPodStatusSummary(resource *unstructured.Unstructured) string {
+	if reason, _, _ := unstructured.NestedString(resource.Object, "status", "reason"); reason != "" {
+		return reason
+	}
+	containerStatuses, _, _ := unstructured.NestedSlice(resource.Object, "status", "containerStatuses")
+	for _, containerStatus := range containerStatuses {
+		containerStatusMap, ok := containerStatus.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, state := range []string{"waiting", "terminated"} {
+			if reason, _, _ := unstructured.NestedString(containerStatusMap, "state", state, "reason"); reason != "" && reason != "Completed" {
+				return reason
+			}
+		}
+	}
+	phase, _, _ := unstructured.NestedString(resource.Object, "status", "phase")
+	return phase
+}
+
+func hasTrueCondition(resource *unstructured.Unstructured, conditionType string) bool {
+	conditions, _, _ := unstructured.NestedSlice(resource.Object, "status", "conditions")
+	for _, condition := range conditions {
+		conditionMap, ok := condition.(map[string]interface{})
+		if ok && conditionMap["type"] == conditionType && conditionMap["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
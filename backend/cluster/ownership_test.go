@@ -0,0 +1,183 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+)
+
+var testOwnershipResources = map[string]schema.GroupVersionResource{
+	"apps/v1/Deployment": {Group: "apps", Version: "v1", Resource: "deployments"},
+	"apps/v1/ReplicaSet": {Group: "apps", Version: "v1", Resource: "replicasets"},
+	"v1/Pod":             podGVR,
+}
+
+func newTestOwnedObject(apiVersion string, kind string, name string, owner *unstructured.Unstructured) *unstructured.Unstructured {
+	object := &unstructured.Unstructured{}
+	object.SetAPIVersion(apiVersion)
+	object.SetKind(kind)
+	object.SetNamespace("payments")
+	object.SetName(name)
+	object.SetUID(types.UID(name + "-uid"))
+	if owner != nil {
+		controller := true
+		object.SetOwnerReferences([]metav1.OwnerReference{{
+			APIVersion: owner.GetAPIVersion(),
+			Kind:       owner.GetKind(),
+			Name:       owner.GetName(),
+			UID:        owner.GetUID(),
+			Controller: &controller,
+		}})
+	}
+	return object
+}
+
+// newTestOwnershipGraphBuilder serves the objects from a fake dynamic client and lets through the types which are not hidden.
+func newTestOwnershipGraphBuilder(hidden map[string]bool, objects ...runtime.Object) *ownershipGraphBuilder {
+	listKinds := map[schema.GroupVersionResource]string{
+		testOwnershipResources["apps/v1/Deployment"]: "DeploymentList",
+		testOwnershipResources["apps/v1/ReplicaSet"]: "ReplicaSetList",
+		podGVR: "PodList",
+	}
+	client := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objects...)
+	getResourceInterface := func(resourceType string, namespace string, defaultNamespace string) (dynamic.ResourceInterface, *models.ModelError) {
+		gvr, found := testOwnershipResources[resourceType]
+		if !found {
+			return nil, &models.ModelError{Code: 400, Message: "Unsupported resource type"}
+		}
+		return client.Resource(gvr).Namespace(namespace), nil
+	}
+	filter := func(resourceType string, namespace string) (bool, *models.ModelError) {
+		return !hidden[resourceType], nil
+	}
+	builder := newOwnershipGraphBuilder("payments", getResourceInterface, filter)
+	builder.normalizeResourceType = func(typeName string) (string, *models.ModelError) {
+		return typeName, nil
+	}
+	return builder
+}
+
+func TestBuildOwnershipTree(t *testing.T) {
+	deployment := newTestOwnedObject("apps/v1", "Deployment", "api", nil)
+	replicaSet := newTestOwnedObject("apps/v1", "ReplicaSet", "api-5d8f", deployment)
+	pod := newTestOwnedObject("v1", "Pod", "api-5d8f-x2x9", replicaSet)
+	otherPod := newTestOwnedObject("v1", "Pod", "worker-0", nil)
+	builder := newTestOwnershipGraphBuilder(nil, deployment, replicaSet, pod, otherPod)
+
+	node, err := builder.buildNode(deployment, 0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "api", node.Name)
+	assert.Len(t, node.Children, 1)
+	assert.Equal(t, "api-5d8f", node.Children[0].Name)
+	assert.Len(t, node.Children[0].Children, 1)
+	assert.Equal(t, "api-5d8f-x2x9", node.Children[0].Children[0].Name)
+	assert.Equal(t, "api-5d8f-x2x9-uid", node.Children[0].Children[0].Uid)
+}
+
+func TestBuildOwnershipTreeHoistsHiddenResources(t *testing.T) {
+	deployment := newTestOwnedObject("apps/v1", "Deployment", "api", nil)
+	replicaSet := newTestOwnedObject("apps/v1", "ReplicaSet", "api-5d8f", deployment)
+	pod := newTestOwnedObject("v1", "Pod", "api-5d8f-x2x9", replicaSet)
+	builder := newTestOwnershipGraphBuilder(map[string]bool{"apps/v1/ReplicaSet": true}, deployment, replicaSet, pod)
+
+	node, err := builder.buildNode(deployment, 0)
+
+	assert.Nil(t, err)
+	assert.Len(t, node.Children, 1)
+	assert.Equal(t, "Pod", node.Children[0].Kind)
+}
+
+func TestBuildOwners(t *testing.T) {
+	deployment := newTestOwnedObject("apps/v1", "Deployment", "api", nil)
+	deployment.SetAnnotations(map[string]string{
+		helmReleaseNameAnnotation:      "payments-api",
+		helmReleaseNamespaceAnnotation: "payments",
+	})
+	replicaSet := newTestOwnedObject("apps/v1", "ReplicaSet", "api-5d8f", deployment)
+	pod := newTestOwnedObject("v1", "Pod", "api-5d8f-x2x9", replicaSet)
+	builder := newTestOwnershipGraphBuilder(nil, deployment, replicaSet, pod)
+
+	owners, err := builder.buildOwners(pod)
+
+	assert.Nil(t, err)
+	assert.Len(t, owners, 3)
+	assert.Equal(t, "api-5d8f", owners[0].Name)
+	assert.Equal(t, "api", owners[1].Name)
+	assert.Equal(t, models.OwnershipNode{Kind: HelmReleaseKind, Name: "payments-api", Namespace: "payments"}, owners[2])
+}
+
+func TestBuildOwnersStopsAtReplacedOwner(t *testing.T) {
+	replicaSet := newTestOwnedObject("apps/v1", "ReplicaSet", "api-5d8f", nil)
+	pod := newTestOwnedObject("v1", "Pod", "api-5d8f-x2x9", replicaSet)
+	replacement := newTestOwnedObject("apps/v1", "ReplicaSet", "api-5d8f", nil)
+	replacement.SetUID("replacement-uid")
+	builder := newTestOwnershipGraphBuilder(nil, replacement, pod)
+
+	owners, err := builder.buildOwners(pod)
+
+	assert.Nil(t, err)
+	assert.Empty(t, owners)
+}
+
+func TestBuildOwnersSkipsHiddenHelmRelease(t *testing.T) {
+	deployment := newTestOwnedObject("apps/v1", "Deployment", "api", nil)
+	deployment.SetAnnotations(map[string]string{
+		helmReleaseNameAnnotation:      "payments-api",
+		helmReleaseNamespaceAnnotation: "payments",
+	})
+	builder := newTestOwnershipGraphBuilder(map[string]bool{helmResourceType: true}, deployment)
+
+	owners, err := builder.buildOwners(deployment)
+
+	assert.Nil(t, err)
+	assert.Empty(t, owners)
+}
+
+func TestGetStatusSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		object   map[string]interface{}
+		expected string
+	}{
+		{"Pod phase", map[string]interface{}{"kind": "Pod", "status": map[string]interface{}{"phase": "Running"}}, "Running"},
+		{"Pod waiting container", map[string]interface{}{"kind": "Pod", "status": map[string]interface{}{
+			"phase": "Running",
+			"containerStatuses": []interface{}{
+				map[string]interface{}{"state": map[string]interface{}{"waiting": map[string]interface{}{"reason": "CrashLoopBackOff"}}},
+			},
+		}}, "CrashLoopBackOff"},
+		{"Deployment", map[string]interface{}{"kind": "Deployment", "spec": map[string]interface{}{"replicas": int64(3)}, "status": map[string]interface{}{"readyReplicas": int64(2)}}, "2/3 ready"},
+		{"DaemonSet", map[string]interface{}{"kind": "DaemonSet", "status": map[string]interface{}{"desiredNumberScheduled": int64(4), "numberReady": int64(4)}}, "4/4 ready"},
+		{"Complete Job", map[string]interface{}{"kind": "Job", "status": map[string]interface{}{
+			"conditions": []interface{}{map[string]interface{}{"type": "Complete", "status": "True"}},
+		}}, "Complete"},
+		{"Suspended CronJob", map[string]interface{}{"kind": "CronJob", "spec": map[string]interface{}{"suspend": true}}, "Suspended"},
+		{"Ready condition", map[string]interface{}{"kind": "Node", "status": map[string]interface{}{
+			"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "False"}},
+		}}, "NotReady"},
+		{"No status", map[string]interface{}{"kind": "ConfigMap"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, getStatusSummary(&unstructured.Unstructured{Object: tt.object}))
+		})
+	}
+}
+
+func TestGetStatusSummaryTerminating(t *testing.T) {
+	pod := newTestOwnedObject("v1", "Pod", "api-0", nil)
+	now := metav1.Now()
+	pod.SetDeletionTimestamp(&now)
+
+	assert.Equal(t, "Terminating", getStatusSummary(pod))
+}
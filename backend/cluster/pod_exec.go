@@ -0,0 +1,167 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/util/exec"
+)
+
+const (
+	PodExecSubresource   = "exec"
+	PodAttachSubresource = "attach"
+)
+
+// PodExecOptions selects the container and the command of an exec session. An attach session
+// connects to the main process of the container instead, so the command is not used.
+type PodExecOptions struct {
+	// Subresource is either PodExecSubresource or PodAttachSubresource, exec by default
+	Subresource string
+	Namespace   string
+	PodName     string
+	Container   string
+	Command     []string
+	TTY         bool
+}
+
+// PodExecStreams connects an exec session to the client. Stderr is not used with a TTY,
+// where the terminal merges it into Stdout.
+type PodExecStreams struct {
+	Stdin             io.Reader
+	Stdout            io.Writer
+	Stderr            io.Writer
+	TerminalSizeQueue remotecommand.TerminalSizeQueue
+}
+
+// TerminalSizeQueue passes terminal resizes of the client to an exec session.
+type TerminalSizeQueue struct {
+	ctx   context.Context
+	sizes chan remotecommand.TerminalSize
+}
+
+func NewTerminalSizeQueue(ctx context.Context) *TerminalSizeQueue {
+	return &TerminalSizeQueue{ctx: ctx, sizes: make(chan remotecommand.TerminalSize, 1)}
+}
+
+// Push queues a new terminal size, replacing a size that has not been sent yet.
+func (q *TerminalSizeQueue) Push(width uint16, height uint16) {
+	size := remotecommand.TerminalSize{Width: width, Height: height}
+	for {
+		select {
+		case q.sizes <- size:
+			return
+		case <-q.ctx.Done():
+			return
+		default:
+			select {
+			case <-q.sizes:
+			default:
+			}
+		}
+	}
+}
+
+// Next blocks until the terminal is resized, returning nil when the session has ended.
+func (q *TerminalSizeQueue) Next() *remotecommand.TerminalSize {
+	select {
+	case size := <-q.sizes:
+		return &size
+	case <-q.ctx.Done():
+		return nil
+	}
+}
+
+// ExecInPod runs a command in a Pod container, or attaches to it, until it exits or ctx is done, returning its exit code.
+// The WebSocket protocol is used when the API server supports it, with SPDY as a fallback.
+func ExecInPod(ctx context.Context, options PodExecOptions, streams PodExecStreams, getConfig ConfigGetter) (int, *models.ModelError) {
+	config, err := getConfig()
+	if err != nil {
+		return 0, err
+	}
+
+	execURL, urlErr := getExecURL(config, options, streams.Stdin != nil)
+	if urlErr != nil {
+		return 0, &models.ModelError{Code: 500, Message: fmt.Sprintf("Failed to get client: %s", urlErr)}
+	}
+	executor, executorErr := newExecutor(config, execURL)
+	if executorErr != nil {
+		return 0, &models.ModelError{Code: 500, Message: fmt.Sprintf("Failed to create executor: %s", executorErr)}
+	}
+
+	streamOptions := remotecommand.StreamOptions{
+		Stdin:             streams.Stdin,
+		Stdout:            streams.Stdout,
+		Tty:               options.TTY,
+		TerminalSizeQueue: streams.TerminalSizeQueue,
+	}
+	if !options.TTY {
+		streamOptions.Stderr = streams.Stderr
+	}
+
+	streamErr := executor.StreamWithContext(ctx, streamOptions)
+	var exitErr exec.ExitError
+	if errors.As(streamErr, &exitErr) && exitErr.Exited() {
+		return exitErr.ExitStatus(), nil
+	}
+	if streamErr != nil {
+		return 0, handleKubernetesError(streamErr)
+	}
+	return 0, nil
+}
+
+func getExecURL(config *rest.Config, options PodExecOptions, stdin bool) (*url.URL, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	request := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(options.Namespace).
+		Name(options.PodName)
+	if options.Subresource == PodAttachSubresource {
+		return request.SubResource(PodAttachSubresource).
+			VersionedParams(&corev1.PodAttachOptions{
+				Container: options.Container,
+				Stdin:     stdin,
+				Stdout:    true,
+				Stderr:    !options.TTY,
+				TTY:       options.TTY,
+			}, scheme.ParameterCodec).
+			URL(), nil
+	}
+	return request.SubResource(PodExecSubresource).
+		VersionedParams(&corev1.PodExecOptions{
+			Container: options.Container,
+			Command:   options.Command,
+			Stdin:     stdin,
+			Stdout:    true,
+			Stderr:    !options.TTY,
+			TTY:       options.TTY,
+		}, scheme.ParameterCodec).
+		URL(), nil
+}
+
+func newExecutor(config *rest.Config, execURL *url.URL) (remotecommand.Executor, error) {
+	websocketExecutor, err := remotecommand.NewWebSocketExecutor(config, "GET", execURL.String())
+	if err != nil {
+		return nil, err
+	}
+	spdyExecutor, err := remotecommand.NewSPDYExecutor(config, "POST", execURL)
+	if err != nil {
+		return nil, err
+	}
+	return remotecommand.NewFallbackExecutor(websocketExecutor, spdyExecutor, func(err error) bool {
+		return httpstream.IsUpgradeFailure(err) || httpstream.IsHTTPSProxyError(err)
+	})
+}
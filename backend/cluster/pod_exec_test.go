@@ -0,0 +1,64 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+func TestGetExecURL(t *testing.T) {
+	options := PodExecOptions{
+		Namespace: "payments",
+		PodName:   "api-0",
+		Container: "app",
+		Command:   []string{"/bin/sh", "-c", "ls"},
+		TTY:       true,
+	}
+
+	execURL, err := getExecURL(&rest.Config{Host: "https://cluster.example.com"}, options, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "/api/v1/namespaces/payments/pods/api-0/exec", execURL.Path)
+
+	query := execURL.Query()
+	assert.Equal(t, "app", query.Get("container"))
+	assert.Equal(t, []string{"/bin/sh", "-c", "ls"}, query["command"])
+	assert.Equal(t, "true", query.Get("stdin"))
+	assert.Equal(t, "true", query.Get("stdout"))
+	assert.Equal(t, "true", query.Get("tty"))
+	assert.Empty(t, query.Get("stderr"))
+}
+
+func TestGetAttachURL(t *testing.T) {
+	options := PodExecOptions{
+		Subresource: PodAttachSubresource,
+		Namespace:   "payments",
+		PodName:     "api-0",
+		Container:   "app",
+		Command:     []string{"/bin/sh"},
+	}
+
+	attachURL, err := getExecURL(&rest.Config{Host: "https://cluster.example.com"}, options, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "/api/v1/namespaces/payments/pods/api-0/attach", attachURL.Path)
+
+	query := attachURL.Query()
+	assert.Equal(t, "app", query.Get("container"))
+	assert.Empty(t, query["command"])
+	assert.Equal(t, "true", query.Get("stderr"))
+	assert.Empty(t, query.Get("stdin"))
+}
+
+func TestTerminalSizeQueue(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	queue := NewTerminalSizeQueue(ctx)
+
+	queue.Push(80, 24)
+	queue.Push(120, 40)
+	assert.Equal(t, &remotecommand.TerminalSize{Width: 120, Height: 40}, queue.Next())
+
+	cancel()
+	assert.Nil(t, queue.Next())
+}
@@ -0,0 +1,24 @@
+package cluster
+
+import (
+	"context"
+	"io"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// StreamPodLogs opens the log stream of a Pod container. With options.Follow set the stream stays open
+// until the container stops or ctx is done. The caller has to close the returned reader.
+func StreamPodLogs(ctx context.Context, namespace string, podName string, options *corev1.PodLogOptions, getClientset ClientsetGetter) (io.ReadCloser, *models.ModelError) {
+	clientset, err := getClientset()
+	if err != nil {
+		return nil, err
+	}
+
+	stream, streamErr := clientset.CoreV1().Pods(namespace).GetLogs(podName, options).Stream(ctx)
+	if streamErr != nil {
+		return nil, handleKubernetesError(streamErr)
+	}
+	return stream, nil
+}
@@ -0,0 +1,47 @@
+package cluster
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestStreamPodLogs(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	getClientset := func() (kubernetes.Interface, *models.ModelError) {
+		return clientset, nil
+	}
+	tailLines := int64(10)
+	options := &corev1.PodLogOptions{Container: "app", TailLines: &tailLines, Timestamps: true}
+
+	stream, err := StreamPodLogs(context.TODO(), "default", "payments", options, getClientset)
+	assert.Nil(t, err)
+	defer stream.Close()
+	logs, readErr := io.ReadAll(stream)
+	assert.NoError(t, readErr)
+	assert.Equal(t, "fake logs", string(logs))
+
+	actions := clientset.Actions()
+	assert.Len(t, actions, 1)
+	assert.Equal(t, "log", actions[0].GetSubresource())
+	assert.Equal(t, "default", actions[0].GetNamespace())
+	assert.Equal(t, options, actions[0].(k8stesting.GenericAction).GetValue())
+}
+
+func TestStreamPodLogsClientError(t *testing.T) {
+	expectedErr := &models.ModelError{Code: 500, Message: "Failed to get client: no config"}
+	getClientset := func() (kubernetes.Interface, *models.ModelError) {
+		return nil, expectedErr
+	}
+
+	stream, err := StreamPodLogs(context.TODO(), "default", "payments", &corev1.PodLogOptions{}, getClientset)
+	assert.Nil(t, stream)
+	assert.Equal(t, expectedErr, err)
+}
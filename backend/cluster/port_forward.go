@@ -0,0 +1,215 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// PortForwardOptions selects the Pod port a tunnel connects to.
+type PortForwardOptions struct {
+	Namespace string
+	PodName   string
+	Port      int32
+}
+
+// ForwardPort passes a single connection of the client to a port of a Pod, the way kubectl port-forward
+// handles every accepted connection, until either side closes it or ctx is done.
+func ForwardPort(ctx context.Context, options PortForwardOptions, conn io.ReadWriter, getConfig ConfigGetter) *models.ModelError {
+	config, err := getConfig()
+	if err != nil {
+		return err
+	}
+
+	dialer, dialerErr := newPortForwardDialer(config, options)
+	if dialerErr != nil {
+		return &models.ModelError{Code: 500, Message: fmt.Sprintf("Failed to create dialer: %s", dialerErr)}
+	}
+	streamConn, _, dialErr := dialer.Dial(portforward.PortForwardProtocolV1Name)
+	if dialErr != nil {
+		return handleKubernetesError(dialErr)
+	}
+	defer streamConn.Close()
+
+	headers := http.Header{}
+	headers.Set(corev1.StreamType, corev1.StreamTypeError)
+	headers.Set(corev1.PortHeader, strconv.Itoa(int(options.Port)))
+	headers.Set(corev1.PortForwardRequestIDHeader, "0")
+	errorStream, streamErr := streamConn.CreateStream(headers)
+	if streamErr != nil {
+		return &models.ModelError{Code: 500, Message: fmt.Sprintf("Failed to create error stream: %s", streamErr)}
+	}
+	// Nothing is written to the error stream
+	errorStream.Close()
+
+	errorMessages := make(chan *models.ModelError, 1)
+	go func() {
+		message, readErr := io.ReadAll(errorStream)
+		switch {
+		case readErr != nil:
+			errorMessages <- &models.ModelError{Code: 500, Message: fmt.Sprintf("Failed to read error stream: %s", readErr)}
+		case len(message) > 0:
+			errorMessages <- &models.ModelError{Code: 502, Message: fmt.Sprintf("Port forwarding failed: %s", message)}
+		}
+		close(errorMessages)
+	}()
+
+	headers.Set(corev1.StreamType, corev1.StreamTypeData)
+	dataStream, streamErr := streamConn.CreateStream(headers)
+	if streamErr != nil {
+		return &models.ModelError{Code: 500, Message: fmt.Sprintf("Failed to create data stream: %s", streamErr)}
+	}
+
+	remoteDone := make(chan struct{})
+	go func() {
+		io.Copy(conn, dataStream)
+		close(remoteDone)
+	}()
+	go func() {
+		// Tells the Pod that the client has nothing more to send
+		defer dataStream.Close()
+		io.Copy(dataStream, conn)
+	}()
+
+	select {
+	case <-remoteDone:
+	case <-ctx.Done():
+		return nil
+	}
+	select {
+	case err := <-errorMessages:
+		return err
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+// ResolveServicePod finds a ready Pod backing a Service and the port of the Pod that a port of the Service
+// targets, so that a tunnel to the Service can be opened to this Pod.
+func ResolveServicePod(ctx context.Context, namespace string, serviceName string, port int32, getClientset ClientsetGetter) (string, int32, *models.ModelError) {
+	clientset, err := getClientset()
+	if err != nil {
+		return "", 0, err
+	}
+
+	service, getErr := clientset.CoreV1().Services(namespace).Get(ctx, serviceName, metav1.GetOptions{})
+	if getErr != nil {
+		return "", 0, handleKubernetesError(getErr)
+	}
+	var servicePort *corev1.ServicePort
+	for i := range service.Spec.Ports {
+		if service.Spec.Ports[i].Port == port {
+			servicePort = &service.Spec.Ports[i]
+			break
+		}
+	}
+	if servicePort == nil {
+		return "", 0, &models.ModelError{Code: 400, Message: fmt.Sprintf("Service %s has no port %d", serviceName, port)}
+	}
+	if len(service.Spec.Selector) == 0 {
+		return "", 0, &models.ModelError{Code: 400, Message: fmt.Sprintf("Service %s has no Pod selector", serviceName)}
+	}
+
+	pods, listErr := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(service.Spec.Selector).String(),
+	})
+	if listErr != nil {
+		return "", 0, handleKubernetesError(listErr)
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].Name < pods.Items[j].Name
+	})
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !isPodReady(pod) {
+			continue
+		}
+		podPort, resolveErr := getTargetPodPort(pod, *servicePort)
+		if resolveErr != nil {
+			return "", 0, resolveErr
+		}
+		return pod.Name, podPort, nil
+	}
+	return "", 0, &models.ModelError{Code: 503, Message: fmt.Sprintf("No ready Pod found for Service %s", serviceName)}
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func getTargetPodPort(pod *corev1.Pod, servicePort corev1.ServicePort) (int32, *models.ModelError) {
+	switch {
+	case servicePort.TargetPort.Type == intstr.String && servicePort.TargetPort.StrVal != "":
+		for _, container := range pod.Spec.Containers {
+			for _, containerPort := range container.Ports {
+				if containerPort.Name == servicePort.TargetPort.StrVal && containerPort.Protocol == servicePort.Protocol {
+					return containerPort.ContainerPort, nil
+				}
+			}
+		}
+		return 0, &models.ModelError{Code: 400, Message: fmt.Sprintf("Pod %s has no port named %s", pod.Name, servicePort.TargetPort.StrVal)}
+	case servicePort.TargetPort.IntVal != 0:
+		return servicePort.TargetPort.IntVal, nil
+	default:
+		return servicePort.Port, nil
+	}
+}
+
+func getPortForwardURL(config *rest.Config, options PortForwardOptions) (*url.URL, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(options.Namespace).
+		Name(options.PodName).
+		SubResource("portforward").
+		URL(), nil
+}
+
+// newPortForwardDialer tunnels the SPDY protocol over a WebSocket when the API server supports it,
+// with plain SPDY as a fallback, like newExecutor does for exec sessions.
+func newPortForwardDialer(config *rest.Config, options PortForwardOptions) (httpstream.Dialer, error) {
+	portForwardURL, err := getPortForwardURL(config, options)
+	if err != nil {
+		return nil, err
+	}
+	transport, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return nil, err
+	}
+	spdyDialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, "POST", portForwardURL)
+
+	websocketDialer, err := portforward.NewSPDYOverWebsocketDialer(portForwardURL, config)
+	if err != nil {
+		return nil, err
+	}
+	return portforward.NewFallbackDialer(websocketDialer, spdyDialer, func(err error) bool {
+		return httpstream.IsUpgradeFailure(err) || httpstream.IsHTTPSProxyError(err)
+	}), nil
+}
@@ -0,0 +1,134 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func newTestServicePod(name string, ready bool) *corev1.Pod {
+	readyStatus := corev1.ConditionFalse
+	if ready {
+		readyStatus = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "payments", Labels: map[string]string{"app": "api"}},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name:  "app",
+			Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080, Protocol: corev1.ProtocolTCP}},
+		}}},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: readyStatus}},
+		},
+	}
+}
+
+func newTestService(targetPort intstr.IntOrString) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "payments"},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": "api"},
+			Ports:    []corev1.ServicePort{{Port: 80, TargetPort: targetPort, Protocol: corev1.ProtocolTCP}},
+		},
+	}
+}
+
+func getTestClientset(clientset kubernetes.Interface) ClientsetGetter {
+	return func() (kubernetes.Interface, *models.ModelError) {
+		return clientset, nil
+	}
+}
+
+func TestResolveServicePod(t *testing.T) {
+	tests := []struct {
+		name         string
+		targetPort   intstr.IntOrString
+		port         int32
+		pods         []*corev1.Pod
+		expectedPod  string
+		expectedPort int32
+		expectedCode int32
+	}{
+		{
+			name:         "named target port on the first ready pod",
+			targetPort:   intstr.FromString("http"),
+			port:         80,
+			pods:         []*corev1.Pod{newTestServicePod("api-0", false), newTestServicePod("api-1", true)},
+			expectedPod:  "api-1",
+			expectedPort: 8080,
+		},
+		{
+			name:         "numeric target port",
+			targetPort:   intstr.FromInt32(9090),
+			port:         80,
+			pods:         []*corev1.Pod{newTestServicePod("api-0", true)},
+			expectedPod:  "api-0",
+			expectedPort: 9090,
+		},
+		{
+			name:         "target port defaults to the service port",
+			port:         80,
+			pods:         []*corev1.Pod{newTestServicePod("api-0", true)},
+			expectedPod:  "api-0",
+			expectedPort: 80,
+		},
+		{
+			name:         "unknown service port",
+			targetPort:   intstr.FromInt32(8080),
+			port:         443,
+			pods:         []*corev1.Pod{newTestServicePod("api-0", true)},
+			expectedCode: 400,
+		},
+		{
+			name:         "no ready pods",
+			targetPort:   intstr.FromInt32(8080),
+			port:         80,
+			pods:         []*corev1.Pod{newTestServicePod("api-0", false)},
+			expectedCode: 503,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(newTestService(tt.targetPort))
+			for _, pod := range tt.pods {
+				clientset.Tracker().Add(pod)
+			}
+
+			podName, podPort, err := ResolveServicePod(context.TODO(), "payments", "api", tt.port, getTestClientset(clientset))
+			if tt.expectedCode != 0 {
+				assert.NotNil(t, err)
+				assert.Equal(t, tt.expectedCode, err.Code)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expectedPod, podName)
+			assert.Equal(t, tt.expectedPort, podPort)
+		})
+	}
+}
+
+func TestResolveServicePodNotFound(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	_, _, err := ResolveServicePod(context.TODO(), "payments", "api", 80, getTestClientset(clientset))
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(404), err.Code)
+}
+
+func TestGetPortForwardURL(t *testing.T) {
+	options := PortForwardOptions{Namespace: "payments", PodName: "api-0", Port: 8080}
+
+	portForwardURL, err := getPortForwardURL(&rest.Config{Host: "https://cluster.example.com"}, options)
+	assert.NoError(t, err)
+	assert.Equal(t, "/api/v1/namespaces/payments/pods/api-0/portforward", portForwardURL.Path)
+}
@@ -0,0 +1,244 @@
+package cluster
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/common"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// coreGroupName is used in resource type names and patterns in place of the empty core API group.
+const coreGroupName = "core"
+
+// resourceTypeName is a resource type as requested by the user. Accepted forms are:
+// "Kind", "Kind.group", "version/Kind" (core group) and "group/version/Kind".
+type resourceTypeName struct {
+	Group    string
+	Version  string
+	Kind     string
+	GroupSet bool
+}
+
+// resolvedResourceType is a resource type found in the cluster discovery data.
+type resolvedResourceType struct {
+	GroupVersionResource schema.GroupVersionResource
+	Kind                 string
+	Namespaced           bool
+}
+
+func parseResourceTypeName(resourceType string) (resourceTypeName, bool) {
+	if strings.Contains(resourceType, "/") {
+		parts := strings.Split(resourceType, "/")
+		switch len(parts) {
+		case 2:
+			if parts[0] == "" || parts[1] == "" {
+				return resourceTypeName{}, false
+			}
+			return resourceTypeName{Version: parts[0], Kind: parts[1], GroupSet: true}, true
+		case 3:
+			if parts[1] == "" || parts[2] == "" {
+				return resourceTypeName{}, false
+			}
+			return resourceTypeName{Group: normalizeGroup(parts[0]), Version: parts[1], Kind: parts[2], GroupSet: true}, true
+		default:
+			return resourceTypeName{}, false
+		}
+	}
+
+	kind, group, qualified := strings.Cut(resourceType, ".")
+	if kind == "" || (qualified && group == "") {
+		return resourceTypeName{}, false
+	}
+	return resourceTypeName{Group: normalizeGroup(group), Kind: kind, GroupSet: qualified}, true
+}
+
+func normalizeGroup(group string) string {
+	if group == coreGroupName {
+		return ""
+	}
+	return group
+}
+
+func displayGroup(group string) string {
+	if group == "" {
+		return coreGroupName
+	}
+	return group
+}
+
+func findAPIResource(apiResourceLists []*metav1.APIResourceList, name resourceTypeName) (resolvedResourceType, bool) {
+	for _, apiResourceList := range apiResourceLists {
+		if apiResourceList == nil {
+			continue
+		}
+		groupVersion, err := schema.ParseGroupVersion(apiResourceList.GroupVersion)
+		if err != nil {
+			continue
+		}
+		if name.GroupSet && groupVersion.Group != name.Group {
+			continue
+		}
+		if name.Version != "" && groupVersion.Version != name.Version {
+			continue
+		}
+		for _, apiResource := range apiResourceList.APIResources {
+			// Subresources such as pods/status share the kind of their parent
+			if strings.Contains(apiResource.Name, "/") {
+				continue
+			}
+			if apiResource.Kind == name.Kind {
+				return resolvedResourceType{
+					GroupVersionResource: groupVersion.WithResource(apiResource.Name),
+					Kind:                 apiResource.Kind,
+					Namespaced:           apiResource.Namespaced,
+				}, true
+			}
+		}
+	}
+	return resolvedResourceType{}, false
+}
+
+// canonicalResourceTypeName returns the name under which a resource type is known to the role map and
+// resource list columns: a bare kind when it is the kind served by the preferred API group, and
+// "Kind.group" when another group serves a kind with the same name.
+func canonicalResourceTypeName(preferredResourceLists []*metav1.APIResourceList, group string, kind string) string {
+	preferred, found := findAPIResource(preferredResourceLists, resourceTypeName{Kind: kind})
+	if found && preferred.GroupVersionResource.Group == group {
+		return kind
+	}
+	return fmt.Sprintf("%s.%s", kind, displayGroup(group))
+}
+
+// matchesResourcePattern checks a resolved type against an allowlist pattern. A pattern is "Kind",
+// matching the kind in every group, or "Kind.group", both parts accepting path.Match wildcards,
+// e.g. "*", "Secret.core", "*.cert-manager.io".
+func matchesResourcePattern(pattern string, group string, kind string) bool {
+	kindPattern, groupPattern, qualified := strings.Cut(pattern, ".")
+	if matched, _ := path.Match(kindPattern, kind); !matched {
+		return false
+	}
+	if !qualified {
+		return true
+	}
+	matched, _ := path.Match(groupPattern, displayGroup(group))
+	return matched
+}
+
+func isResourceAllowed(group string, kind string) bool {
+	included := false
+	for _, pattern := range getIncludedResourcePatterns() {
+		if matchesResourcePattern(pattern, group, kind) {
+			included = true
+			break
+		}
+	}
+	if !included {
+		return false
+	}
+	for _, pattern := range splitResourcePatterns(common.ResourceTypesExclude) {
+		if matchesResourcePattern(pattern, group, kind) {
+			return false
+		}
+	}
+	return true
+}
+
+func getIncludedResourcePatterns() []string {
+	if patterns := splitResourcePatterns(common.ResourceTypesInclude); len(patterns) > 0 {
+		return patterns
+	}
+	patterns := make([]string, 0)
+	for resourceType := range getAllowedResourceTypes() {
+		patterns = append(patterns, resourceType)
+	}
+	return patterns
+}
+
+func splitResourcePatterns(patterns string) []string {
+	result := make([]string, 0)
+	for _, pattern := range strings.Split(patterns, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			result = append(result, pattern)
+		}
+	}
+	return result
+}
+
+// lookupAPIResource resolves types with a group through the REST mapper. A bare kind may belong to
+// any group, so it is looked up in the preferred resources of all groups instead.
+func lookupAPIResource(name resourceTypeName) (resolvedResourceType, bool, *models.ModelError) {
+	if name.GroupSet {
+		cache, err := getDiscoveryCache()
+		if err != nil {
+			return resolvedResourceType{}, false, &models.ModelError{Code: 500, Message: fmt.Sprintf("Failed to get discovery client: %s", err)}
+		}
+		resolved, found, err := cache.restMapping(name)
+		if err != nil {
+			return resolvedResourceType{}, false, handleKubernetesError(err)
+		}
+		return resolved, found, nil
+	}
+
+	apiResourceLists, err := discoverAPIResources(name)
+	if err != nil {
+		return resolvedResourceType{}, false, err
+	}
+	resolved, found := findAPIResource(apiResourceLists, name)
+	return resolved, found, nil
+}
+
+func invalidateDiscoveryCacheAfterMiss() bool {
+	cache, err := getDiscoveryCache()
+	if err != nil {
+		return false
+	}
+	return cache.invalidateAfterMiss()
+}
+
+func resolveResourceType(resourceType string) (resolvedResourceType, *models.ModelError) {
+	name, ok := parseResourceTypeName(resourceType)
+	if !ok {
+		return resolvedResourceType{}, &models.ModelError{Code: 400, Message: "Invalid Resource Type"}
+	}
+
+	resolved, found, err := lookupAPIResource(name)
+	if err != nil {
+		return resolvedResourceType{}, err
+	}
+	// The type may have been registered after the cache was filled
+	if !found && invalidateDiscoveryCacheAfterMiss() {
+		resolved, found, err = lookupAPIResource(name)
+		if err != nil {
+			return resolvedResourceType{}, err
+		}
+	}
+	if !found {
+		return resolvedResourceType{}, &models.ModelError{Code: 400, Message: "Invalid Resource Type"}
+	}
+	return resolved, nil
+}
+
+// NormalizeResourceType resolves any accepted form of a resource type to its canonical name,
+// so that e.g. "Pod", "v1/Pod" and "core/v1/Pod" are authorized as the same "Pod" resource.
+// Types rejected by the configured allowlist are reported as invalid. The allowlist only guards
+// the API, internal lookups such as reading the role map ConfigMap are not affected by it.
+func NormalizeResourceType(resourceType string) (string, *models.ModelError) {
+	resolved, err := resolveResourceType(resourceType)
+	if err != nil {
+		return "", err
+	}
+	if !isResourceAllowed(resolved.GroupVersionResource.Group, resolved.Kind) {
+		return "", &models.ModelError{Code: 400, Message: "Invalid Resource Type"}
+	}
+
+	preferredResourceLists, err := discoverAPIResources(resourceTypeName{})
+	if err != nil {
+		return "", err
+	}
+
+	return canonicalResourceTypeName(preferredResourceLists, resolved.GroupVersionResource.Group, resolved.Kind), nil
+}
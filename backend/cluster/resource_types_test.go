@@ -0,0 +1,173 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/common"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func mockAPIResourceLists() []*metav1.APIResourceList {
+	return []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "pods", Kind: "Pod", Namespaced: true},
+				{Name: "pods/status", Kind: "Pod", Namespaced: true},
+				{Name: "nodes", Kind: "Node", Namespaced: false},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Kind: "Deployment", Namespaced: true},
+				{Name: "deployments/scale", Kind: "Scale", Namespaced: true},
+			},
+		},
+		{
+			GroupVersion: "cert-manager.io/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "certificates", Kind: "Certificate", Namespaced: true},
+			},
+		},
+		{
+			GroupVersion: "example.com/v1alpha1",
+			APIResources: []metav1.APIResource{
+				{Name: "certificates", Kind: "Certificate", Namespaced: false},
+				{Name: "deployments", Kind: "Deployment", Namespaced: true},
+			},
+		},
+	}
+}
+
+func TestParseResourceTypeName(t *testing.T) {
+	tests := []struct {
+		name          string
+		resourceType  string
+		expected      resourceTypeName
+		expectedValid bool
+	}{
+		{"Kind", "Pod", resourceTypeName{Kind: "Pod"}, true},
+		{"Kind with group", "Certificate.cert-manager.io", resourceTypeName{Group: "cert-manager.io", Kind: "Certificate", GroupSet: true}, true},
+		{"Kind with core group", "Pod.core", resourceTypeName{Group: "", Kind: "Pod", GroupSet: true}, true},
+		{"Version and kind", "v1/Pod", resourceTypeName{Version: "v1", Kind: "Pod", GroupSet: true}, true},
+		{"Group, version and kind", "apps/v1/Deployment", resourceTypeName{Group: "apps", Version: "v1", Kind: "Deployment", GroupSet: true}, true},
+		{"Core group, version and kind", "core/v1/Pod", resourceTypeName{Group: "", Version: "v1", Kind: "Pod", GroupSet: true}, true},
+		{"Empty", "", resourceTypeName{}, false},
+		{"Empty group", "Pod.", resourceTypeName{}, false},
+		{"Missing kind", "apps/v1/", resourceTypeName{}, false},
+		{"Too many parts", "a/b/c/d", resourceTypeName{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, valid := parseResourceTypeName(tt.resourceType)
+			assert.Equal(t, tt.expectedValid, valid)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestFindAPIResource(t *testing.T) {
+	tests := []struct {
+		name          string
+		resourceType  resourceTypeName
+		expected      resolvedResourceType
+		expectedFound bool
+	}{
+		{
+			name:          "Kind from the first group serving it",
+			resourceType:  resourceTypeName{Kind: "Certificate"},
+			expected:      resolvedResourceType{GroupVersionResource: schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}, Kind: "Certificate", Namespaced: true},
+			expectedFound: true,
+		},
+		{
+			name:          "Kind from the requested group",
+			resourceType:  resourceTypeName{Group: "example.com", Kind: "Certificate", GroupSet: true},
+			expected:      resolvedResourceType{GroupVersionResource: schema.GroupVersionResource{Group: "example.com", Version: "v1alpha1", Resource: "certificates"}, Kind: "Certificate", Namespaced: false},
+			expectedFound: true,
+		},
+		{
+			name:          "Core group does not match other groups",
+			resourceType:  resourceTypeName{Version: "v1", Kind: "Deployment", GroupSet: true},
+			expectedFound: false,
+		},
+		{
+			name:          "Subresources are skipped",
+			resourceType:  resourceTypeName{Kind: "Scale"},
+			expectedFound: false,
+		},
+		{
+			name:          "Parent of a subresource",
+			resourceType:  resourceTypeName{Kind: "Pod"},
+			expected:      resolvedResourceType{GroupVersionResource: schema.GroupVersionResource{Version: "v1", Resource: "pods"}, Kind: "Pod", Namespaced: true},
+			expectedFound: true,
+		},
+		{
+			name:          "Wrong version",
+			resourceType:  resourceTypeName{Group: "apps", Version: "v1beta1", Kind: "Deployment", GroupSet: true},
+			expectedFound: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, found := findAPIResource(mockAPIResourceLists(), tt.resourceType)
+			assert.Equal(t, tt.expectedFound, found)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestCanonicalResourceTypeName(t *testing.T) {
+	lists := mockAPIResourceLists()
+
+	assert.Equal(t, "Pod", canonicalResourceTypeName(lists, "", "Pod"))
+	assert.Equal(t, "Deployment", canonicalResourceTypeName(lists, "apps", "Deployment"))
+	assert.Equal(t, "Deployment.example.com", canonicalResourceTypeName(lists, "example.com", "Deployment"))
+	assert.Equal(t, "Certificate", canonicalResourceTypeName(lists, "cert-manager.io", "Certificate"))
+	assert.Equal(t, "Certificate.example.com", canonicalResourceTypeName(lists, "example.com", "Certificate"))
+}
+
+func TestMatchesResourcePattern(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		group    string
+		kind     string
+		expected bool
+	}{
+		{"Pod", "", "Pod", true},
+		{"Pod", "example.com", "Pod", true},
+		{"Pod.core", "", "Pod", true},
+		{"Pod.core", "example.com", "Pod", false},
+		{"*", "cert-manager.io", "Certificate", true},
+		{"*.cert-manager.io", "cert-manager.io", "Certificate", true},
+		{"*.cert-manager.io", "acme.cert-manager.io", "Order", false},
+		{"*.*cert-manager.io", "acme.cert-manager.io", "Order", true},
+		{"Cert*", "cert-manager.io", "Certificate", true},
+		{"Secret", "", "ConfigMap", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.kind+"."+tt.group, func(t *testing.T) {
+			assert.Equal(t, tt.expected, matchesResourcePattern(tt.pattern, tt.group, tt.kind))
+		})
+	}
+}
+
+func TestIsResourceAllowedWithConfiguredPatterns(t *testing.T) {
+	defer func() {
+		common.ResourceTypesInclude = ""
+		common.ResourceTypesExclude = ""
+	}()
+	common.ResourceTypesInclude = "Pod, *.cert-manager.io, *.argoproj.io"
+	common.ResourceTypesExclude = "Certificate.cert-manager.io"
+
+	assert.True(t, isResourceAllowed("", "Pod"))
+	assert.True(t, isResourceAllowed("argoproj.io", "Rollout"))
+	assert.True(t, isResourceAllowed("cert-manager.io", "Issuer"))
+	assert.False(t, isResourceAllowed("cert-manager.io", "Certificate"))
+	assert.False(t, isResourceAllowed("", "Secret"))
+}
@@ -0,0 +1,329 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	podMetricsType  = "metrics.k8s.io/v1beta1/PodMetrics"
+	nodeMetricsType = "metrics.k8s.io/v1beta1/NodeMetrics"
+)
+
+// usageMetricsTypes maps the resource types with usage to their metrics.k8s.io types.
+var usageMetricsTypes = map[string]string{
+	podString:  podMetricsType,
+	nodeString: nodeMetricsType,
+}
+
+// podMetrics and nodeMetrics hold the fields read from metrics.k8s.io, whose client is not a dependency.
+type podMetrics struct {
+	Timestamp  metav1.Time     `json:"timestamp"`
+	Window     metav1.Duration `json:"window"`
+	Containers []struct {
+		Name  string              `json:"name"`
+		Usage corev1.ResourceList `json:"usage"`
+	} `json:"containers"`
+}
+
+type nodeMetrics struct {
+	Timestamp metav1.Time         `json:"timestamp"`
+	Window    metav1.Duration     `json:"window"`
+	Usage     corev1.ResourceList `json:"usage"`
+}
+
+// usageReferences are the values usage percentages are computed against, missing ones are left out.
+type usageReferences struct {
+	requests    corev1.ResourceList
+	limits      corev1.ResourceList
+	allocatable corev1.ResourceList
+}
+
+// GetResourceUsage returns the CPU and memory usage of a Pod, with the usage of its containers, or of a Node.
+// Metrics are read with getMetricsInterface, which must not be served from the informer cache, as metrics.k8s.io
+// cannot be watched. When metrics are not available, e.g. without metrics-server, the usage is returned with
+// Available set to false and the reason in Message.
+func GetResourceUsage(ctx context.Context, resourceType string, namespace string, resourceName string, getResourceInterface ResourceInterfaceGetter, getMetricsInterface ResourceInterfaceGetter) (models.ResourceUsage, *models.ModelError) {
+	metricsType, found := usageMetricsTypes[resourceType]
+	if !found {
+		return models.ResourceUsage{}, &models.ModelError{Code: 400, Message: fmt.Sprintf("Resource type %s has no usage metrics", resourceType)}
+	}
+	resourceInterface, err := getResourceInterface(resourceType, namespace, DefaultNamespace)
+	if err != nil {
+		return models.ResourceUsage{}, err
+	}
+	object, getErr := resourceInterface.Get(ctx, resourceName, metav1.GetOptions{})
+	if getErr != nil {
+		return models.ResourceUsage{}, handleKubernetesError(getErr)
+	}
+	usage := models.ResourceUsage{Kind: resourceType, Name: object.GetName(), Namespace: object.GetNamespace()}
+
+	metricsInterface, err := getMetricsInterface(metricsType, namespace, DefaultNamespace)
+	if err != nil {
+		usage.Message = getUsageUnavailableMessage(err)
+		return usage, nil
+	}
+	metrics, getErr := metricsInterface.Get(ctx, resourceName, metav1.GetOptions{})
+	if errors.IsNotFound(getErr) {
+		usage.Message = fmt.Sprintf("No usage reported for %s %s yet", resourceType, resourceName)
+		return usage, nil
+	}
+	if getErr != nil {
+		usage.Message = getUsageUnavailableMessage(handleKubernetesError(getErr))
+		return usage, nil
+	}
+
+	if err := fillResourceUsage(resourceType, &usage, object, metrics); err != nil {
+		return models.ResourceUsage{}, err
+	}
+	usage.Available = true
+	return usage, nil
+}
+
+// addResourceUsage fills the usage columns of the Pod or Node rows listed from items, in the same order. Rows of
+// objects without metrics stay empty. It returns a warning when no metrics could be read, so that the list is
+// still served without metrics-server. Metrics are listed for the whole namespace, so a request listing several
+// pages should read them once with a getter returned by NewListOnceResourceInterfaceGetter.
+func addResourceUsage(ctx context.Context, resourceType string, namespace string, items []unstructured.Unstructured, rows []models.ResourceListResourceList, getMetricsInterface ResourceInterfaceGetter) string {
+	metricsType, found := usageMetricsTypes[resourceType]
+	if !found || getMetricsInterface == nil || len(items) == 0 {
+		return ""
+	}
+	metricsInterface, err := getMetricsInterface(metricsType, namespace, emptyNamespace)
+	if err != nil {
+		return getUsageUnavailableMessage(err)
+	}
+	metricsList, listErr := metricsInterface.List(ctx, metav1.ListOptions{})
+	if listErr != nil {
+		return getUsageUnavailableMessage(handleKubernetesError(listErr))
+	}
+	metricsByName := make(map[types.NamespacedName]*unstructured.Unstructured, len(metricsList.Items))
+	for i := range metricsList.Items {
+		metrics := &metricsList.Items[i]
+		metricsByName[types.NamespacedName{Namespace: metrics.GetNamespace(), Name: metrics.GetName()}] = metrics
+	}
+
+	for i := range items {
+		metrics, found := metricsByName[types.NamespacedName{Namespace: items[i].GetNamespace(), Name: items[i].GetName()}]
+		if !found {
+			continue
+		}
+		usage := models.ResourceUsage{}
+		if err := fillResourceUsage(resourceType, &usage, &items[i], metrics); err != nil {
+			continue
+		}
+		rows[i].Cpu, rows[i].CpuPercent = getUsageColumns(usage.Cpu)
+		rows[i].Memory, rows[i].MemoryPercent = getUsageColumns(usage.Memory)
+	}
+	return ""
+}
+
+// NewListOnceResourceInterfaceGetter returns a getter whose resource interfaces answer List with the result of
+// the first List of the same type, namespace and options. It is meant for the lifetime of one request.
+func NewListOnceResourceInterfaceGetter(getResourceInterface ResourceInterfaceGetter) ResourceInterfaceGetter {
+	lists := &listOnceResults{results: map[string]*listOnceResult{}}
+	return func(resourceType string, namespace string, defaultNamespace string) (dynamic.ResourceInterface, *models.ModelError) {
+		resourceInterface, err := getResourceInterface(resourceType, namespace, defaultNamespace)
+		if err != nil {
+			return nil, err
+		}
+		return &listOnceResourceInterface{ResourceInterface: resourceInterface, lists: lists, key: resourceType + "/" + namespace}, nil
+	}
+}
+
+type listOnceResult struct {
+	list *unstructured.UnstructuredList
+	err  error
+}
+
+type listOnceResults struct {
+	mutex   sync.Mutex
+	results map[string]*listOnceResult
+}
+
+// listOnceResourceInterface overrides List of the resource interface it embeds.
+type listOnceResourceInterface struct {
+	dynamic.ResourceInterface
+	lists *listOnceResults
+	key   string
+}
+
+func (r *listOnceResourceInterface) List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	key := r.key + "?" + opts.String()
+	r.lists.mutex.Lock()
+	defer r.lists.mutex.Unlock()
+	if result, found := r.lists.results[key]; found {
+		return result.list, result.err
+	}
+	list, err := r.ResourceInterface.List(ctx, opts)
+	r.lists.results[key] = &listOnceResult{list: list, err: err}
+	return list, err
+}
+
+// getUsageColumns returns the usage and its percentage of the request of a Pod or of the allocatable of a Node.
+func getUsageColumns(value *models.ResourceUsageValue) (string, string) {
+	if value == nil {
+		return "", ""
+	}
+	percent := value.RequestPercent
+	if percent == nil {
+		percent = value.AllocatablePercent
+	}
+	if percent == nil {
+		return value.Usage, ""
+	}
+	return value.Usage, strconv.FormatInt(*percent, 10)
+}
+
+func getUsageUnavailableMessage(err *models.ModelError) string {
+	// Types missing from discovery are reported as invalid, here it means no metrics API is installed
+	if err.Code == 400 {
+		return "Resource usage is unavailable, metrics.k8s.io is not served by the cluster"
+	}
+	return fmt.Sprintf("Resource usage is unavailable: %s", err.Message)
+}
+
+func fillResourceUsage(resourceType string, usage *models.ResourceUsage, object *unstructured.Unstructured, metrics *unstructured.Unstructured) *models.ModelError {
+	if resourceType == podString {
+		return fillPodUsage(usage, object, metrics)
+	}
+	return fillNodeUsage(usage, object, metrics)
+}
+
+// fillPodUsage sums the usage of the containers of a Pod. Percentages of the Pod are only given when all its
+// containers set the request or the limit, like the HorizontalPodAutoscaler requires.
+func fillPodUsage(usage *models.ResourceUsage, object *unstructured.Unstructured, metricsObject *unstructured.Unstructured) *models.ModelError {
+	var pod corev1.Pod
+	var metrics podMetrics
+	if err := fromUnstructured(object, &pod); err != nil {
+		return err
+	}
+	if err := fromUnstructured(metricsObject, &metrics); err != nil {
+		return err
+	}
+
+	containers := make(map[string]corev1.Container, len(pod.Spec.Containers))
+	for _, container := range pod.Spec.Containers {
+		containers[container.Name] = container
+	}
+	total := corev1.ResourceList{}
+	podReferences := usageReferences{requests: corev1.ResourceList{}, limits: corev1.ResourceList{}}
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		total[name] = resource.Quantity{}
+		podReferences.requests[name] = resource.Quantity{}
+		podReferences.limits[name] = resource.Quantity{}
+	}
+	for _, container := range pod.Spec.Containers {
+		addToReferences(podReferences.requests, container.Resources.Requests)
+		addToReferences(podReferences.limits, container.Resources.Limits)
+	}
+
+	for _, containerMetrics := range metrics.Containers {
+		container := containers[containerMetrics.Name]
+		references := usageReferences{requests: container.Resources.Requests, limits: container.Resources.Limits}
+		usage.Containers = append(usage.Containers, models.ContainerUsage{
+			Name:   containerMetrics.Name,
+			Cpu:    getUsageValue(corev1.ResourceCPU, containerMetrics.Usage, references),
+			Memory: getUsageValue(corev1.ResourceMemory, containerMetrics.Usage, references),
+		})
+		for name, quantity := range total {
+			quantity.Add(containerMetrics.Usage[name])
+			total[name] = quantity
+		}
+	}
+
+	cpu := getUsageValue(corev1.ResourceCPU, total, podReferences)
+	memory := getUsageValue(corev1.ResourceMemory, total, podReferences)
+	usage.Cpu, usage.Memory = &cpu, &memory
+	usage.Timestamp = metrics.Timestamp.UTC().Format(time.RFC3339)
+	usage.Window = metrics.Window.Duration.String()
+	return nil
+}
+
+// addToReferences adds the values of a container to the sums of a Pod, removing a sum once a container lacks the value.
+func addToReferences(sums corev1.ResourceList, values corev1.ResourceList) {
+	for name, sum := range sums {
+		value, found := values[name]
+		if !found {
+			delete(sums, name)
+			continue
+		}
+		sum.Add(value)
+		sums[name] = sum
+	}
+}
+
+func fillNodeUsage(usage *models.ResourceUsage, object *unstructured.Unstructured, metricsObject *unstructured.Unstructured) *models.ModelError {
+	var node corev1.Node
+	var metrics nodeMetrics
+	if err := fromUnstructured(object, &node); err != nil {
+		return err
+	}
+	if err := fromUnstructured(metricsObject, &metrics); err != nil {
+		return err
+	}
+
+	references := usageReferences{allocatable: node.Status.Allocatable}
+	cpu := getUsageValue(corev1.ResourceCPU, metrics.Usage, references)
+	memory := getUsageValue(corev1.ResourceMemory, metrics.Usage, references)
+	usage.Cpu, usage.Memory = &cpu, &memory
+	usage.Timestamp = metrics.Timestamp.UTC().Format(time.RFC3339)
+	usage.Window = metrics.Window.Duration.String()
+	return nil
+}
+
+func getUsageValue(name corev1.ResourceName, usage corev1.ResourceList, references usageReferences) models.ResourceUsageValue {
+	quantity := usage[name]
+	value := models.ResourceUsageValue{Usage: formatUsageQuantity(name, quantity)}
+	if request, found := references.requests[name]; found && !request.IsZero() {
+		value.Request = formatUsageQuantity(name, request)
+		value.RequestPercent = getUsagePercent(name, quantity, request)
+	}
+	if limit, found := references.limits[name]; found && !limit.IsZero() {
+		value.Limit = formatUsageQuantity(name, limit)
+		value.LimitPercent = getUsagePercent(name, quantity, limit)
+	}
+	if allocatable, found := references.allocatable[name]; found && !allocatable.IsZero() {
+		value.Allocatable = formatUsageQuantity(name, allocatable)
+		value.AllocatablePercent = getUsagePercent(name, quantity, allocatable)
+	}
+	return value
+}
+
+// formatUsageQuantity uses the units of kubectl top: millicores for CPU and mebibytes for memory.
+func formatUsageQuantity(name corev1.ResourceName, quantity resource.Quantity) string {
+	if name == corev1.ResourceCPU {
+		return fmt.Sprintf("%dm", quantity.MilliValue())
+	}
+	return fmt.Sprintf("%dMi", quantity.Value()/(1024*1024))
+}
+
+func getUsagePercent(name corev1.ResourceName, usage resource.Quantity, reference resource.Quantity) *int64 {
+	var percent int64
+	if name == corev1.ResourceCPU {
+		percent = usage.MilliValue() * 100 / reference.MilliValue()
+	} else {
+		percent = usage.Value() * 100 / reference.Value()
+	}
+	return &percent
+}
+
+func fromUnstructured(object *unstructured.Unstructured, into interface{}) *models.ModelError {
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, into); err != nil {
+		return &models.ModelError{Code: 500, Message: fmt.Sprintf("Failed to read %s %s: %s", object.GetKind(), object.GetName(), err)}
+	}
+	return nil
+}
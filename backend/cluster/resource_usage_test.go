@@ -0,0 +1,280 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+)
+
+var testUsageResources = map[string]schema.GroupVersionResource{
+	podString:       podGVR,
+	nodeString:      {Version: "v1", Resource: "nodes"},
+	podMetricsType:  {Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"},
+	nodeMetricsType: {Group: "metrics.k8s.io", Version: "v1beta1", Resource: "nodes"},
+}
+
+func newTestUsagePod(containers ...corev1.Container) *unstructured.Unstructured {
+	pod := &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: podString},
+		ObjectMeta: metav1.ObjectMeta{Name: "api-0", Namespace: "payments"},
+		Spec:       corev1.PodSpec{Containers: containers},
+	}
+	object, _ := runtime.DefaultUnstructuredConverter.ToUnstructured(pod)
+	return &unstructured.Unstructured{Object: object}
+}
+
+func newTestUsageContainer(name string, requests corev1.ResourceList, limits corev1.ResourceList) corev1.Container {
+	return corev1.Container{Name: name, Resources: corev1.ResourceRequirements{Requests: requests, Limits: limits}}
+}
+
+func newTestPodMetrics(containerUsage map[string][2]string) *unstructured.Unstructured {
+	containers := make([]interface{}, 0, len(containerUsage))
+	for name, usage := range containerUsage {
+		containers = append(containers, map[string]interface{}{
+			"name":  name,
+			"usage": map[string]interface{}{"cpu": usage[0], "memory": usage[1]},
+		})
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "metrics.k8s.io/v1beta1",
+		"kind":       "PodMetrics",
+		"metadata":   map[string]interface{}{"name": "api-0", "namespace": "payments"},
+		"timestamp":  "2024-11-05T10:00:00Z",
+		"window":     "15s",
+		"containers": containers,
+	}}
+}
+
+func newTestNodeWithAllocatable() *unstructured.Unstructured {
+	node := &corev1.Node{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: nodeString},
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-1"},
+		Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("4"),
+			corev1.ResourceMemory: resource.MustParse("8Gi"),
+		}},
+	}
+	object, _ := runtime.DefaultUnstructuredConverter.ToUnstructured(node)
+	return &unstructured.Unstructured{Object: object}
+}
+
+func newTestNodeMetrics() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "metrics.k8s.io/v1beta1",
+		"kind":       "NodeMetrics",
+		"metadata":   map[string]interface{}{"name": "worker-1"},
+		"timestamp":  "2024-11-05T10:00:00Z",
+		"window":     "20s",
+		"usage":      map[string]interface{}{"cpu": "1000000000n", "memory": "2097152Ki"},
+	}}
+}
+
+// getTestUsageResourceInterface serves the objects from a fake dynamic client, metrics types are served only when withMetrics is set.
+func getTestUsageResourceInterface(withMetrics bool, objects ...*unstructured.Unstructured) ResourceInterfaceGetter {
+	listKinds := map[schema.GroupVersionResource]string{
+		testUsageResources[podString]:       "PodList",
+		testUsageResources[nodeString]:      "NodeList",
+		testUsageResources[podMetricsType]:  "PodMetricsList",
+		testUsageResources[nodeMetricsType]: "NodeMetricsList",
+	}
+	client := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+	for _, object := range objects {
+		// Created by resource, as the fake client would guess podmetricses from the PodMetrics kind
+		gvr := testUsageResources[object.GetKind()]
+		if object.GetAPIVersion() == "metrics.k8s.io/v1beta1" {
+			gvr = testUsageResources[object.GetAPIVersion()+"/"+object.GetKind()]
+		}
+		_, _ = client.Resource(gvr).Namespace(object.GetNamespace()).Create(context.TODO(), object, metav1.CreateOptions{})
+	}
+	return func(resourceType string, namespace string, defaultNamespace string) (dynamic.ResourceInterface, *models.ModelError) {
+		if !withMetrics && (resourceType == podMetricsType || resourceType == nodeMetricsType) {
+			return nil, &models.ModelError{Code: 400, Message: "Invalid Resource Type"}
+		}
+		gvr := testUsageResources[resourceType]
+		if resourceType == nodeString || resourceType == nodeMetricsType {
+			return client.Resource(gvr), nil
+		}
+		if namespace == "" {
+			namespace = defaultNamespace
+		}
+		return client.Resource(gvr).Namespace(namespace), nil
+	}
+}
+
+func TestGetPodUsage(t *testing.T) {
+	pod := newTestUsagePod(
+		newTestUsageContainer("api", corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("200m"),
+			corev1.ResourceMemory: resource.MustParse("256Mi"),
+		}, corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")}),
+		newTestUsageContainer("proxy", corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("100m"),
+			corev1.ResourceMemory: resource.MustParse("64Mi"),
+		}, nil),
+	)
+	metrics := newTestPodMetrics(map[string][2]string{"api": {"150m", "128Mi"}, "proxy": {"30m", "32Mi"}})
+	getResourceInterface := getTestUsageResourceInterface(true, pod, metrics)
+
+	usage, err := GetResourceUsage(context.TODO(), podString, "payments", "api-0", getResourceInterface, getResourceInterface)
+
+	assert.Nil(t, err)
+	assert.True(t, usage.Available)
+	assert.Equal(t, "2024-11-05T10:00:00Z", usage.Timestamp)
+	assert.Equal(t, "15s", usage.Window)
+	assert.Equal(t, "180m", usage.Cpu.Usage)
+	assert.Equal(t, "300m", usage.Cpu.Request)
+	assert.Equal(t, int64(60), *usage.Cpu.RequestPercent)
+	assert.Equal(t, "160Mi", usage.Memory.Usage)
+	assert.Equal(t, int64(50), *usage.Memory.RequestPercent)
+	// The proxy sets no memory limit, so the Pod has none
+	assert.Nil(t, usage.Memory.LimitPercent)
+	assert.Len(t, usage.Containers, 2)
+	for _, container := range usage.Containers {
+		if container.Name == "api" {
+			assert.Equal(t, int64(25), *container.Memory.LimitPercent)
+			assert.Equal(t, int64(75), *container.Cpu.RequestPercent)
+		}
+	}
+}
+
+func TestGetNodeUsage(t *testing.T) {
+	getResourceInterface := getTestUsageResourceInterface(true, newTestNodeWithAllocatable(), newTestNodeMetrics())
+
+	usage, err := GetResourceUsage(context.TODO(), nodeString, "", "worker-1", getResourceInterface, getResourceInterface)
+
+	assert.Nil(t, err)
+	assert.True(t, usage.Available)
+	assert.Equal(t, &models.ResourceUsageValue{Usage: "1000m", Allocatable: "4000m", AllocatablePercent: usage.Cpu.AllocatablePercent}, usage.Cpu)
+	assert.Equal(t, int64(25), *usage.Cpu.AllocatablePercent)
+	assert.Equal(t, "2048Mi", usage.Memory.Usage)
+	assert.Equal(t, int64(25), *usage.Memory.AllocatablePercent)
+	assert.Empty(t, usage.Containers)
+}
+
+func TestGetResourceUsageWithoutMetricsAPI(t *testing.T) {
+	getResourceInterface := getTestUsageResourceInterface(false, newTestNodeWithAllocatable())
+
+	usage, err := GetResourceUsage(context.TODO(), nodeString, "", "worker-1", getResourceInterface, getResourceInterface)
+
+	assert.Nil(t, err)
+	assert.False(t, usage.Available)
+	assert.Contains(t, usage.Message, "metrics.k8s.io is not served")
+	assert.Nil(t, usage.Cpu)
+}
+
+func TestGetResourceUsageNotReportedYet(t *testing.T) {
+	getResourceInterface := getTestUsageResourceInterface(true, newTestNodeWithAllocatable())
+
+	usage, err := GetResourceUsage(context.TODO(), nodeString, "", "worker-1", getResourceInterface, getResourceInterface)
+
+	assert.Nil(t, err)
+	assert.False(t, usage.Available)
+	assert.Equal(t, "No usage reported for Node worker-1 yet", usage.Message)
+}
+
+func TestGetResourceUsageUnsupportedType(t *testing.T) {
+	getResourceInterface := getTestUsageResourceInterface(true)
+
+	_, err := GetResourceUsage(context.TODO(), deploymentString, "payments", "api", getResourceInterface, getResourceInterface)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(400), err.Code)
+}
+
+func TestGetResourceUsageObjectNotFound(t *testing.T) {
+	getResourceInterface := getTestUsageResourceInterface(true, newTestNodeMetrics())
+
+	_, err := GetResourceUsage(context.TODO(), nodeString, "", "worker-1", getResourceInterface, getResourceInterface)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(404), err.Code)
+}
+
+func TestListResourcesWithUsage(t *testing.T) {
+	pod := newTestUsagePod(newTestUsageContainer("api", corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m")}, nil))
+	otherPod := newTestUsagePod()
+	otherPod.SetName("worker-0")
+	metrics := newTestPodMetrics(map[string][2]string{"api": {"50m", "100Mi"}})
+	getResourceInterface := getTestUsageResourceInterface(true, pod, otherPod, metrics)
+
+	result, err := ListResources(context.TODO(), podString, "payments", metav1.ListOptions{}, getResourceInterface, getResourceInterface)
+
+	assert.Nil(t, err)
+	assert.Empty(t, result.Warnings)
+	assert.Contains(t, result.Columns, cpuStr)
+	rows := map[string]models.ResourceListResourceList{}
+	for _, row := range result.ResourceList {
+		rows[row.Name] = row
+	}
+	assert.Equal(t, "50m", rows["api-0"].Cpu)
+	assert.Equal(t, "25", rows["api-0"].CpuPercent)
+	assert.Equal(t, "100Mi", rows["api-0"].Memory)
+	// No memory request, so no percentage
+	assert.Empty(t, rows["api-0"].MemoryPercent)
+	assert.Empty(t, rows["worker-0"].Cpu)
+}
+
+func TestListResourcesWithoutMetricsAPI(t *testing.T) {
+	getResourceInterface := getTestUsageResourceInterface(false, newTestNodeWithAllocatable())
+
+	result, err := ListResources(context.TODO(), nodeString, "", metav1.ListOptions{}, getResourceInterface, getResourceInterface)
+
+	assert.Nil(t, err)
+	assert.Len(t, result.ResourceList, 1)
+	assert.Empty(t, result.ResourceList[0].Cpu)
+	assert.Equal(t, []string{"Resource usage is unavailable, metrics.k8s.io is not served by the cluster"}, result.Warnings)
+}
+
+func TestListResourcesNodeUsage(t *testing.T) {
+	getResourceInterface := getTestUsageResourceInterface(true, newTestNodeWithAllocatable(), newTestNodeMetrics())
+
+	result, err := ListResources(context.TODO(), nodeString, "", metav1.ListOptions{}, getResourceInterface, getResourceInterface)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "1000m", result.ResourceList[0].Cpu)
+	assert.Equal(t, "25", result.ResourceList[0].CpuPercent)
+	assert.Equal(t, "2048Mi", result.ResourceList[0].Memory)
+	assert.Equal(t, "25", result.ResourceList[0].MemoryPercent)
+}
+
+type countingListResourceInterface struct {
+	dynamic.ResourceInterface
+	lists *int
+}
+
+func (r *countingListResourceInterface) List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	*r.lists++
+	return r.ResourceInterface.List(ctx, opts)
+}
+
+func TestListResourcesPagesShareMetrics(t *testing.T) {
+	pod := newTestUsagePod(newTestUsageContainer("api", nil, nil))
+	metrics := newTestPodMetrics(map[string][2]string{"api": {"50m", "100Mi"}})
+	getResourceInterface := getTestUsageResourceInterface(true, pod, metrics)
+	metricsLists := 0
+	getMetricsInterface := NewListOnceResourceInterfaceGetter(func(resourceType string, namespace string, defaultNamespace string) (dynamic.ResourceInterface, *models.ModelError) {
+		resourceInterface, err := getResourceInterface(resourceType, namespace, defaultNamespace)
+		if err != nil {
+			return nil, err
+		}
+		return &countingListResourceInterface{ResourceInterface: resourceInterface, lists: &metricsLists}, nil
+	})
+
+	for page := 0; page < 2; page++ {
+		result, err := ListResources(context.TODO(), podString, "payments", metav1.ListOptions{}, getResourceInterface, getMetricsInterface)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "50m", result.ResourceList[0].Cpu)
+	}
+	assert.Equal(t, 1, metricsLists)
+}
@@ -0,0 +1,174 @@
+package cluster
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	clientappsv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+)
+
+// unknownRevisionMessages are returned by the rollbackers of kubectl when the revision is not in the history.
+var unknownRevisionMessages = []string{
+	"unable to find specified revision",
+	"no last revision to roll back to",
+	"no rollout history found",
+}
+
+// pausedRollbackMessage is returned by the Deployment rollbacker of kubectl for a paused Deployment.
+const pausedRollbackMessage = "cannot rollback a paused deployment"
+
+// getRollbackError translates an error of a rollbacker. The rollbackers only keep the text of the errors
+// returned by the API server, so the status recorded by the client is translated instead when there is one.
+func getRollbackError(err error, apiErr error) *models.ModelError {
+	if apiErr != nil {
+		if modelErr := FromKubernetesError(apiErr); modelErr != nil {
+			return modelErr
+		}
+	}
+	if modelErr := FromKubernetesError(err); modelErr != nil {
+		return modelErr
+	}
+	message := err.Error()
+	for _, unknownRevision := range unknownRevisionMessages {
+		if strings.Contains(message, unknownRevision) {
+			return &models.ModelError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Unknown revision: %s", err)}
+		}
+	}
+	if strings.Contains(message, pausedRollbackMessage) {
+		return &models.ModelError{Code: http.StatusConflict, Message: fmt.Sprintf("Conflict: %s", err)}
+	}
+	return &models.ModelError{Code: http.StatusInternalServerError, Message: fmt.Sprintf("Rollback failed: %s", err)}
+}
+
+// statusRecordingClientset records the last error of the calls the rollbackers make, before they wrap it.
+type statusRecordingClientset struct {
+	kubernetes.Interface
+	err *error
+}
+
+func newStatusRecordingClientset(clientset kubernetes.Interface) *statusRecordingClientset {
+	return &statusRecordingClientset{Interface: clientset, err: new(error)}
+}
+
+func (c *statusRecordingClientset) lastError() error {
+	return *c.err
+}
+
+func (c *statusRecordingClientset) AppsV1() clientappsv1.AppsV1Interface {
+	return &statusRecordingAppsV1{AppsV1Interface: c.Interface.AppsV1(), err: c.err}
+}
+
+type statusRecordingAppsV1 struct {
+	clientappsv1.AppsV1Interface
+	err *error
+}
+
+func (a *statusRecordingAppsV1) Deployments(namespace string) clientappsv1.DeploymentInterface {
+	return &statusRecordingDeployments{DeploymentInterface: a.AppsV1Interface.Deployments(namespace), err: a.err}
+}
+
+func (a *statusRecordingAppsV1) StatefulSets(namespace string) clientappsv1.StatefulSetInterface {
+	return &statusRecordingStatefulSets{StatefulSetInterface: a.AppsV1Interface.StatefulSets(namespace), err: a.err}
+}
+
+func (a *statusRecordingAppsV1) DaemonSets(namespace string) clientappsv1.DaemonSetInterface {
+	return &statusRecordingDaemonSets{DaemonSetInterface: a.AppsV1Interface.DaemonSets(namespace), err: a.err}
+}
+
+func (a *statusRecordingAppsV1) ReplicaSets(namespace string) clientappsv1.ReplicaSetInterface {
+	return &statusRecordingReplicaSets{ReplicaSetInterface: a.AppsV1Interface.ReplicaSets(namespace), err: a.err}
+}
+
+func (a *statusRecordingAppsV1) ControllerRevisions(namespace string) clientappsv1.ControllerRevisionInterface {
+	return &statusRecordingControllerRevisions{ControllerRevisionInterface: a.AppsV1Interface.ControllerRevisions(namespace), err: a.err}
+}
+
+// recordStatusError keeps errors returned by the API server, which the rollbackers would wrap.
+func recordStatusError(target *error, err error) {
+	var apiStatus errors.APIStatus
+	if stderrors.As(err, &apiStatus) {
+		*target = err
+	}
+}
+
+type statusRecordingDeployments struct {
+	clientappsv1.DeploymentInterface
+	err *error
+}
+
+func (d *statusRecordingDeployments) Get(ctx context.Context, name string, opts metav1.GetOptions) (*appsv1.Deployment, error) {
+	deployment, err := d.DeploymentInterface.Get(ctx, name, opts)
+	recordStatusError(d.err, err)
+	return deployment, err
+}
+
+func (d *statusRecordingDeployments) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*appsv1.Deployment, error) {
+	deployment, err := d.DeploymentInterface.Patch(ctx, name, pt, data, opts, subresources...)
+	recordStatusError(d.err, err)
+	return deployment, err
+}
+
+type statusRecordingStatefulSets struct {
+	clientappsv1.StatefulSetInterface
+	err *error
+}
+
+func (s *statusRecordingStatefulSets) Get(ctx context.Context, name string, opts metav1.GetOptions) (*appsv1.StatefulSet, error) {
+	statefulSet, err := s.StatefulSetInterface.Get(ctx, name, opts)
+	recordStatusError(s.err, err)
+	return statefulSet, err
+}
+
+func (s *statusRecordingStatefulSets) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*appsv1.StatefulSet, error) {
+	statefulSet, err := s.StatefulSetInterface.Patch(ctx, name, pt, data, opts, subresources...)
+	recordStatusError(s.err, err)
+	return statefulSet, err
+}
+
+type statusRecordingDaemonSets struct {
+	clientappsv1.DaemonSetInterface
+	err *error
+}
+
+func (d *statusRecordingDaemonSets) Get(ctx context.Context, name string, opts metav1.GetOptions) (*appsv1.DaemonSet, error) {
+	daemonSet, err := d.DaemonSetInterface.Get(ctx, name, opts)
+	recordStatusError(d.err, err)
+	return daemonSet, err
+}
+
+func (d *statusRecordingDaemonSets) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*appsv1.DaemonSet, error) {
+	daemonSet, err := d.DaemonSetInterface.Patch(ctx, name, pt, data, opts, subresources...)
+	recordStatusError(d.err, err)
+	return daemonSet, err
+}
+
+type statusRecordingReplicaSets struct {
+	clientappsv1.ReplicaSetInterface
+	err *error
+}
+
+func (r *statusRecordingReplicaSets) List(ctx context.Context, opts metav1.ListOptions) (*appsv1.ReplicaSetList, error) {
+	list, err := r.ReplicaSetInterface.List(ctx, opts)
+	recordStatusError(r.err, err)
+	return list, err
+}
+
+type statusRecordingControllerRevisions struct {
+	clientappsv1.ControllerRevisionInterface
+	err *error
+}
+
+func (c *statusRecordingControllerRevisions) List(ctx context.Context, opts metav1.ListOptions) (*appsv1.ControllerRevisionList, error) {
+	list, err := c.ControllerRevisionInterface.List(ctx, opts)
+	recordStatusError(c.err, err)
+	return list, err
+}
@@ -0,0 +1,280 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
+)
+
+const (
+	deploymentKind  = "Deployment"
+	statefulSetKind = "StatefulSet"
+	daemonSetKind   = "DaemonSet"
+	// restartedAtAnnotation is set on the pod template, like kubectl rollout restart does
+	restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+)
+
+// RolloutStatusUpdate is sent when watching a rollout, either with the current status or with an error ending the watch.
+type RolloutStatusUpdate struct {
+	Status models.RolloutStatus
+	Error  *models.ModelError
+}
+
+// RestartRollout rolls out all Pods of a Deployment, StatefulSet or DaemonSet again by changing an annotation of its pod template.
+func RestartRollout(resourceType string, namespace string, resourceName string, getResourceInterface ResourceInterfaceGetter) *models.ModelError {
+	kind, err := getRolloutKind(resourceType)
+	if err != nil {
+		return err
+	}
+	resourceInterface, err := getResourceInterface(resourceType, namespace, DefaultNamespace)
+	if err != nil {
+		return err
+	}
+
+	if kind == deploymentKind {
+		resource, getErr := resourceInterface.Get(context.TODO(), resourceName, metav1.GetOptions{})
+		if getErr != nil {
+			return handleKubernetesError(getErr)
+		}
+		if paused, _, _ := unstructured.NestedBool(resource.Object, "spec", "paused"); paused {
+			return &models.ModelError{Code: 400, Message: fmt.Sprintf("Deployment %s is paused, resume it before restarting", resourceName)}
+		}
+	}
+
+	return patchRollout(resourceInterface, resourceName, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{restartedAtAnnotation: time.Now().Format(time.RFC3339)},
+				},
+			},
+		},
+	})
+}
+
+// PauseRollout stops a Deployment from rolling out changes of its pod template until it is resumed.
+func PauseRollout(resourceType string, namespace string, resourceName string, getResourceInterface ResourceInterfaceGetter) *models.ModelError {
+	return setRolloutPaused(resourceType, namespace, resourceName, true, getResourceInterface)
+}
+
+// ResumeRollout lets a paused Deployment roll out the changes made while it was paused.
+func ResumeRollout(resourceType string, namespace string, resourceName string, getResourceInterface ResourceInterfaceGetter) *models.ModelError {
+	return setRolloutPaused(resourceType, namespace, resourceName, false, getResourceInterface)
+}
+
+func setRolloutPaused(resourceType string, namespace string, resourceName string, paused bool, getResourceInterface ResourceInterfaceGetter) *models.ModelError {
+	kind, err := getRolloutKind(resourceType)
+	if err != nil {
+		return err
+	}
+	// StatefulSets and DaemonSets have no way of pausing a rollout
+	if kind != deploymentKind {
+		return &models.ModelError{Code: 400, Message: fmt.Sprintf("Rollouts of %s cannot be paused or resumed", kind)}
+	}
+	resourceInterface, err := getResourceInterface(resourceType, namespace, DefaultNamespace)
+	if err != nil {
+		return err
+	}
+
+	return patchRollout(resourceInterface, resourceName, map[string]interface{}{
+		"spec": map[string]interface{}{"paused": paused},
+	})
+}
+
+// UndoRollout restores the pod template of a previous revision, the one before the current revision when
+// toRevision is 0. Deployments are restored from their ReplicaSets, StatefulSets and DaemonSets from their
+// ControllerRevisions. The returned message tells whether the rollback was done or skipped, as the current
+// template may already match the revision.
+func UndoRollout(resourceType string, namespace string, resourceName string, toRevision int64, getResourceInterface ResourceInterfaceGetter, getClientset ClientsetGetter) (string, *models.ModelError) {
+	kind, err := getRolloutKind(resourceType)
+	if err != nil {
+		return "", err
+	}
+	resourceInterface, err := getResourceInterface(resourceType, namespace, DefaultNamespace)
+	if err != nil {
+		return "", err
+	}
+	clientset, err := getClientset()
+	if err != nil {
+		return "", err
+	}
+
+	// Reading the resource first reports a missing resource or missing permissions with the right status code
+	resource, getErr := resourceInterface.Get(context.TODO(), resourceName, metav1.GetOptions{})
+	if getErr != nil {
+		return "", handleKubernetesError(getErr)
+	}
+
+	return rollback(kind, resource, toRevision, clientset)
+}
+
+// rollback restores a revision with the rollbackers of kubectl, which fail with plain errors, e.g. when the
+// revision does not exist or the Deployment is paused. Errors of the API server are recorded by the client
+// passed to them, so they are reported with their own status instead.
+func rollback(kind string, resource *unstructured.Unstructured, toRevision int64, clientset kubernetes.Interface) (string, *models.ModelError) {
+	recordingClientset := newStatusRecordingClientset(clientset)
+	rollbacker, rollbackerErr := polymorphichelpers.RollbackerFor(appsv1.SchemeGroupVersion.WithKind(kind).GroupKind(), recordingClientset)
+	if rollbackerErr != nil {
+		return "", &models.ModelError{Code: 500, Message: fmt.Sprintf("Rollback is not supported: %s", rollbackerErr)}
+	}
+	result, rollbackErr := rollbacker.Rollback(resource, nil, toRevision, cmdutil.DryRunNone)
+	if rollbackErr != nil {
+		return "", getRollbackError(rollbackErr, recordingClientset.lastError())
+	}
+	return result, nil
+}
+
+// GetRolloutStatus reports the progress of a rollout the way kubectl rollout status does. When revision is
+// not 0, the status of a Deployment fails unless the revision is the one being rolled out.
+func GetRolloutStatus(resourceType string, namespace string, resourceName string, revision int64, getResourceInterface ResourceInterfaceGetter) (models.RolloutStatus, *models.ModelError) {
+	kind, err := getRolloutKind(resourceType)
+	if err != nil {
+		return models.RolloutStatus{}, err
+	}
+	resourceInterface, err := getResourceInterface(resourceType, namespace, DefaultNamespace)
+	if err != nil {
+		return models.RolloutStatus{}, err
+	}
+
+	resource, getErr := resourceInterface.Get(context.TODO(), resourceName, metav1.GetOptions{})
+	if getErr != nil {
+		return models.RolloutStatus{}, handleKubernetesError(getErr)
+	}
+	return getRolloutStatus(kind, resource, revision)
+}
+
+// WatchRolloutStatus sends the status of a rollout every time the resource changes, until the rollout is done
+// or fails, the resource is deleted or ctx is done. Expired watches are restarted, so the rollout can be
+// followed for as long as it takes.
+func WatchRolloutStatus(ctx context.Context, resourceType string, namespace string, resourceName string, revision int64, getResourceInterface ResourceInterfaceGetter) (<-chan RolloutStatusUpdate, *models.ModelError) {
+	kind, err := getRolloutKind(resourceType)
+	if err != nil {
+		return nil, err
+	}
+	resourceInterface, err := getResourceInterface(resourceType, namespace, DefaultNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", resourceName).String()
+	listWatch := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			return resourceInterface.List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return resourceInterface.Watch(ctx, options)
+		},
+	}
+	// Listing first reports a missing resource or missing permissions before the response starts
+	list, listErr := listWatch.List(metav1.ListOptions{})
+	if listErr != nil {
+		return nil, handleKubernetesError(listErr)
+	}
+	if items, _ := list.(*unstructured.UnstructuredList); items == nil || len(items.Items) == 0 {
+		return nil, &models.ModelError{Code: 404, Message: fmt.Sprintf("Resource not found: %s %s", kind, resourceName)}
+	}
+
+	updates := make(chan RolloutStatusUpdate)
+	go func() {
+		defer close(updates)
+		send := func(update RolloutStatusUpdate) bool {
+			select {
+			case updates <- update:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		_, watchErr := watchtools.UntilWithSync(ctx, listWatch, &unstructured.Unstructured{}, nil, func(event watch.Event) (bool, error) {
+			switch event.Type {
+			case watch.Deleted:
+				send(RolloutStatusUpdate{Error: &models.ModelError{Code: 404, Message: fmt.Sprintf("%s %s was deleted", kind, resourceName)}})
+				return true, nil
+			case watch.Added, watch.Modified:
+				resource, ok := event.Object.(*unstructured.Unstructured)
+				if !ok {
+					return false, nil
+				}
+				status, statusErr := getRolloutStatus(kind, resource, revision)
+				if statusErr != nil {
+					send(RolloutStatusUpdate{Error: statusErr})
+					return true, nil
+				}
+				if !send(RolloutStatusUpdate{Status: status}) {
+					return true, nil
+				}
+				return status.Done || status.Failed, nil
+			}
+			return false, nil
+		})
+		if watchErr != nil && ctx.Err() == nil {
+			send(RolloutStatusUpdate{Error: handleKubernetesErrorOr(watchErr, 500, "Watching the rollout failed")})
+		}
+	}()
+	return updates, nil
+}
+
+func getRolloutStatus(kind string, resource *unstructured.Unstructured, revision int64) (models.RolloutStatus, *models.ModelError) {
+	viewer, viewerErr := polymorphichelpers.StatusViewerFor(appsv1.SchemeGroupVersion.WithKind(kind).GroupKind())
+	if viewerErr != nil {
+		return models.RolloutStatus{}, &models.ModelError{Code: 400, Message: fmt.Sprintf("Rollout status is not supported: %s", viewerErr)}
+	}
+
+	status := models.RolloutStatus{Name: resource.GetName(), Namespace: resource.GetNamespace()}
+	message, done, statusErr := viewer.Status(resource, revision)
+	if statusErr != nil {
+		// A rollout past its progress deadline or of another revision will not complete
+		status.Message = statusErr.Error()
+		status.Failed = true
+		return status, nil
+	}
+	status.Message = strings.TrimSpace(message)
+	status.Done = done
+	return status, nil
+}
+
+// getRolloutKind returns the kind of the resource type if it has rollouts, which only the workloads of the apps group have.
+func getRolloutKind(resourceType string) (string, *models.ModelError) {
+	resolved, err := resolveResourceType(resourceType)
+	if err != nil {
+		return "", err
+	}
+	if resolved.GroupVersionResource.Group == appsv1.GroupName {
+		switch resolved.Kind {
+		case deploymentKind, statefulSetKind, daemonSetKind:
+			return resolved.Kind, nil
+		}
+	}
+	return "", &models.ModelError{Code: 400, Message: fmt.Sprintf("Resource type %s does not support rollouts", resourceType)}
+}
+
+func patchRollout(resourceInterface dynamic.ResourceInterface, resourceName string, content map[string]interface{}) *models.ModelError {
+	data, marshalErr := json.Marshal(content)
+	if marshalErr != nil {
+		return &models.ModelError{Code: 500, Message: fmt.Sprintf("Failed to create patch: %s", marshalErr)}
+	}
+	if _, patchErr := resourceInterface.Patch(context.TODO(), resourceName, types.MergePatchType, data, metav1.PatchOptions{}); patchErr != nil {
+		return handleKubernetesError(patchErr)
+	}
+	return nil
+}
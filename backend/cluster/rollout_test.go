@@ -0,0 +1,196 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+)
+
+func newTestDeployment(image string, revision string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "api",
+			Namespace:   "payments",
+			UID:         types.UID("api-uid"),
+			Generation:  2,
+			Annotations: map[string]string{"deployment.kubernetes.io/revision": revision},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To(int32(3)),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+			Template: newTestPodTemplate(image),
+		},
+		Status: appsv1.DeploymentStatus{ObservedGeneration: 2},
+	}
+}
+
+func newTestPodTemplate(image string) corev1.PodTemplateSpec {
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "api"}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: image}}},
+	}
+}
+
+func newTestReplicaSet(name string, image string, revision string) *appsv1.ReplicaSet {
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "payments",
+			UID:         types.UID(name + "-uid"),
+			Labels:      map[string]string{"app": "api"},
+			Annotations: map[string]string{"deployment.kubernetes.io/revision": revision},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "api",
+				UID:        types.UID("api-uid"),
+				Controller: ptr.To(true),
+			}},
+		},
+		Spec: appsv1.ReplicaSetSpec{
+			Replicas: ptr.To(int32(0)),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+			Template: newTestPodTemplate(image),
+		},
+	}
+}
+
+func toTestUnstructured(t *testing.T, object runtime.Object) *unstructured.Unstructured {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	assert.NoError(t, err)
+	return &unstructured.Unstructured{Object: content}
+}
+
+func TestGetRolloutStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         appsv1.DeploymentStatus
+		revision       int64
+		expectedDone   bool
+		expectedFailed bool
+		expectedPrefix string
+	}{
+		{
+			name:           "replicas being updated",
+			status:         appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 1},
+			expectedPrefix: "Waiting for deployment \"api\" rollout to finish: 1 out of 3 new replicas have been updated",
+		},
+		{
+			name:           "rolled out",
+			status:         appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3},
+			expectedDone:   true,
+			expectedPrefix: "deployment \"api\" successfully rolled out",
+		},
+		{
+			name: "progress deadline exceeded",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Conditions: []appsv1.DeploymentCondition{{
+				Type:   appsv1.DeploymentProgressing,
+				Status: corev1.ConditionFalse,
+				Reason: "ProgressDeadlineExceeded",
+			}}},
+			expectedFailed: true,
+			expectedPrefix: "deployment \"api\" exceeded its progress deadline",
+		},
+		{
+			name:           "other revision",
+			status:         appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3},
+			revision:       1,
+			expectedFailed: true,
+			expectedPrefix: "desired revision (1) is different from the running revision (2)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := newTestDeployment("api:v2", "2")
+			deployment.Status = tt.status
+
+			status, err := getRolloutStatus(deploymentKind, toTestUnstructured(t, deployment), tt.revision)
+			assert.Nil(t, err)
+			assert.Equal(t, "api", status.Name)
+			assert.Equal(t, "payments", status.Namespace)
+			assert.Equal(t, tt.expectedDone, status.Done)
+			assert.Equal(t, tt.expectedFailed, status.Failed)
+			assert.Contains(t, status.Message, tt.expectedPrefix)
+		})
+	}
+}
+
+func TestRollbackDeployment(t *testing.T) {
+	deployment := newTestDeployment("api:v2", "2")
+	clientset := fake.NewSimpleClientset(
+		deployment,
+		newTestReplicaSet("api-1", "api:v1", "1"),
+		newTestReplicaSet("api-2", "api:v2", "2"),
+	)
+
+	result, err := rollback(deploymentKind, toTestUnstructured(t, deployment), 0, clientset)
+	assert.Nil(t, err)
+	assert.Equal(t, "rolled back", result)
+
+	restored, getErr := clientset.AppsV1().Deployments("payments").Get(context.TODO(), "api", metav1.GetOptions{})
+	assert.NoError(t, getErr)
+	assert.Equal(t, "api:v1", restored.Spec.Template.Spec.Containers[0].Image)
+}
+
+func TestRollbackDeploymentErrors(t *testing.T) {
+	deployment := newTestDeployment("api:v2", "2")
+	clientset := fake.NewSimpleClientset(deployment, newTestReplicaSet("api-2", "api:v2", "2"))
+
+	_, err := rollback(deploymentKind, toTestUnstructured(t, deployment), 5, clientset)
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(400), err.Code)
+
+	paused := newTestDeployment("api:v2", "2")
+	paused.Spec.Paused = true
+	clientset = fake.NewSimpleClientset(paused, newTestReplicaSet("api-1", "api:v1", "1"), newTestReplicaSet("api-2", "api:v2", "2"))
+
+	_, err = rollback(deploymentKind, toTestUnstructured(t, paused), 0, clientset)
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(409), err.Code)
+}
+
+func TestRollbackDeploymentStatusErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		verb         string
+		resource     string
+		err          error
+		expectedCode int32
+	}{
+		{"forbidden patch", "patch", "deployments", apierrors.NewForbidden(appsv1.Resource("deployments"), "api", errors.New("denied")), 403},
+		{"conflicting patch", "patch", "deployments", apierrors.NewConflict(appsv1.Resource("deployments"), "api", errors.New("modified")), 409},
+		{"forbidden history", "list", "replicasets", apierrors.NewForbidden(appsv1.Resource("replicasets"), "", errors.New("denied")), 403},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := newTestDeployment("api:v2", "2")
+			clientset := fake.NewSimpleClientset(
+				deployment,
+				newTestReplicaSet("api-1", "api:v1", "1"),
+				newTestReplicaSet("api-2", "api:v2", "2"),
+			)
+			clientset.PrependReactor(tt.verb, tt.resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, tt.err
+			})
+
+			_, err := rollback(deploymentKind, toTestUnstructured(t, deployment), 0, clientset)
+			assert.NotNil(t, err)
+			assert.Equal(t, tt.expectedCode, err.Code)
+		})
+	}
+}
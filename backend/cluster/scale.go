@@ -0,0 +1,101 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const scaleSubresource = "scale"
+
+// GetScale reads the scale subresource of a workload, served by Deployments, StatefulSets, ReplicaSets
+// and custom resources which enable it.
+func GetScale(resourceType string, namespace string, resourceName string, getResourceInterface ResourceInterfaceGetter) (models.ResourceScale, *models.ModelError) {
+	resourceInterface, err := getResourceInterface(resourceType, namespace, DefaultNamespace)
+	if err != nil {
+		return models.ResourceScale{}, err
+	}
+
+	scale, getErr := resourceInterface.Get(context.TODO(), resourceName, metav1.GetOptions{}, scaleSubresource)
+	if getErr != nil {
+		return models.ResourceScale{}, handleKubernetesError(getErr)
+	}
+	return toResourceScale(scale), nil
+}
+
+// UpdateScale sets the replica count of a workload with a merge patch of its scale subresource, which unlike
+// an update of the whole object does not conflict with changes made by controllers to other fields. When
+// resourceVersion is set, the patch fails if the workload has changed since then.
+func UpdateScale(resourceType string, namespace string, resourceName string, replicas int32, resourceVersion string, getResourceInterface ResourceInterfaceGetter) (models.ResourceScale, *models.ModelError) {
+	resourceInterface, err := getResourceInterface(resourceType, namespace, DefaultNamespace)
+	if err != nil {
+		return models.ResourceScale{}, err
+	}
+
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{"replicas": replicas},
+	}
+	if resourceVersion != "" {
+		patch["metadata"] = map[string]interface{}{"resourceVersion": resourceVersion}
+	}
+	data, marshalErr := json.Marshal(patch)
+	if marshalErr != nil {
+		return models.ResourceScale{}, &models.ModelError{Code: 500, Message: fmt.Sprintf("Failed to create patch: %s", marshalErr)}
+	}
+
+	scale, patchErr := resourceInterface.Patch(context.TODO(), resourceName, types.MergePatchType, data, metav1.PatchOptions{}, scaleSubresource)
+	if patchErr != nil {
+		return models.ResourceScale{}, handleKubernetesError(patchErr)
+	}
+	return toResourceScale(scale), nil
+}
+
+// SupportsScale reports whether the resource type serves the scale subresource.
+func SupportsScale(resourceType string) (bool, *models.ModelError) {
+	resolved, err := resolveResourceType(resourceType)
+	if err != nil {
+		return false, err
+	}
+	groupVersion := resolved.GroupVersionResource.GroupVersion()
+	apiResourceLists, err := discoverAPIResources(resourceTypeName{Group: groupVersion.Group, Version: groupVersion.Version, GroupSet: true})
+	if err != nil {
+		return false, err
+	}
+	return hasSubresource(apiResourceLists, resolved.GroupVersionResource, scaleSubresource), nil
+}
+
+func hasSubresource(apiResourceLists []*metav1.APIResourceList, gvr schema.GroupVersionResource, subresource string) bool {
+	for _, apiResourceList := range apiResourceLists {
+		if apiResourceList == nil || apiResourceList.GroupVersion != gvr.GroupVersion().String() {
+			continue
+		}
+		for _, apiResource := range apiResourceList.APIResources {
+			if apiResource.Name == gvr.Resource+"/"+subresource {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// toResourceScale reads an autoscaling/v1 Scale. Custom resources report their selector only in the
+// status, so a missing field is left empty rather than treated as an error.
+func toResourceScale(scale *unstructured.Unstructured) models.ResourceScale {
+	replicas, _, _ := unstructured.NestedInt64(scale.Object, "spec", "replicas")
+	currentReplicas, _, _ := unstructured.NestedInt64(scale.Object, "status", "replicas")
+	selector, _, _ := unstructured.NestedString(scale.Object, "status", "selector")
+	return models.ResourceScale{
+		Name:            scale.GetName(),
+		Namespace:       scale.GetNamespace(),
+		Replicas:        int32(replicas),
+		CurrentReplicas: int32(currentReplicas),
+		Selector:        selector,
+		ResourceVersion: scale.GetResourceVersion(),
+	}
+}
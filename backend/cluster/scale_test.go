@@ -0,0 +1,111 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+type scaleResourceInterface struct {
+	dynamic.ResourceInterface
+	scale        *unstructured.Unstructured
+	err          error
+	subresources []string
+	patchType    types.PatchType
+	patch        string
+}
+
+func (s *scaleResourceInterface) Get(ctx context.Context, name string, options metav1.GetOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	s.subresources = subresources
+	return s.scale, s.err
+}
+
+func (s *scaleResourceInterface) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	s.subresources = subresources
+	s.patchType = pt
+	s.patch = string(data)
+	return s.scale, s.err
+}
+
+func newTestScale(replicas int64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "autoscaling/v1",
+		"kind":       "Scale",
+		"metadata":   map[string]interface{}{"name": "api", "namespace": "payments", "resourceVersion": "42"},
+		"spec":       map[string]interface{}{"replicas": replicas},
+		"status":     map[string]interface{}{"replicas": int64(2), "selector": "app=api"},
+	}}
+}
+
+func getTestScaleResourceInterface(resourceInterface dynamic.ResourceInterface) ResourceInterfaceGetter {
+	return func(resourceType, namespace, emptyNamespace string) (dynamic.ResourceInterface, *models.ModelError) {
+		return resourceInterface, nil
+	}
+}
+
+func TestGetScale(t *testing.T) {
+	resourceInterface := &scaleResourceInterface{scale: newTestScale(3)}
+
+	scale, err := GetScale("Deployment", "payments", "api", getTestScaleResourceInterface(resourceInterface))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"scale"}, resourceInterface.subresources)
+	assert.Equal(t, models.ResourceScale{
+		Name:            "api",
+		Namespace:       "payments",
+		Replicas:        3,
+		CurrentReplicas: 2,
+		Selector:        "app=api",
+		ResourceVersion: "42",
+	}, scale)
+}
+
+func TestGetScaleNotFound(t *testing.T) {
+	resourceInterface := &scaleResourceInterface{
+		err: apierrors.NewNotFound(schema.GroupResource{Group: "apps", Resource: "deployments"}, "api"),
+	}
+
+	_, err := GetScale("Deployment", "payments", "api", getTestScaleResourceInterface(resourceInterface))
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(404), err.Code)
+}
+
+func TestUpdateScale(t *testing.T) {
+	resourceInterface := &scaleResourceInterface{scale: newTestScale(5)}
+
+	scale, err := UpdateScale("Deployment", "payments", "api", 5, "", getTestScaleResourceInterface(resourceInterface))
+	assert.Nil(t, err)
+	assert.Equal(t, int32(5), scale.Replicas)
+	assert.Equal(t, []string{"scale"}, resourceInterface.subresources)
+	assert.Equal(t, types.MergePatchType, resourceInterface.patchType)
+	assert.JSONEq(t, `{"spec":{"replicas":5}}`, resourceInterface.patch)
+
+	_, err = UpdateScale("Deployment", "payments", "api", 0, "41", getTestScaleResourceInterface(resourceInterface))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"spec":{"replicas":0},"metadata":{"resourceVersion":"41"}}`, resourceInterface.patch)
+}
+
+func TestHasSubresource(t *testing.T) {
+	apiResourceLists := []*metav1.APIResourceList{
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Kind: "Deployment"},
+				{Name: "deployments/scale", Kind: "Scale"},
+				{Name: "daemonsets", Kind: "DaemonSet"},
+				{Name: "daemonsets/status", Kind: "DaemonSet"},
+			},
+		},
+	}
+
+	assert.True(t, hasSubresource(apiResourceLists, schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, "scale"))
+	assert.False(t, hasSubresource(apiResourceLists, schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "daemonsets"}, "scale"))
+	assert.False(t, hasSubresource(apiResourceLists, schema.GroupVersionResource{Group: "apps", Version: "v2", Resource: "deployments"}, "scale"))
+}
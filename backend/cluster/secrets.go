@@ -0,0 +1,104 @@
+package cluster
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// RedactedSecretValue replaces the values of Secrets in responses. It is not valid base64, so the API server
+// rejects a patch or an update which would store it, unless UpdateResource restores the original value.
+const RedactedSecretValue = "********"
+
+// redactSecret returns a copy of a Secret with the values of its data masked, other resources are returned as
+// they are. The copy keeps objects shared with the informer cache intact.
+func redactSecret(resourceType string, resource interface{}) interface{} {
+	object, ok := resource.(*unstructured.Unstructured)
+	if !ok || object == nil || (resourceType != secretString && object.GetKind() != secretString) {
+		return resource
+	}
+
+	redacted := object.DeepCopy()
+	for _, field := range []string{"data", "stringData"} {
+		values, found, _ := unstructured.NestedMap(redacted.Object, field)
+		if !found {
+			continue
+		}
+		for key := range values {
+			values[key] = RedactedSecretValue
+		}
+		_ = unstructured.SetNestedMap(redacted.Object, values, field)
+	}
+	return redacted
+}
+
+// restoreRedactedSecretValues puts back the current values of the keys left masked in an updated Secret,
+// so that a Secret read with masked values can be edited and sent back without revealing it.
+func restoreRedactedSecretValues(resourceInterface dynamic.ResourceInterface, resource *unstructured.Unstructured) *models.ModelError {
+	data, _, _ := unstructured.NestedMap(resource.Object, "data")
+	redactedKeys := make([]string, 0)
+	for key, value := range data {
+		if value == RedactedSecretValue {
+			redactedKeys = append(redactedKeys, key)
+		}
+	}
+	if len(redactedKeys) == 0 {
+		return nil
+	}
+
+	current, getErr := resourceInterface.Get(context.TODO(), resource.GetName(), metav1.GetOptions{})
+	if getErr != nil {
+		return handleKubernetesError(getErr)
+	}
+	currentData, _, _ := unstructured.NestedMap(current.Object, "data")
+	for _, key := range redactedKeys {
+		value, found := currentData[key]
+		if !found {
+			return &models.ModelError{Code: 400, Message: fmt.Sprintf("Key %s is redacted, but the Secret has no value for it", key)}
+		}
+		data[key] = value
+	}
+	_ = unstructured.SetNestedMap(resource.Object, data, "data")
+	return nil
+}
+
+// RevealSecret returns the decoded values of the given keys of a Secret, or of all its keys when none are given.
+func RevealSecret(namespace string, secretName string, keys []string, getClientset ClientsetGetter) (models.SecretReveal, *models.ModelError) {
+	clientset, err := getClientset()
+	if err != nil {
+		return models.SecretReveal{}, err
+	}
+	secret, getErr := clientset.CoreV1().Secrets(namespace).Get(context.TODO(), secretName, metav1.GetOptions{})
+	if getErr != nil {
+		return models.SecretReveal{}, handleKubernetesError(getErr)
+	}
+
+	if len(keys) == 0 {
+		for key := range secret.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+	}
+	reveal := models.SecretReveal{Name: secret.Name, Namespace: secret.Namespace, Data: make(map[string]string, len(keys))}
+	for _, key := range keys {
+		value, found := secret.Data[key]
+		if !found {
+			return models.SecretReveal{}, &models.ModelError{Code: 404, Message: fmt.Sprintf("Key %s not found in Secret %s", key, secretName)}
+		}
+		// Binary values, e.g. keystores, are left encoded, as JSON strings cannot hold them
+		if utf8.Valid(value) {
+			reveal.Data[key] = string(value)
+		} else {
+			reveal.Data[key] = base64.StdEncoding.EncodeToString(value)
+			reveal.Base64Keys = append(reveal.Base64Keys, key)
+		}
+	}
+	return reveal, nil
+}
@@ -0,0 +1,103 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestSecret(data map[string]interface{}) *unstructured.Unstructured {
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "db-credentials", "namespace": "payments"},
+		"data":       data,
+	}}
+	return secret
+}
+
+func TestRedactSecret(t *testing.T) {
+	secret := newTestSecret(map[string]interface{}{"username": "YWRtaW4=", "password": "czNjcjN0"})
+
+	redacted := redactSecret(secretString, secret).(*unstructured.Unstructured)
+
+	data, _, _ := unstructured.NestedMap(redacted.Object, "data")
+	assert.Equal(t, map[string]interface{}{"username": RedactedSecretValue, "password": RedactedSecretValue}, data)
+	// The original object may be shared with the informer cache
+	original, _, _ := unstructured.NestedString(secret.Object, "data", "password")
+	assert.Equal(t, "czNjcjN0", original)
+}
+
+func TestRedactSecretLeavesOtherResources(t *testing.T) {
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind": "ConfigMap",
+		"data": map[string]interface{}{"key": "value"},
+	}}
+
+	assert.Same(t, configMap, redactSecret("ConfigMap", configMap))
+}
+
+func TestGetResourceRedactsSecret(t *testing.T) {
+	secret := newTestSecret(map[string]interface{}{"password": "czNjcjN0"})
+
+	result, err := GetResource(secretString, "payments", "db-credentials", getTestScaleResourceInterface(&MockResourceInterface{ReturnedValue: secret}))
+
+	assert.Nil(t, err)
+	value, _, _ := unstructured.NestedString((*result.ResourceDetails).(*unstructured.Unstructured).Object, "data", "password")
+	assert.Equal(t, RedactedSecretValue, value)
+}
+
+func TestUpdateResourceRestoresRedactedValues(t *testing.T) {
+	resourceInterface := &MockResourceInterface{ReturnedValue: newTestSecret(map[string]interface{}{"username": "YWRtaW4=", "password": "czNjcjN0"})}
+	var details interface{} = map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "db-credentials"},
+		"data":       map[string]interface{}{"username": "cm9vdA==", "password": RedactedSecretValue},
+	}
+
+	_, err := UpdateResource(secretString, "payments", "db-credentials", models.ResourceDetails{ResourceDetails: &details}, false, getTestScaleResourceInterface(resourceInterface))
+
+	assert.Nil(t, err)
+	data := details.(map[string]interface{})["data"].(map[string]interface{})
+	assert.Equal(t, "cm9vdA==", data["username"])
+	assert.Equal(t, "czNjcjN0", data["password"])
+}
+
+func TestRestoreRedactedSecretValuesMissingKey(t *testing.T) {
+	resourceInterface := &MockResourceInterface{ReturnedValue: newTestSecret(map[string]interface{}{})}
+	secret := newTestSecret(map[string]interface{}{"token": RedactedSecretValue})
+
+	err := restoreRedactedSecretValues(resourceInterface, secret)
+
+	assert.Equal(t, int32(400), err.Code)
+}
+
+func TestRevealSecret(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-credentials", Namespace: "payments"},
+		Data: map[string][]byte{
+			"username": []byte("admin"),
+			"password": []byte("s3cr3t"),
+			"keystore": {0xfe, 0xed, 0xfe, 0xed},
+		},
+	})
+
+	reveal, err := RevealSecret("payments", "db-credentials", nil, getTestClientset(clientset))
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"username": "admin", "password": "s3cr3t", "keystore": "/u3+7Q=="}, reveal.Data)
+	assert.Equal(t, []string{"keystore"}, reveal.Base64Keys)
+
+	reveal, err = RevealSecret("payments", "db-credentials", []string{"password"}, getTestClientset(clientset))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"password": "s3cr3t"}, reveal.Data)
+
+	_, err = RevealSecret("payments", "db-credentials", []string{"token"}, getTestClientset(clientset))
+	assert.Equal(t, int32(404), err.Code)
+}
@@ -0,0 +1,22 @@
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ValidateSelectors parses the label and field selectors of the list options, so that malformed
+// selectors are rejected with a clear message before reaching the API server.
+func ValidateSelectors(listOptions metav1.ListOptions) *models.ModelError {
+	if _, err := labels.Parse(listOptions.LabelSelector); err != nil {
+		return &models.ModelError{Code: 400, Message: fmt.Sprintf("Invalid labelSelector: %s", err)}
+	}
+	if _, err := fields.ParseSelector(listOptions.FieldSelector); err != nil {
+		return &models.ModelError{Code: 400, Message: fmt.Sprintf("Invalid fieldSelector: %s", err)}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateSelectors(t *testing.T) {
+	tests := []struct {
+		name          string
+		listOptions   metav1.ListOptions
+		expectedCode  int32
+		expectedValid bool
+	}{
+		{"No selectors", metav1.ListOptions{}, 0, true},
+		{"Equality label selector", metav1.ListOptions{LabelSelector: "app=payments"}, 0, true},
+		{"Set based label selector", metav1.ListOptions{LabelSelector: "env in (prod,staging),!canary"}, 0, true},
+		{"Field selector", metav1.ListOptions{FieldSelector: "spec.nodeName=node-1,status.phase!=Running"}, 0, true},
+		{"Both selectors", metav1.ListOptions{LabelSelector: "app=payments", FieldSelector: "spec.nodeName=node-1"}, 0, true},
+		{"Malformed label selector", metav1.ListOptions{LabelSelector: "=payments"}, 400, false},
+		{"Invalid label value", metav1.ListOptions{LabelSelector: "app=pay ments"}, 400, false},
+		{"Unclosed set", metav1.ListOptions{LabelSelector: "env in (prod"}, 400, false},
+		{"Malformed field selector", metav1.ListOptions{FieldSelector: "spec.nodeName"}, 400, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSelectors(tt.listOptions)
+			if tt.expectedValid {
+				assert.Nil(t, err)
+			} else {
+				assert.NotNil(t, err)
+				assert.Equal(t, tt.expectedCode, err.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package cluster
+
+import (
+	"context"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const WatchEventError = "ERROR"
+
+// WatchResources streams changes of resources of the given type made after listOptions.ResourceVersion,
+// each converted to a list row. The channel is closed when ctx is done, the API server ends the watch
+// or after an ERROR event.
+func WatchResources(ctx context.Context, resourceType string, namespace string, listOptions metav1.ListOptions, getResourceInterface ResourceInterfaceGetter) (<-chan models.ResourceEvent, *models.ModelError) {
+	resourceInterface, err := getResourceInterface(resourceType, namespace, emptyNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	listOptions.Limit = 0
+	listOptions.Continue = ""
+	watcher, watchErr := resourceInterface.Watch(ctx, listOptions)
+	if watchErr != nil {
+		return nil, handleKubernetesError(watchErr)
+	}
+
+	columnsResourceType := getColumnsResourceType(resourceType)
+	events := make(chan models.ResourceEvent)
+	go func() {
+		defer close(events)
+		defer watcher.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, open := <-watcher.ResultChan():
+				if !open {
+					return
+				}
+				resourceEvent, ok := convertWatchEvent(event, columnsResourceType)
+				if !ok {
+					continue
+				}
+				select {
+				case events <- resourceEvent:
+				case <-ctx.Done():
+					return
+				}
+				if resourceEvent.Type == WatchEventError {
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+func convertWatchEvent(event watch.Event, resourceType string) (models.ResourceEvent, bool) {
+	switch event.Type {
+	case watch.Added, watch.Modified, watch.Deleted:
+		resource, ok := event.Object.(*unstructured.Unstructured)
+		if !ok {
+			return models.ResourceEvent{}, false
+		}
+		return models.ResourceEvent{
+			Type:     string(event.Type),
+			Resource: extractResourceRow(*resource, resourceType),
+		}, true
+	case watch.Error:
+		return models.ResourceEvent{
+			Type:  WatchEventError,
+			Error: handleKubernetesError(apierrors.FromObject(event.Object)),
+		}, true
+	default:
+		return models.ResourceEvent{}, false
+	}
+}
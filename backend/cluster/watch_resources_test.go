@@ -0,0 +1,117 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+)
+
+type MockWatchResourceInterface struct {
+	dynamic.ResourceInterface
+	Watcher         *watch.FakeWatcher
+	ReturnedError   error
+	ReceivedOptions metav1.ListOptions
+}
+
+func (m *MockWatchResourceInterface) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	m.ReceivedOptions = opts
+	if m.ReturnedError != nil {
+		return nil, m.ReturnedError
+	}
+	return m.Watcher, nil
+}
+
+func mockPod(name string, namespace string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": namespace,
+			},
+		},
+	}
+}
+
+func TestWatchResources(t *testing.T) {
+	mockResourceInterface := &MockWatchResourceInterface{Watcher: watch.NewFakeWithChanSize(4, false)}
+	getResourceI := func(resourceType string, namespace string, emptyNamespace string) (dynamic.ResourceInterface, *models.ModelError) {
+		return mockResourceInterface, nil
+	}
+
+	events, err := WatchResources(context.Background(), "Pod", "validNamespace",
+		metav1.ListOptions{ResourceVersion: "100", LabelSelector: "app=payments", Limit: 10, Continue: "token"}, getResourceI)
+	assert.Nil(t, err)
+	assert.Equal(t, metav1.ListOptions{ResourceVersion: "100", LabelSelector: "app=payments"}, mockResourceInterface.ReceivedOptions)
+
+	mockResourceInterface.Watcher.Add(mockPod("pod1", "validNamespace"))
+	mockResourceInterface.Watcher.Action(watch.Bookmark, mockPod("", ""))
+	mockResourceInterface.Watcher.Modify(mockPod("pod1", "validNamespace"))
+	mockResourceInterface.Watcher.Delete(mockPod("pod1", "validNamespace"))
+
+	for _, expectedType := range []string{"ADDED", "MODIFIED", "DELETED"} {
+		event := <-events
+		assert.Equal(t, expectedType, event.Type)
+		assert.Equal(t, "pod1", event.Resource.Name)
+		assert.Equal(t, "validNamespace", event.Resource.Namespace)
+	}
+
+	mockResourceInterface.Watcher.Stop()
+	_, open := <-events
+	assert.False(t, open)
+}
+
+func TestWatchResourcesEndsAfterError(t *testing.T) {
+	mockResourceInterface := &MockWatchResourceInterface{Watcher: watch.NewFakeWithChanSize(2, false)}
+	getResourceI := func(resourceType string, namespace string, emptyNamespace string) (dynamic.ResourceInterface, *models.ModelError) {
+		return mockResourceInterface, nil
+	}
+
+	events, err := WatchResources(context.Background(), "Pod", "validNamespace", metav1.ListOptions{}, getResourceI)
+	assert.Nil(t, err)
+
+	status := apierrors.NewResourceExpired("too old resource version").Status()
+	mockResourceInterface.Watcher.Error(&status)
+
+	event := <-events
+	assert.Equal(t, WatchEventError, event.Type)
+	assert.Equal(t, int32(410), event.Error.Code)
+
+	_, open := <-events
+	assert.False(t, open)
+}
+
+func TestWatchResourcesStopsWithContext(t *testing.T) {
+	mockResourceInterface := &MockWatchResourceInterface{Watcher: watch.NewFake()}
+	getResourceI := func(resourceType string, namespace string, emptyNamespace string) (dynamic.ResourceInterface, *models.ModelError) {
+		return mockResourceInterface, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := WatchResources(ctx, "Pod", "validNamespace", metav1.ListOptions{}, getResourceI)
+	assert.Nil(t, err)
+
+	cancel()
+	_, open := <-events
+	assert.False(t, open)
+	assert.True(t, mockResourceInterface.Watcher.IsStopped())
+}
+
+func TestWatchResourcesError(t *testing.T) {
+	expectedError := errors.New("failed to watch")
+	mockResourceInterface := &MockWatchResourceInterface{ReturnedError: expectedError}
+	getResourceI := func(resourceType string, namespace string, emptyNamespace string) (dynamic.ResourceInterface, *models.ModelError) {
+		return mockResourceInterface, nil
+	}
+
+	events, err := WatchResources(context.Background(), "Pod", "validNamespace", metav1.ListOptions{}, getResourceI)
+	assert.Nil(t, events)
+	assert.Equal(t, &models.ModelError{Code: 500, Message: "Internal server error: " + expectedError.Error()}, err)
+}
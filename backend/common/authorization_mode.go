@@ -0,0 +1,21 @@
+package common
+
+// UsesRoleMap reports whether requests have to be authorized against the role map.
+func UsesRoleMap() bool {
+	return AuthorizationMode != AUTHORIZATION_MODE_RBAC
+}
+
+// UsesImpersonation reports whether requests to the API server have to be sent on behalf of the user
+// from the JWT token, so that native RBAC decides about access and audit logs show the real user.
+func UsesImpersonation() bool {
+	return AuthorizationMode == AUTHORIZATION_MODE_RBAC || AuthorizationMode == AUTHORIZATION_MODE_BOTH
+}
+
+func isValidAuthorizationMode(mode string) bool {
+	switch mode {
+	case AUTHORIZATION_MODE_ROLEMAP, AUTHORIZATION_MODE_RBAC, AUTHORIZATION_MODE_BOTH:
+		return true
+	default:
+		return false
+	}
+}
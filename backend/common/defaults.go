@@ -4,4 +4,18 @@ const(
 	DEFAULT_NAMESPACE = "default"
 	DEFAULT_ROLEMAP_NAMESPACE = "default"
 	DEFAULT_ROLEMAP_NAME = "role-map"	
-)
\ No newline at end of file
+	DEFAULT_AUTHORIZATION_MODE = AUTHORIZATION_MODE_ROLEMAP
+	DEFAULT_DISCOVERY_REFRESH_INTERVAL = 300
+	DEFAULT_INFORMER_CACHE_ENABLED = false
+	DEFAULT_INFORMER_CACHE_IDLE_TIMEOUT = 600
+	DEFAULT_SESSION_RECORDING_ENABLED = false
+	DEFAULT_SESSION_RECORDING_DIR = "/var/lib/kam/recordings"
+	DEFAULT_PORT_FORWARD_IDLE_TIMEOUT = 300
+	DEFAULT_PORT_FORWARD_MAX_TUNNELS_PER_USER = 5
+)
+
+const (
+	AUTHORIZATION_MODE_ROLEMAP = "rolemap"
+	AUTHORIZATION_MODE_RBAC    = "rbac"
+	AUTHORIZATION_MODE_BOTH    = "both"
+)
@@ -17,6 +17,27 @@ var (
 	KeycloakJwksUrl  string
 	RoleMapNamespace string
 	RoleMapName      string
+	// AuthorizationMode is one of "rolemap", "rbac" or "both"
+	AuthorizationMode string
+	// Comma separated "Kind" or "Kind.group" patterns of resource types exposed by the API
+	ResourceTypesInclude string
+	ResourceTypesExclude string
+	// DiscoveryRefreshInterval is the number of seconds after which cached API discovery data is refreshed
+	DiscoveryRefreshInterval int
+	// InformerCacheEnabled turns on serving list and get requests from in-memory informer caches
+	InformerCacheEnabled bool
+	// InformerCacheIdleTimeout is the number of seconds after which an unused informer is stopped
+	InformerCacheIdleTimeout int
+	// SessionRecordingEnabled turns on recording of exec and attach sessions
+	SessionRecordingEnabled bool
+	// SessionRecordingDir is the directory, usually a mounted volume, where session recordings are stored
+	SessionRecordingDir string
+	// PortForwardIdleTimeout is the number of seconds without traffic after which a port-forward tunnel is closed
+	PortForwardIdleTimeout int
+	// PortForwardMaxTunnelsPerUser limits concurrent port-forward tunnels of a single user, 0 means no limit
+	PortForwardMaxTunnelsPerUser int
+	// AuditLogPath is the file the audit trail, e.g. of revealed Secrets, is appended to, empty means the standard output
+	AuditLogPath string
 )
 
 func InitEnv() {
@@ -45,6 +66,44 @@ func InitEnv() {
 	log.Printf("Using role map namespace: %s\n", RoleMapNamespace)
 	RoleMapName = getEnvOrDefault("ROLEMAP_NAME", DEFAULT_ROLEMAP_NAME)
 	log.Printf("Using role map name: %s\n", RoleMapName)
+	AuthorizationMode = getEnvOrDefault("AUTHORIZATION_MODE", DEFAULT_AUTHORIZATION_MODE)
+	if !isValidAuthorizationMode(AuthorizationMode) {
+		log.Fatalf("Invalid value for AUTHORIZATION_MODE: %s. Must be one of: %s, %s, %s. Exiting...",
+			AuthorizationMode, AUTHORIZATION_MODE_ROLEMAP, AUTHORIZATION_MODE_RBAC, AUTHORIZATION_MODE_BOTH)
+	}
+	log.Printf("Using authorization mode: %s\n", AuthorizationMode)
+	ResourceTypesInclude = getEnvOrDefault("RESOURCE_TYPES_INCLUDE", "")
+	log.Printf("Using included resource types: %s\n", ResourceTypesInclude)
+	ResourceTypesExclude = getEnvOrDefault("RESOURCE_TYPES_EXCLUDE", "")
+	log.Printf("Using excluded resource types: %s\n", ResourceTypesExclude)
+	DiscoveryRefreshInterval = getEnvAsInt("DISCOVERY_REFRESH_INTERVAL", DEFAULT_DISCOVERY_REFRESH_INTERVAL)
+	if DiscoveryRefreshInterval <= 0 {
+		log.Fatalf("Invalid value for DISCOVERY_REFRESH_INTERVAL: %d. Must be a positive number of seconds. Exiting...", DiscoveryRefreshInterval)
+	}
+	log.Printf("Using discovery refresh interval: %ds\n", DiscoveryRefreshInterval)
+	InformerCacheEnabled = getEnvAsBool("INFORMER_CACHE_ENABLED", DEFAULT_INFORMER_CACHE_ENABLED)
+	log.Printf("Using informer cache: %t\n", InformerCacheEnabled)
+	InformerCacheIdleTimeout = getEnvAsInt("INFORMER_CACHE_IDLE_TIMEOUT", DEFAULT_INFORMER_CACHE_IDLE_TIMEOUT)
+	if InformerCacheIdleTimeout <= 0 {
+		log.Fatalf("Invalid value for INFORMER_CACHE_IDLE_TIMEOUT: %d. Must be a positive number of seconds. Exiting...", InformerCacheIdleTimeout)
+	}
+	log.Printf("Using informer cache idle timeout: %ds\n", InformerCacheIdleTimeout)
+	SessionRecordingEnabled = getEnvAsBool("SESSION_RECORDING_ENABLED", DEFAULT_SESSION_RECORDING_ENABLED)
+	log.Printf("Using session recording: %t\n", SessionRecordingEnabled)
+	SessionRecordingDir = getEnvOrDefault("SESSION_RECORDING_DIR", DEFAULT_SESSION_RECORDING_DIR)
+	log.Printf("Using session recording directory: %s\n", SessionRecordingDir)
+	PortForwardIdleTimeout = getEnvAsInt("PORT_FORWARD_IDLE_TIMEOUT", DEFAULT_PORT_FORWARD_IDLE_TIMEOUT)
+	if PortForwardIdleTimeout <= 0 {
+		log.Fatalf("Invalid value for PORT_FORWARD_IDLE_TIMEOUT: %d. Must be a positive number of seconds. Exiting...", PortForwardIdleTimeout)
+	}
+	log.Printf("Using port-forward idle timeout: %ds\n", PortForwardIdleTimeout)
+	PortForwardMaxTunnelsPerUser = getEnvAsInt("PORT_FORWARD_MAX_TUNNELS_PER_USER", DEFAULT_PORT_FORWARD_MAX_TUNNELS_PER_USER)
+	if PortForwardMaxTunnelsPerUser < 0 {
+		log.Fatalf("Invalid value for PORT_FORWARD_MAX_TUNNELS_PER_USER: %d. Must be a non-negative number. Exiting...", PortForwardMaxTunnelsPerUser)
+	}
+	log.Printf("Using port-forward tunnel limit per user: %d\n", PortForwardMaxTunnelsPerUser)
+	AuditLogPath = getEnvOrDefault("AUDIT_LOG_PATH", "")
+	log.Printf("Using audit log path: %s\n", AuditLogPath)
 }
 
 func getEnvOrDefault(key, defaultValue string) string {
@@ -71,3 +130,15 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return value
 }
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := getEnvOrDefault(key, strconv.FormatBool(defaultValue))
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		log.Fatalf("Invalid value for %s: %s. Must be true or false. Exiting...", key, valueStr)
+	}
+	return value
+}
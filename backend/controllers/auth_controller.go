@@ -10,6 +10,7 @@ import (
 	"net/http"
 
 	"github.com/ZPI-2024-25/KubernetesAccessManager/auth"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/common"
 	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
 	"github.com/golang-jwt/jwt/v4"
 )
@@ -25,13 +26,16 @@ func CheckLoginStatus(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
-	rolemap, err := auth.GetRoleMapInstance()
-	if err != nil {
-		writeJSONResponse(w, http.StatusInternalServerError, &models.ModelError{
-			Message: fmt.Sprintf("Failed to get client: %s", err),
-			Code:    http.StatusInternalServerError,
-		})
-		return
+	var rolemap *auth.RoleMapRepository
+	if common.UsesRoleMap() {
+		rolemap, err = auth.GetRoleMapInstance()
+		if err != nil {
+			writeJSONResponse(w, http.StatusInternalServerError, &models.ModelError{
+				Message: fmt.Sprintf("Failed to get client: %s", err),
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
 	}
 	status, errM := getLoginStatus(claims, rolemap)
 	if errM != nil {
@@ -42,12 +46,18 @@ func CheckLoginStatus(w http.ResponseWriter, r *http.Request) {
 }
 
 func getLoginStatus(claims *jwt.MapClaims, rolemap *auth.RoleMapRepository) (*models.UserStatus, *models.ModelError) {
-	roles, err := auth.ExtractRoles(claims)
-	if err != nil {
-		return nil, err
+	var access auth.PermissionMatrix
+	if rolemap == nil {
+		// Role map is disabled, every action is allowed here and the API server decides on its own
+		access = auth.FullPermissionMatrix()
+	} else {
+		roles, err := auth.ExtractRoles(claims)
+		if err != nil {
+			return nil, err
+		}
+		access = rolemap.GetAllPermissions(roles)
+		auth.PrunePermissions(access)
 	}
-	access := rolemap.GetAllPermissions(roles)
-	auth.PrunePermissions(access)
 	permissions := toPermissionModel(access)
 	exp, preferredUsername, email := auth.ExtractUserStatus(claims)
 
@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/cluster"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+)
+
+// ApplyManifestController applies every object of a manifest, authorizing each of them with the create or
+// update operation in its namespace. The report is returned with 200 when all objects were applied and with
+// 207 when any of them failed.
+func ApplyManifestController(w http.ResponseWriter, r *http.Request) {
+	if _, err := getClaims(r); err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	options, err := getApplyOptions(r)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	documents, err := decodeManifestBody(r)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	// Whether an object is created or updated depends on the current state, which the informer cache may not have yet
+	getResourceInterface, err := getUncachedResourceInterfaceGetter(r)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+
+	authorize := func(resourceType string, namespace string, opType models.OperationType) *models.ModelError {
+		return authenticateAndAuthorize(r, models.Operation{Resource: resourceType, Namespace: namespace, Type: opType})
+	}
+	report := cluster.ApplyManifest(documents, options, authorize, getResourceInterface)
+
+	statusCode := http.StatusOK
+	if !report.Succeeded {
+		statusCode = http.StatusMultiStatus
+	}
+	writeNegotiatedResponse(w, r, statusCode, report)
+}
+
+// getApplyOptions reads the namespace, atomic, dryRun and force query parameters of a manifest apply.
+func getApplyOptions(r *http.Request) (cluster.ApplyOptions, *models.ModelError) {
+	options := cluster.ApplyOptions{Namespace: getNamespace(r)}
+	var err *models.ModelError
+	if options.Atomic, err = getBoolQueryParam(r, "atomic"); err != nil {
+		return cluster.ApplyOptions{}, err
+	}
+	if options.DryRun, err = getDryRunQueryParam(r); err != nil {
+		return cluster.ApplyOptions{}, err
+	}
+	if options.Force, err = getBoolQueryParam(r, "force"); err != nil {
+		return cluster.ApplyOptions{}, err
+	}
+	return options, nil
+}
@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/cluster"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/common"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+)
+
+// GetResourceEventsController returns the events about a resource, which only requires the read operation on it.
+func GetResourceEventsController(w http.ResponseWriter, r *http.Request) {
+	handleResourceOperation(w, r, models.Read, func(resourceType, namespace, resourceName string, getResourceInterface cluster.ResourceInterfaceGetter) (interface{}, *models.ModelError) {
+		getClientset, err := getClientsetGetter(r)
+		if err != nil {
+			return nil, err
+		}
+		return cluster.GetResourceEvents(resourceType, namespace, resourceName, getResourceInterface, getClientset)
+	})
+}
+
+// ListNamespaceEventsController returns the event timeline of a namespace, optionally filtered by the type and
+// reason query parameters. With the role map enabled only events about resources the user may read are returned.
+func ListNamespaceEventsController(w http.ResponseWriter, r *http.Request) {
+	if _, err := getClaims(r); err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	namespace := getNamespace(r)
+	if namespace == "" {
+		namespace = common.DEFAULT_NAMESPACE
+	}
+	getClientset, err := getClientsetGetter(r)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+
+	events, err := cluster.ListNamespaceEvents(namespace, r.URL.Query().Get("type"), r.URL.Query().Get("reason"), getClientset)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	if common.UsesRoleMap() {
+		events, err = cluster.FilterEventsByInvolvedObject(events, newReadFilter(r))
+		if err != nil {
+			writeJSONResponse(w, int(err.Code), err)
+			return
+		}
+	}
+	writeNegotiatedResponse(w, r, http.StatusOK, events)
+}
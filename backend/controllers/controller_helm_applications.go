@@ -3,6 +3,7 @@ package controllers
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/ZPI-2024-25/KubernetesAccessManager/auth"
@@ -16,43 +17,58 @@ const (
 )
 
 func GetHelmReleaseController(w http.ResponseWriter, r *http.Request) {
-	handleHelmOperation(w, r, models.Read, func(releaseName, namespace string) (interface{}, *models.ModelError) {
-		return helm.GetHelmRelease(releaseName, namespace, helm.PrepareActionConfig)
+	handleHelmOperation(w, r, models.Read, func(releaseName, namespace string, getActionConfig helm.ActionConfigGetter) (interface{}, *models.ModelError) {
+		return helm.GetHelmRelease(releaseName, namespace, getActionConfig)
 	})
 }
 
 func GetHelmReleaseHistoryController(w http.ResponseWriter, r *http.Request) {
-	handleHelmOperation(w, r, models.Read, func(releaseName, namespace string) (interface{}, *models.ModelError) {
-		return helm.GetHelmReleaseHistory(releaseName, namespace, helm.PrepareActionConfig)
+	handleHelmOperation(w, r, models.Read, func(releaseName, namespace string, getActionConfig helm.ActionConfigGetter) (interface{}, *models.ModelError) {
+		return helm.GetHelmReleaseHistory(releaseName, namespace, getActionConfig)
 	})
 }
 
 func ListHelmReleasesController(w http.ResponseWriter, r *http.Request) {
-	handleHelmOperation(w, r, models.List, func(releaseName, namespace string) (interface{}, *models.ModelError) {
-		if namespace != "" {
-			return helm.ListHelmReleases(namespace, helm.PrepareActionConfig)
+	handleHelmOperation(w, r, models.List, func(releaseName, namespace string, getActionConfig helm.ActionConfigGetter) (interface{}, *models.ModelError) {
+		offset, limit, err := getOffsetPagination(r)
+		if err != nil {
+			return nil, err
 		}
 
-		token, err2 := auth.GetJWTTokenFromHeader(r)
-		isValid, claims := auth.IsTokenValid(token)
-
-		if err2 != nil || !isValid {
-			return nil, &models.ModelError{
-				Message: "Unauthorized",
-				Code:    http.StatusUnauthorized,
-			}
-		}
-		releases, err := helm.ListHelmReleases(namespace, helm.PrepareActionConfig)
+		releases, err := listAllowedReleases(r, namespace, getActionConfig)
 		if err != nil {
 			return nil, err
 		}
-		filtered, errM := auth.FilterRestrictedReleases(releases, claims)
-		return filtered, errM
+
+		// Releases are paginated after filtering, so offsets are counted among releases the user may see
+		w.Header().Set("X-Total-Count", strconv.Itoa(len(releases)))
+		return helm.PaginateHelmReleases(releases, offset, limit), nil
 	})
 }
 
+func listAllowedReleases(r *http.Request, namespace string, getActionConfig helm.ActionConfigGetter) ([]models.HelmRelease, *models.ModelError) {
+	if namespace != "" || !common.UsesRoleMap() {
+		return helm.ListHelmReleases(namespace, getActionConfig)
+	}
+
+	token, err2 := auth.GetJWTTokenFromHeader(r)
+	isValid, claims := auth.IsTokenValid(token)
+
+	if err2 != nil || !isValid {
+		return nil, &models.ModelError{
+			Message: "Unauthorized",
+			Code:    http.StatusUnauthorized,
+		}
+	}
+	releases, err := helm.ListHelmReleases(namespace, getActionConfig)
+	if err != nil {
+		return nil, err
+	}
+	return auth.FilterRestrictedReleases(releases, claims)
+}
+
 func RollbackHelmReleaseController(w http.ResponseWriter, r *http.Request) {
-	handleHelmOperation(w, r, models.Update, func(releaseName, namespace string) (interface{}, *models.ModelError) {
+	handleHelmOperation(w, r, models.Update, func(releaseName, namespace string, getActionConfig helm.ActionConfigGetter) (interface{}, *models.ModelError) {
 		var version models.ReleaseNameRollbackBody
 		if !decodeJSONBody(r, &version) {
 			return nil, &models.ModelError{Code: http.StatusBadRequest, Message: "Invalid request body"}
@@ -62,7 +78,7 @@ func RollbackHelmReleaseController(w http.ResponseWriter, r *http.Request) {
 		}
 
 		timeout := DefaultOperationTimeout
-		release, completed, err := helm.RollbackHelmRelease(releaseName, namespace, int(version.Version), timeout, helm.PrepareActionConfig)
+		release, completed, err := helm.RollbackHelmRelease(releaseName, namespace, int(version.Version), timeout, getActionConfig)
 		if err != nil {
 			return nil, err
 		}
@@ -80,9 +96,9 @@ func RollbackHelmReleaseController(w http.ResponseWriter, r *http.Request) {
 }
 
 func UninstallHelmReleaseController(w http.ResponseWriter, r *http.Request) {
-	handleHelmOperation(w, r, models.Delete, func(releaseName, namespace string) (interface{}, *models.ModelError) {
+	handleHelmOperation(w, r, models.Delete, func(releaseName, namespace string, getActionConfig helm.ActionConfigGetter) (interface{}, *models.ModelError) {
 		timeout := DefaultOperationTimeout
-		completed, err := helm.UninstallHelmRelease(releaseName, namespace, timeout, helm.PrepareActionConfig)
+		completed, err := helm.UninstallHelmRelease(releaseName, namespace, timeout, getActionConfig)
 		if err != nil {
 			return nil, err
 		}
@@ -103,7 +119,7 @@ func UninstallHelmReleaseController(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func handleHelmOperation(w http.ResponseWriter, r *http.Request, opType models.OperationType, operationFunc func(string, string) (interface{}, *models.ModelError)) {
+func handleHelmOperation(w http.ResponseWriter, r *http.Request, opType models.OperationType, operationFunc func(string, string, helm.ActionConfigGetter) (interface{}, *models.ModelError)) {
 	releaseName := getReleaseName(r)
 	namespace := getNamespace(r)
 
@@ -125,7 +141,13 @@ func handleHelmOperation(w http.ResponseWriter, r *http.Request, opType models.O
 		}
 	}
 
-	result, err := operationFunc(releaseName, namespace)
+	getActionConfig, err := getActionConfigGetter(r)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+
+	result, err := operationFunc(releaseName, namespace, getActionConfig)
 	if err != nil {
 		writeJSONResponse(w, int(err.Code), err)
 		return
@@ -1,7 +1,9 @@
 package controllers
 
 import (
+	"context"
 	"fmt"
+	"io"
 
 	"net/http"
 
@@ -9,31 +11,87 @@ import (
 	"github.com/ZPI-2024-25/KubernetesAccessManager/cluster"
 	"github.com/ZPI-2024-25/KubernetesAccessManager/common"
 	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"github.com/golang-jwt/jwt/v4"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func GetResourceController(w http.ResponseWriter, r *http.Request) {
-	handleResourceOperation(w, r, models.Read, func(resourceType, namespace, resourceName string) (interface{}, *models.ModelError) {
-		return cluster.GetResource(resourceType, namespace, resourceName, cluster.GetResourceInterface)
+	handleResourceOperation(w, r, models.Read, func(resourceType, namespace, resourceName string, getResourceInterface cluster.ResourceInterfaceGetter) (interface{}, *models.ModelError) {
+		return cluster.GetResource(resourceType, namespace, resourceName, getResourceInterface)
 	})
 }
 
 func ListResourcesController(w http.ResponseWriter, r *http.Request) {
-	handleResourceOperation(w, r, models.List, func(resourceType, namespace, _ string) (interface{}, *models.ModelError) {
-		if namespace != "" {
-			return cluster.ListResources(resourceType, namespace, cluster.GetResourceInterface)
+	if isWatchRequest(r) {
+		watchResources(w, r)
+		return
+	}
+
+	handleResourceOperation(w, r, models.List, func(resourceType, namespace, _ string, getResourceInterface cluster.ResourceInterfaceGetter) (interface{}, *models.ModelError) {
+		listOptions, err := getListOptions(r)
+		if err != nil {
+			return nil, err
+		}
+		query, err := getResourceListQuery(r, resourceType)
+		if err != nil {
+			return nil, err
+		}
+		if err := cluster.ValidateQueryPagination(query, listOptions); err != nil {
+			return nil, err
+		}
+		// Metrics cannot be watched, so they are never read through the informer cache
+		getUncachedMetricsInterface, err := getUncachedResourceInterfaceGetter(r)
+		if err != nil {
+			return nil, err
 		}
+		// Filtered lists may take several pages, which all share the metrics read for the first one
+		getMetricsInterface := cluster.NewListOnceResourceInterfaceGetter(getUncachedMetricsInterface)
 
-		token, err2 := auth.GetJWTTokenFromHeader(r)
-		isValid, claims := auth.IsTokenValid(token)
+		var resources *models.ResourceList
+		if namespace != "" || !common.UsesRoleMap() {
+			resources, err = listFilteredResources(r.Context(), resourceType, namespace, listOptions, query, getResourceInterface, getMetricsInterface)
+		} else {
+			token, err2 := auth.GetJWTTokenFromHeader(r)
+			isValid, claims := auth.IsTokenValid(token)
 
-		if err2 != nil || !isValid {
-			return nil, &models.ModelError{
-				Message: "Unauthorized",
-				Code:    http.StatusUnauthorized,
+			if err2 != nil || !isValid {
+				return nil, &models.ModelError{
+					Message: "Unauthorized",
+					Code:    http.StatusUnauthorized,
+				}
 			}
+
+			resources, err = listAllowedResources(r.Context(), resourceType, listOptions, query, claims, getResourceInterface, getMetricsInterface)
+		}
+		if err != nil {
+			return nil, err
 		}
 
-		resources, err := cluster.ListResources(resourceType, namespace, cluster.GetResourceInterface)
+		cluster.SortResourceList(resources, query)
+		return resources, nil
+	})
+}
+
+// listFilteredResources lists resources matching the column filters of the query. With a limit set,
+// filtered out resources would leave pages short or empty, so further pages are fetched until
+// the limit is reached. Each request asks only for the missing number of resources, which keeps
+// the continue token in line with the last returned resource.
+func listFilteredResources(ctx context.Context, resourceType string, namespace string, listOptions metav1.ListOptions, query cluster.ResourceListQuery, getResourceInterface cluster.ResourceInterfaceGetter, getMetricsInterface cluster.ResourceInterfaceGetter) (*models.ResourceList, *models.ModelError) {
+	return listPages(listOptions, func(pageOptions metav1.ListOptions) (*models.ResourceList, *models.ModelError) {
+		resources, err := cluster.ListResources(ctx, resourceType, namespace, pageOptions, getResourceInterface, getMetricsInterface)
+		if err != nil {
+			return nil, err
+		}
+		cluster.FilterResourceList(&resources, query)
+		return &resources, nil
+	}, len(query.Filters) > 0)
+}
+
+// listAllowedResources lists resources from all namespaces the user may list, paginated the same way
+// as listFilteredResources.
+func listAllowedResources(ctx context.Context, resourceType string, listOptions metav1.ListOptions, query cluster.ResourceListQuery, claims *jwt.MapClaims, getResourceInterface cluster.ResourceInterfaceGetter, getMetricsInterface cluster.ResourceInterfaceGetter) (*models.ResourceList, *models.ModelError) {
+	return listPages(listOptions, func(pageOptions metav1.ListOptions) (*models.ResourceList, *models.ModelError) {
+		resources, err := cluster.ListResources(ctx, resourceType, "", pageOptions, getResourceInterface, getMetricsInterface)
 		if err != nil {
 			return nil, err
 		}
@@ -41,48 +99,158 @@ func ListResourcesController(w http.ResponseWriter, r *http.Request) {
 		if errM != nil {
 			return nil, errM
 		}
+		cluster.FilterResourceList(filtered, query)
 		return filtered, nil
-	})
+	}, true)
+}
+
+func listPages(listOptions metav1.ListOptions, listPage func(metav1.ListOptions) (*models.ResourceList, *models.ModelError), filtered bool) (*models.ResourceList, *models.ModelError) {
+	limit := listOptions.Limit
+	var result *models.ResourceList
+	for {
+		page, err := listPage(listOptions)
+		if err != nil {
+			return nil, err
+		}
+
+		if result == nil {
+			result = page
+		} else {
+			result.ResourceList = append(result.ResourceList, page.ResourceList...)
+			result.Continue = page.Continue
+		}
+		if filtered {
+			// Remaining item count refers to unfiltered resources, which would mislead the client
+			result.RemainingItemCount = nil
+		}
+
+		if !filtered || limit == 0 || result.Continue == "" || int64(len(result.ResourceList)) >= limit {
+			return result, nil
+		}
+		listOptions.Continue = result.Continue
+		listOptions.Limit = limit - int64(len(result.ResourceList))
+	}
 }
 
 func CreateResourceController(w http.ResponseWriter, r *http.Request) {
-	handleResourceOperation(w, r, models.Create, func(resourceType, namespace, _ string) (interface{}, *models.ModelError) {
+	handleResourceOperation(w, r, models.Create, func(resourceType, namespace, _ string, getResourceInterface cluster.ResourceInterfaceGetter) (interface{}, *models.ModelError) {
 		var resource models.ResourceDetails
-		if !decodeJSONBody(r, &resource.ResourceDetails) {
-			return nil, &models.ModelError{Code: http.StatusBadRequest, Message: "Invalid request body"}
+		if err := decodeResourceBody(r, &resource.ResourceDetails); err != nil {
+			return nil, err
 		}
-		return cluster.CreateResource(resourceType, namespace, resource, cluster.GetResourceInterface)
+		dryRun, err := getDryRunQueryParam(r)
+		if err != nil {
+			return nil, err
+		}
+		return cluster.CreateResource(resourceType, namespace, resource, dryRun, getResourceInterface)
 	})
 }
 
 func DeleteResourceController(w http.ResponseWriter, r *http.Request) {
-	handleResourceOperation(w, r, models.Delete, func(resourceType, namespace, resourceName string) (interface{}, *models.ModelError) {
-		if err := cluster.DeleteResource(resourceType, namespace, resourceName, cluster.GetResourceInterface); err != nil {
+	handleResourceOperation(w, r, models.Delete, func(resourceType, namespace, resourceName string, getResourceInterface cluster.ResourceInterfaceGetter) (interface{}, *models.ModelError) {
+		options, err := getDeleteOptions(r)
+		if err != nil {
+			return nil, err
+		}
+		// Deleting without graceful termination also requires its own operation, on top of delete
+		if options.IsForce() {
+			if err := authenticateAndAuthorize(r, models.Operation{Resource: resourceType, Namespace: namespace, Type: models.ForceDelete}); err != nil {
+				return nil, err
+			}
+		}
+		if err := cluster.DeleteResource(resourceType, namespace, resourceName, options, getResourceInterface); err != nil {
 			return nil, err
 		}
+		message := fmt.Sprintf("Resource %s deleted successfully", resourceName)
+		if options.DryRun {
+			message = fmt.Sprintf("Resource %s would be deleted (dry run)", resourceName)
+		}
 		return models.Status{
 			Status:  "Success",
 			Code:    http.StatusOK,
-			Message: fmt.Sprintf("Resource %s deleted successfully", resourceName),
+			Message: message,
 		}, nil
 	})
 }
 
 func UpdateResourceController(w http.ResponseWriter, r *http.Request) {
-	handleResourceOperation(w, r, models.Update, func(resourceType, namespace, resourceName string) (interface{}, *models.ModelError) {
+	handleResourceOperation(w, r, models.Update, func(resourceType, namespace, resourceName string, getResourceInterface cluster.ResourceInterfaceGetter) (interface{}, *models.ModelError) {
 		var resource models.ResourceDetails
-		if !decodeJSONBody(r, &resource.ResourceDetails) {
+		if err := decodeResourceBody(r, &resource.ResourceDetails); err != nil {
+			return nil, err
+		}
+		dryRun, err := getDryRunQueryParam(r)
+		if err != nil {
+			return nil, err
+		}
+		return cluster.UpdateResource(resourceType, namespace, resourceName, resource, dryRun, getResourceInterface)
+	})
+}
+
+// PatchResourceController applies a patch of the type given by the Content-Type header. Patching changes the
+// resource like an update does, so it is authorized with the update operation.
+func PatchResourceController(w http.ResponseWriter, r *http.Request) {
+	handleResourceOperation(w, r, models.Update, func(resourceType, namespace, resourceName string, getResourceInterface cluster.ResourceInterfaceGetter) (interface{}, *models.ModelError) {
+		patchType, err := getPatchType(r)
+		if err != nil {
+			return nil, err
+		}
+		force, err := getBoolQueryParam(r, "force")
+		if err != nil {
+			return nil, err
+		}
+		dryRun, err := getDryRunQueryParam(r)
+		if err != nil {
+			return nil, err
+		}
+		patch, readErr := io.ReadAll(r.Body)
+		if readErr != nil {
 			return nil, &models.ModelError{Code: http.StatusBadRequest, Message: "Invalid request body"}
 		}
-		return cluster.UpdateResource(resourceType, namespace, resourceName, resource, cluster.GetResourceInterface)
+		return cluster.PatchResource(resourceType, namespace, resourceName, patchType, patch, force, dryRun, getResourceInterface)
 	})
 }
 
-func handleResourceOperation(w http.ResponseWriter, r *http.Request, opType models.OperationType, operationFunc func(string, string, string) (interface{}, *models.ModelError)) {
-	resourceType := getResourceType(r)
+// resourceRequest is an authorized request for an operation on resources of one type.
+type resourceRequest struct {
+	resourceType         string
+	namespace            string
+	resourceName         string
+	getResourceInterface cluster.ResourceInterfaceGetter
+}
+
+func handleResourceOperation(w http.ResponseWriter, r *http.Request, opType models.OperationType, operationFunc func(string, string, string, cluster.ResourceInterfaceGetter) (interface{}, *models.ModelError)) {
+	request, err := prepareResourceOperation(r, opType)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+
+	result, err := operationFunc(request.resourceType, request.namespace, request.resourceName, request.getResourceInterface)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+
+	statusCode := http.StatusOK
+	if opType == models.Create {
+		statusCode = http.StatusCreated
+	}
+
+	writeNegotiatedResponse(w, r, statusCode, result)
+}
+
+// prepareResourceOperation resolves the resource type, authorizes the operation and picks the getter used to reach the cluster.
+func prepareResourceOperation(r *http.Request, opType models.OperationType) (resourceRequest, *models.ModelError) {
 	resourceName := getResourceName(r)
 	namespace := getNamespace(r)
 
+	// Every accepted form of the type is authorized, listed and filtered under its canonical name
+	resourceType, err := cluster.NormalizeResourceType(getResourceType(r))
+	if err != nil {
+		return resourceRequest{}, err
+	}
+
 	if namespace == "" && opType != models.List {
 		namespace = common.DEFAULT_NAMESPACE
 	}
@@ -95,23 +263,35 @@ func handleResourceOperation(w http.ResponseWriter, r *http.Request, opType mode
 		}
 
 		if err := authenticateAndAuthorize(r, operation); err != nil {
-			writeJSONResponse(w, int(err.Code), err)
-			return
+			return resourceRequest{}, err
 		}
 	}
 
-	result, err := operationFunc(resourceType, namespace, resourceName)
+	// Only reads may start informers, writes always go straight to the API server
+	getResourceInterfaceGetterFor := getUncachedResourceInterfaceGetter
+	if opType == models.Read || opType == models.List {
+		getResourceInterfaceGetterFor = getResourceInterfaceGetter
+	}
+	getResourceInterface, err := getResourceInterfaceGetterFor(r)
 	if err != nil {
-		writeJSONResponse(w, int(err.Code), err)
-		return
+		return resourceRequest{}, err
 	}
 
-	statusCode := http.StatusOK
-	if opType == models.Create {
-		statusCode = http.StatusCreated
-	}
+	return resourceRequest{
+		resourceType:         resourceType,
+		namespace:            namespace,
+		resourceName:         resourceName,
+		getResourceInterface: getResourceInterface,
+	}, nil
+}
 
-	writeJSONResponse(w, statusCode, result)
+// authenticateAndAuthorizeFixedType is authenticateAndAuthorize for endpoints bound to one resource type,
+// such as Pod logs or Secret reveal. The type is checked against the allowlist first, like a type given in the path.
+func authenticateAndAuthorizeFixedType(r *http.Request, operation models.Operation) *models.ModelError {
+	if _, err := cluster.NormalizeResourceType(operation.Resource); err != nil {
+		return err
+	}
+	return authenticateAndAuthorize(r, operation)
 }
 
 func authenticateAndAuthorize(r *http.Request, operation models.Operation) *models.ModelError {
@@ -125,6 +305,11 @@ func authenticateAndAuthorize(r *http.Request, operation models.Operation) *mode
 		}
 	}
 
+	// With role map disabled the API server authorizes the impersonated user on its own
+	if !common.UsesRoleMap() {
+		return nil
+	}
+
 	roles, errM := auth.ExtractRoles(claims)
 	if errM != nil {
 		return errM
@@ -146,3 +331,15 @@ func authenticateAndAuthorize(r *http.Request, operation models.Operation) *mode
 	}
 	return nil
 }
+
+// newReadFilter lets through the resources the user may read. Resources are never filtered out with the role map
+// disabled, the API server leaves out what the impersonated user may not see on its own.
+func newReadFilter(r *http.Request) cluster.ResourceFilter {
+	return func(resourceType string, namespace string) (bool, *models.ModelError) {
+		err := authenticateAndAuthorize(r, models.Operation{Resource: resourceType, Namespace: namespace, Type: models.Read})
+		if err != nil && err.Code != http.StatusForbidden {
+			return false, err
+		}
+		return err == nil, nil
+	}
+}
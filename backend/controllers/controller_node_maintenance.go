@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/cluster"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/common"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+)
+
+const (
+	nodeResourceType   = "Node"
+	drainProgressEvent = "progress"
+	drainReportEvent   = "report"
+)
+
+func CordonNodeController(w http.ResponseWriter, r *http.Request) {
+	setNodeSchedulable(w, r, models.Cordon, true, "cordoned")
+}
+
+func UncordonNodeController(w http.ResponseWriter, r *http.Request) {
+	setNodeSchedulable(w, r, models.Uncordon, false, "uncordoned")
+}
+
+func setNodeSchedulable(w http.ResponseWriter, r *http.Request, operationType models.OperationType, unschedulable bool, action string) {
+	getClientset, err := authorizeNodeOperation(r, operationType)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	nodeName := getResourceName(r)
+	if err := cluster.CordonNode(nodeName, unschedulable, getClientset); err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	writeJSONResponse(w, http.StatusOK, models.Status{
+		Status:  "Success",
+		Code:    http.StatusOK,
+		Message: fmt.Sprintf("Node %s %s", nodeName, action),
+	})
+}
+
+// DrainNodeController cordons a Node and evicts its Pods, streaming a "progress" event whenever the state of a Pod
+// changes and a "report" event with the evicted, skipped and failed Pods at the end. Closing the stream or
+// the token expiring only ends the stream, the drain itself goes on until it finishes or times out.
+// A zero grace period force deletes the Pods, so it also requires the forcedelete operation on Pods in
+// the namespace of every Pod to be evicted.
+func DrainNodeController(w http.ResponseWriter, r *http.Request) {
+	getClientset, err := authorizeNodeOperation(r, models.Drain)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	options, err := getDrainOptions(r)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	options.AuthorizeForceEviction = func(namespace string) *models.ModelError {
+		return authenticateAndAuthorizeFixedType(r, models.Operation{Resource: podResourceType, Namespace: namespace, Type: models.ForceDelete})
+	}
+	stream, err := newResponseStream(w, r)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	defer stream.close()
+
+	updates, err := cluster.DrainNode(stream.ctx, getResourceName(r), options, getClientset)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+
+	stream.start("text/event-stream")
+	serveEvents(stream, updates, func(update cluster.DrainUpdate) bool {
+		if update.Report != nil {
+			stream.writeEvent(drainReportEvent, update.Report)
+			return false
+		}
+		stream.writeEvent(drainProgressEvent, update.Progress)
+		return true
+	})
+}
+
+// authorizeNodeOperation checks an operation on Nodes, which are not namespaced, so the role map is consulted
+// for the namespace query parameter or the default namespace like for other resources.
+func authorizeNodeOperation(r *http.Request, operationType models.OperationType) (cluster.ClientsetGetter, *models.ModelError) {
+	namespace := getNamespace(r)
+	if namespace == "" {
+		namespace = common.DEFAULT_NAMESPACE
+	}
+	operation := models.Operation{
+		Resource:  nodeResourceType,
+		Namespace: namespace,
+		Type:      operationType,
+	}
+	if err := authenticateAndAuthorizeFixedType(r, operation); err != nil {
+		return nil, err
+	}
+	return getClientsetGetter(r)
+}
+
+// getDrainOptions reads the gracePeriodSeconds, timeoutSeconds, force and deleteEmptyDirData query parameters.
+func getDrainOptions(r *http.Request) (cluster.DrainOptions, *models.ModelError) {
+	options := cluster.DrainOptions{}
+	if r.URL.Query().Get("gracePeriodSeconds") != "" {
+		gracePeriod, err := getNonNegativeIntQueryParam(r, "gracePeriodSeconds")
+		if err != nil {
+			return options, err
+		}
+		gracePeriodSeconds := int64(gracePeriod)
+		options.GracePeriodSeconds = &gracePeriodSeconds
+	}
+	timeout, err := getNonNegativeIntQueryParam(r, "timeoutSeconds")
+	if err != nil {
+		return options, err
+	}
+	options.Timeout = time.Duration(timeout) * time.Second
+	if options.Force, err = getBoolQueryParam(r, "force"); err != nil {
+		return options, err
+	}
+	if options.DeleteEmptyDirData, err = getBoolQueryParam(r, "deleteEmptyDirData"); err != nil {
+		return options, err
+	}
+	return options, nil
+}
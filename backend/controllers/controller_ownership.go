@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/cluster"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/helm"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+)
+
+// GetOwnershipGraphController returns the owners of a resource and the resources it owns, leaving out those the user may not read.
+func GetOwnershipGraphController(w http.ResponseWriter, r *http.Request) {
+	handleResourceOperation(w, r, models.Read, func(resourceType, namespace, resourceName string, getResourceInterface cluster.ResourceInterfaceGetter) (interface{}, *models.ModelError) {
+		graph, err := cluster.GetOwnershipGraph(resourceType, namespace, resourceName, getResourceInterface, newReadFilter(r))
+		if err != nil {
+			return nil, err
+		}
+		fillHelmReleaseStatus(r, graph.Owners)
+		return graph, nil
+	})
+}
+
+// GetHelmReleaseGraphController returns a Helm release with the trees of the objects it installed.
+func GetHelmReleaseGraphController(w http.ResponseWriter, r *http.Request) {
+	handleHelmOperation(w, r, models.Read, func(releaseName, namespace string, getActionConfig helm.ActionConfigGetter) (interface{}, *models.ModelError) {
+		release, objects, err := helm.GetHelmReleaseObjects(releaseName, namespace, getActionConfig)
+		if err != nil {
+			return nil, err
+		}
+		getResourceInterface, err := getResourceInterfaceGetter(r)
+		if err != nil {
+			return nil, err
+		}
+		children, err := cluster.GetOwnershipTrees(objects, namespace, getResourceInterface, newReadFilter(r))
+		if err != nil {
+			return nil, err
+		}
+		return models.OwnershipNode{
+			Kind:      cluster.HelmReleaseKind,
+			Name:      release.Name,
+			Namespace: release.Namespace,
+			Status:    release.Status,
+			Children:  children,
+		}, nil
+	})
+}
+
+// fillHelmReleaseStatus sets the status of the Helm release among the owners. The status is left empty when the
+// release cannot be read, e.g. it was installed by another tool which only set the annotations.
+func fillHelmReleaseStatus(r *http.Request, owners []models.OwnershipNode) {
+	for i := range owners {
+		if owners[i].Kind != cluster.HelmReleaseKind {
+			continue
+		}
+		getActionConfig, err := getActionConfigGetter(r)
+		if err != nil {
+			return
+		}
+		if release, err := helm.GetHelmRelease(owners[i].Name, owners[i].Namespace, getActionConfig); err == nil {
+			owners[i].Status = release.Status
+		}
+	}
+}
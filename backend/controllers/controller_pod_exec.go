@@ -0,0 +1,280 @@
+package controllers
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/auth"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/cluster"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/common"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/recordings"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// terminalProtocol has to be requested by the client next to the "bearer.<token>" subprotocol
+	terminalProtocol = "kam.v1"
+
+	terminalMessageStdin  = "stdin"
+	terminalMessageResize = "resize"
+	terminalMessageStdout = "stdout"
+	terminalMessageStderr = "stderr"
+	terminalMessageExit   = "exit"
+	terminalMessageError  = "error"
+
+	defaultExecCommand = "/bin/sh"
+
+	terminalPingInterval = 30 * time.Second
+	terminalWriteTimeout = 10 * time.Second
+)
+
+// ExecPodController opens an exec session in a Pod container over a WebSocket. Every message is a JSON
+// models.TerminalMessage. The session is authorized with the exec operation and is closed when the
+// command exits, the client disconnects or the token expires.
+func ExecPodController(w http.ResponseWriter, r *http.Request) {
+	openTerminalSession(w, r, cluster.PodExecSubresource)
+}
+
+// AttachPodController attaches to the main process of a Pod container, the same way ExecPodController
+// runs a command. Attaching is authorized with the exec operation as well.
+func AttachPodController(w http.ResponseWriter, r *http.Request) {
+	openTerminalSession(w, r, cluster.PodAttachSubresource)
+}
+
+// openTerminalSession connects the client to an exec or attach session, recording it when session
+// recording is enabled. A session which can't be recorded is not opened.
+func openTerminalSession(w http.ResponseWriter, r *http.Request, subresource string) {
+	namespace := getNamespace(r)
+	if namespace == "" {
+		namespace = common.DEFAULT_NAMESPACE
+	}
+	operation := models.Operation{
+		Resource:  podResourceType,
+		Namespace: namespace,
+		Type:      models.Exec,
+	}
+	if err := authenticateAndAuthorizeFixedType(r, operation); err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+
+	options, err := getPodExecOptions(r, subresource, namespace, getResourceName(r))
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	claims, err := getClaims(r)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	getConfig, err := getConfigGetter(r)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+
+	var recorder *recordings.Recorder
+	if storage := recordings.GetStorage(); storage != nil {
+		if recorder, err = startSessionRecording(storage, claims, options); err != nil {
+			writeJSONResponse(w, int(err.Code), err)
+			return
+		}
+	}
+
+	conn, upgradeErr := upgradeWebSocket(w, r, terminalProtocol)
+	if upgradeErr != nil {
+		// The upgrader has already responded with an error
+		finishSessionRecording(recorder, nil, &models.ModelError{Code: http.StatusBadRequest, Message: "WebSocket upgrade failed"})
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := withTokenDeadline(r.Context(), claims)
+	defer cancel()
+	session := newTerminalSession(ctx, cancel, conn, recorder)
+	go session.readClientMessages()
+	go session.ping()
+
+	exitCode, err := cluster.ExecInPod(ctx, options, cluster.PodExecStreams{
+		Stdin:             session.stdinReader,
+		Stdout:            &terminalWriter{session: session, messageType: terminalMessageStdout},
+		Stderr:            &terminalWriter{session: session, messageType: terminalMessageStderr},
+		TerminalSizeQueue: session.sizes,
+	}, getConfig)
+	if ctx.Err() == context.DeadlineExceeded {
+		err = &models.ModelError{Code: http.StatusUnauthorized, Message: "Token expired"}
+	}
+
+	if err != nil {
+		session.send(models.TerminalMessage{Type: terminalMessageError, Error: err})
+		finishSessionRecording(recorder, nil, err)
+	} else {
+		session.send(models.TerminalMessage{Type: terminalMessageExit, ExitCode: &exitCode})
+		finishSessionRecording(recorder, &exitCode, nil)
+	}
+	session.close()
+}
+
+func startSessionRecording(storage recordings.Storage, claims *jwt.MapClaims, options cluster.PodExecOptions) (*recordings.Recorder, *models.ModelError) {
+	roles, err := auth.ExtractRoles(claims)
+	if err != nil {
+		return nil, err
+	}
+	recording := models.SessionRecording{
+		User:        auth.ExtractUsername(claims),
+		Roles:       roles,
+		Subresource: options.Subresource,
+		Namespace:   options.Namespace,
+		Pod:         options.PodName,
+		Container:   options.Container,
+		Tty:         options.TTY,
+	}
+	if options.Subresource == cluster.PodExecSubresource {
+		recording.Command = options.Command
+	}
+
+	recorder, recordErr := recordings.StartRecording(storage, recording)
+	if recordErr != nil {
+		log.Printf("Failed to start session recording: %v", recordErr)
+		return nil, &models.ModelError{Code: http.StatusInternalServerError, Message: "Failed to start session recording"}
+	}
+	return recorder, nil
+}
+
+func finishSessionRecording(recorder *recordings.Recorder, exitCode *int, sessionErr *models.ModelError) {
+	if recorder == nil {
+		return
+	}
+	if err := recorder.Finish(exitCode, sessionErr); err != nil {
+		log.Printf("Failed to save session recording %s: %v", recorder.Id(), err)
+	}
+}
+
+type terminalSession struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	conn        *websocket.Conn
+	writeMutex  sync.Mutex
+	stdinReader *io.PipeReader
+	stdinWriter *io.PipeWriter
+	sizes       *cluster.TerminalSizeQueue
+	// recorder is nil when session recording is disabled
+	recorder *recordings.Recorder
+}
+
+func newTerminalSession(ctx context.Context, cancel context.CancelFunc, conn *websocket.Conn,
+	recorder *recordings.Recorder) *terminalSession {
+	stdinReader, stdinWriter := io.Pipe()
+	return &terminalSession{
+		ctx:         ctx,
+		cancel:      cancel,
+		conn:        conn,
+		stdinReader: stdinReader,
+		stdinWriter: stdinWriter,
+		sizes:       cluster.NewTerminalSizeQueue(ctx),
+		recorder:    recorder,
+	}
+}
+
+// readClientMessages passes input and resizes to the session, ending it when the client disconnects.
+func (s *terminalSession) readClientMessages() {
+	defer s.cancel()
+	defer s.stdinWriter.Close()
+	for {
+		var message models.TerminalMessage
+		if err := s.conn.ReadJSON(&message); err != nil {
+			return
+		}
+		switch message.Type {
+		case terminalMessageStdin:
+			if s.recorder != nil {
+				s.recorder.Input([]byte(message.Data))
+			}
+			if _, err := s.stdinWriter.Write([]byte(message.Data)); err != nil {
+				return
+			}
+		case terminalMessageResize:
+			if s.recorder != nil {
+				s.recorder.Resize(message.Cols, message.Rows)
+			}
+			s.sizes.Push(message.Cols, message.Rows)
+		}
+	}
+}
+
+// ping keeps idle sessions from being closed by proxies.
+func (s *terminalSession) ping() {
+	pingPeriodically(s.ctx, s.cancel, s.conn)
+}
+
+// pingPeriodically pings the client until ctx is done, cancelling it when the connection is lost.
+func pingPeriodically(ctx context.Context, cancel context.CancelFunc, conn *websocket.Conn) {
+	ticker := time.NewTicker(terminalPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(terminalWriteTimeout)); err != nil {
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+func (s *terminalSession) send(message models.TerminalMessage) error {
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+	s.conn.SetWriteDeadline(time.Now().Add(terminalWriteTimeout))
+	return s.conn.WriteJSON(message)
+}
+
+func (s *terminalSession) close() {
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+	s.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+		time.Now().Add(terminalWriteTimeout))
+}
+
+// terminalWriter sends the output of a stream as messages of one type.
+type terminalWriter struct {
+	session     *terminalSession
+	messageType string
+	// pending holds the beginning of a UTF-8 character split between two writes
+	pending []byte
+}
+
+func (w *terminalWriter) Write(p []byte) (int, error) {
+	data := append(w.pending, p...)
+	end := len(data)
+	for start := end - 1; start >= 0 && start >= end-utf8.UTFMax; start-- {
+		if utf8.RuneStart(data[start]) {
+			if !utf8.FullRune(data[start:]) {
+				end = start
+			}
+			break
+		}
+	}
+	w.pending = append([]byte(nil), data[end:]...)
+	if end == 0 {
+		return len(p), nil
+	}
+	if w.session.recorder != nil {
+		w.session.recorder.Output(data[:end])
+	}
+
+	if err := w.session.send(models.TerminalMessage{Type: w.messageType, Data: string(data[:end])}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
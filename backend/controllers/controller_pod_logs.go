@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/cluster"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/common"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+)
+
+const (
+	podResourceType = "Pod"
+	logsBufferSize  = 32 * 1024
+)
+
+// GetPodLogsController returns the logs of a Pod container as plain text. It is authorized with the logs
+// operation, so reading logs does not require read access to the Pod. With follow=true the response is
+// streamed until the container stops, the client disconnects or the token expires.
+func GetPodLogsController(w http.ResponseWriter, r *http.Request) {
+	namespace := getNamespace(r)
+	if namespace == "" {
+		namespace = common.DEFAULT_NAMESPACE
+	}
+	operation := models.Operation{
+		Resource:  podResourceType,
+		Namespace: namespace,
+		Type:      models.Logs,
+	}
+	if err := authenticateAndAuthorizeFixedType(r, operation); err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+
+	options, err := getPodLogOptions(r)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	getClientset, err := getClientsetGetter(r)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+
+	podName := getResourceName(r)
+	if !options.Follow {
+		logs, err := cluster.StreamPodLogs(r.Context(), namespace, podName, options, getClientset)
+		if err != nil {
+			writeJSONResponse(w, int(err.Code), err)
+			return
+		}
+		defer logs.Close()
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		io.Copy(w, logs)
+		return
+	}
+
+	stream, err := newResponseStream(w, r)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	defer stream.close()
+	logs, err := cluster.StreamPodLogs(stream.ctx, namespace, podName, options, getClientset)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	defer logs.Close()
+
+	stream.start("text/plain; charset=utf-8")
+	copyToStream(stream, logs)
+}
+
+// copyToStream sends every chunk read from the logs to the client right away.
+func copyToStream(stream *responseStream, logs io.Reader) {
+	buffer := make([]byte, logsBufferSize)
+	for {
+		n, err := logs.Read(buffer)
+		if n > 0 {
+			if writeErr := stream.write(buffer[:n]); writeErr != nil {
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/auth"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/cluster"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/common"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	serviceResourceType = "Service"
+	// portForwardProtocol has to be requested by the client next to the "bearer.<token>" subprotocol
+	portForwardProtocol = "kam.portforward.v1"
+	// maxCloseReasonLength is the limit of a close frame payload without the two bytes of the close code
+	maxCloseReasonLength = 123
+)
+
+var openTunnels = &tunnelCounter{counts: make(map[string]int)}
+
+// PortForwardPodController opens a tunnel to a port of a Pod over a WebSocket. Binary messages carry the raw
+// bytes of a single TCP connection in both directions. The tunnel is authorized with the portforward operation
+// and is closed when either side closes the connection, nothing is sent for the idle timeout or the token expires.
+func PortForwardPodController(w http.ResponseWriter, r *http.Request) {
+	forwardPort(w, r, podResourceType)
+}
+
+// PortForwardServiceController opens a tunnel like PortForwardPodController to a ready Pod behind the Service,
+// connecting to the port of the Pod that the requested port of the Service targets.
+func PortForwardServiceController(w http.ResponseWriter, r *http.Request) {
+	forwardPort(w, r, serviceResourceType)
+}
+
+func forwardPort(w http.ResponseWriter, r *http.Request, resourceType string) {
+	namespace := getNamespace(r)
+	if namespace == "" {
+		namespace = common.DEFAULT_NAMESPACE
+	}
+	operation := models.Operation{
+		Resource:  resourceType,
+		Namespace: namespace,
+		Type:      models.PortForward,
+	}
+	if err := authenticateAndAuthorizeFixedType(r, operation); err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+
+	port, err := getPortQueryParam(r)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	claims, err := getClaims(r)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	getConfig, err := getConfigGetter(r)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+
+	options := cluster.PortForwardOptions{Namespace: namespace, PodName: getResourceName(r), Port: port}
+	if resourceType == serviceResourceType {
+		getClientset, err := getClientsetGetter(r)
+		if err != nil {
+			writeJSONResponse(w, int(err.Code), err)
+			return
+		}
+		options.PodName, options.Port, err = cluster.ResolveServicePod(r.Context(), namespace, options.PodName, port, getClientset)
+		if err != nil {
+			writeJSONResponse(w, int(err.Code), err)
+			return
+		}
+	}
+
+	username := auth.ExtractUsername(claims)
+	if !openTunnels.acquire(username, common.PortForwardMaxTunnelsPerUser) {
+		writeJSONResponse(w, http.StatusTooManyRequests, &models.ModelError{
+			Code:    http.StatusTooManyRequests,
+			Message: fmt.Sprintf("Too many port-forward tunnels, at most %d are allowed per user", common.PortForwardMaxTunnelsPerUser),
+		})
+		return
+	}
+	defer openTunnels.release(username)
+
+	conn, upgradeErr := upgradeWebSocket(w, r, portForwardProtocol)
+	if upgradeErr != nil {
+		// The upgrader has already responded with an error
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := withTokenDeadline(r.Context(), claims)
+	defer cancel()
+	tunnel := newTunnelConn(conn, cancel, time.Duration(common.PortForwardIdleTimeout)*time.Second)
+	defer tunnel.idleTimer.Stop()
+	go pingPeriodically(ctx, cancel, conn)
+
+	err = cluster.ForwardPort(ctx, options, tunnel, getConfig)
+	switch {
+	case tunnel.idle.Load():
+		tunnel.close(websocket.CloseGoingAway, "Idle timeout")
+	case ctx.Err() == context.DeadlineExceeded:
+		tunnel.close(websocket.ClosePolicyViolation, "Token expired")
+	case err != nil:
+		tunnel.close(websocket.CloseInternalServerErr, err.Message)
+	default:
+		tunnel.close(websocket.CloseNormalClosure, "")
+	}
+}
+
+// tunnelCounter counts open tunnels of every user.
+type tunnelCounter struct {
+	mutex  sync.Mutex
+	counts map[string]int
+}
+
+// acquire registers a new tunnel of the user unless the user already has the limit of tunnels open, 0 means no limit.
+func (c *tunnelCounter) acquire(username string, limit int) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if limit > 0 && c.counts[username] >= limit {
+		return false
+	}
+	c.counts[username]++
+	return true
+}
+
+func (c *tunnelCounter) release(username string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.counts[username]--
+	if c.counts[username] <= 0 {
+		delete(c.counts, username)
+	}
+}
+
+// tunnelConn reads and writes the data of a tunnel as binary WebSocket messages, cancelling the tunnel
+// when no data is sent in either direction for the idle timeout.
+type tunnelConn struct {
+	conn        *websocket.Conn
+	message     io.Reader
+	idleTimeout time.Duration
+	idleTimer   *time.Timer
+	idle        atomic.Bool
+}
+
+func newTunnelConn(conn *websocket.Conn, cancel context.CancelFunc, idleTimeout time.Duration) *tunnelConn {
+	tunnel := &tunnelConn{conn: conn, idleTimeout: idleTimeout}
+	tunnel.idleTimer = time.AfterFunc(idleTimeout, func() {
+		tunnel.idle.Store(true)
+		cancel()
+	})
+	return tunnel
+}
+
+// Read returns io.EOF once the client closes the connection, so the Pod is told that no more data follows.
+func (t *tunnelConn) Read(p []byte) (int, error) {
+	for {
+		if t.message == nil {
+			messageType, message, err := t.conn.NextReader()
+			if err != nil {
+				return 0, io.EOF
+			}
+			if messageType != websocket.BinaryMessage {
+				continue
+			}
+			t.message = message
+		}
+
+		n, err := t.message.Read(p)
+		if n > 0 {
+			t.idleTimer.Reset(t.idleTimeout)
+		}
+		if err == io.EOF {
+			t.message = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (t *tunnelConn) Write(p []byte) (int, error) {
+	t.conn.SetWriteDeadline(time.Now().Add(terminalWriteTimeout))
+	if err := t.conn.WriteMessage(websocket.BinaryMessage, p); err != nil {
+		return 0, err
+	}
+	t.idleTimer.Reset(t.idleTimeout)
+	return len(p), nil
+}
+
+func (t *tunnelConn) close(code int, reason string) {
+	if len(reason) > maxCloseReasonLength {
+		reason = reason[:maxCloseReasonLength]
+	}
+	t.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason),
+		time.Now().Add(terminalWriteTimeout))
+}
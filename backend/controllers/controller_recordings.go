@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/auth"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/cluster"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/common"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/recordings"
+	"github.com/golang-jwt/jwt/v4"
+	authorizationv1 "k8s.io/api/authorization/v1"
+)
+
+const (
+	asciicastContentType = "application/x-asciicast"
+	// recordingsSubresource is checked in RBAC as "pods/recordings", it is not served by the API server
+	recordingsSubresource = "recordings"
+)
+
+// ListRecordingsController lists recorded sessions in namespaces where the user may view recordings,
+// optionally only in the namespace given as a query parameter.
+func ListRecordingsController(w http.ResponseWriter, r *http.Request) {
+	claims, storage, err := prepareRecordingsRequest(r)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+
+	allRecordings, listErr := storage.ListMetadata()
+	if listErr != nil {
+		log.Printf("Failed to list session recordings: %v", listErr)
+		writeJSONResponse(w, http.StatusInternalServerError, &models.ModelError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to list session recordings",
+		})
+		return
+	}
+
+	namespace := getNamespace(r)
+	allowedNamespaces := make(map[string]bool)
+	visibleRecordings := make([]models.SessionRecording, 0)
+	for _, recording := range allRecordings {
+		if namespace != "" && recording.Namespace != namespace {
+			continue
+		}
+		allowed, checked := allowedNamespaces[recording.Namespace]
+		if !checked {
+			if allowed, err = isRecordingNamespaceAllowed(r, claims, recording.Namespace); err != nil {
+				writeJSONResponse(w, int(err.Code), err)
+				return
+			}
+			allowedNamespaces[recording.Namespace] = allowed
+		}
+		if allowed {
+			visibleRecordings = append(visibleRecordings, recording)
+		}
+	}
+	writeJSONResponse(w, http.StatusOK, visibleRecordings)
+}
+
+func GetRecordingController(w http.ResponseWriter, r *http.Request) {
+	_, recording, err := getAuthorizedRecording(r)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	writeJSONResponse(w, http.StatusOK, recording)
+}
+
+// GetRecordingCastController returns the recorded session in asciicast v2 format, which can be replayed
+// e.g. with asciinema-player. With download=true the browser saves it as a file.
+func GetRecordingCastController(w http.ResponseWriter, r *http.Request) {
+	storage, recording, err := getAuthorizedRecording(r)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	download, err := getBoolQueryParam(r, "download")
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+
+	cast, openErr := storage.Open(recording.Id)
+	if openErr != nil {
+		err = getRecordingStorageError(openErr)
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	defer cast.Close()
+
+	w.Header().Set("Content-Type", asciicastContentType)
+	if download {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", recording.Id+".cast"))
+	}
+	if _, copyErr := io.Copy(w, cast); copyErr != nil {
+		log.Printf("Failed to send session recording %s: %v", recording.Id, copyErr)
+	}
+}
+
+func getRecordingId(r *http.Request) string {
+	return getPathVar(r, "recordingId")
+}
+
+func prepareRecordingsRequest(r *http.Request) (*jwt.MapClaims, recordings.Storage, *models.ModelError) {
+	claims, err := getClaims(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Recordings belong to Pods, so they are not served when the allowlist excludes Pods
+	if _, err := cluster.NormalizeResourceType(podResourceType); err != nil {
+		return nil, nil, err
+	}
+	storage := recordings.GetStorage()
+	if storage == nil {
+		return nil, nil, &models.ModelError{Code: http.StatusNotFound, Message: "Session recording is disabled"}
+	}
+	return claims, storage, nil
+}
+
+func getAuthorizedRecording(r *http.Request) (recordings.Storage, models.SessionRecording, *models.ModelError) {
+	claims, storage, err := prepareRecordingsRequest(r)
+	if err != nil {
+		return nil, models.SessionRecording{}, err
+	}
+
+	recording, getErr := storage.GetMetadata(getRecordingId(r))
+	if getErr != nil {
+		return nil, models.SessionRecording{}, getRecordingStorageError(getErr)
+	}
+	allowed, err := isRecordingNamespaceAllowed(r, claims, recording.Namespace)
+	if err != nil {
+		return nil, models.SessionRecording{}, err
+	}
+	if !allowed {
+		return nil, models.SessionRecording{}, &models.ModelError{
+			Code:    http.StatusForbidden,
+			Message: "Insufficient permissions",
+		}
+	}
+	return storage, recording, nil
+}
+
+// isRecordingNamespaceAllowed checks the recordings operation on Pods in the namespace in the role map and,
+// when users are impersonated, asks the API server whether the user may get the "pods/recordings" subresource.
+func isRecordingNamespaceAllowed(r *http.Request, claims *jwt.MapClaims, namespace string) (bool, *models.ModelError) {
+	if common.UsesRoleMap() {
+		allowed, err := auth.IsNamespaceAllowed(claims, podResourceType, namespace, models.Recordings)
+		if err != nil || !allowed {
+			return false, err
+		}
+	}
+	if !common.UsesImpersonation() {
+		return true, nil
+	}
+
+	getClientset, err := getClientsetGetter(r)
+	if err != nil {
+		return false, err
+	}
+	return cluster.IsActionAllowed(r.Context(), authorizationv1.ResourceAttributes{
+		Namespace:   namespace,
+		Verb:        "get",
+		Resource:    "pods",
+		Subresource: recordingsSubresource,
+	}, getClientset)
+}
+
+func getRecordingStorageError(err error) *models.ModelError {
+	if errors.Is(err, recordings.ErrNotFound) {
+		return &models.ModelError{Code: http.StatusNotFound, Message: "Recording not found"}
+	}
+	log.Printf("Failed to read session recording: %v", err)
+	return &models.ModelError{Code: http.StatusInternalServerError, Message: "Failed to read session recording"}
+}
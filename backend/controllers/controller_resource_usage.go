@@ -0,0 +1,19 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/cluster"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+)
+
+// GetResourceUsageController returns the CPU and memory usage of a Pod or a Node, which requires the read operation.
+func GetResourceUsageController(w http.ResponseWriter, r *http.Request) {
+	handleResourceOperation(w, r, models.Read, func(resourceType, namespace, resourceName string, getResourceInterface cluster.ResourceInterfaceGetter) (interface{}, *models.ModelError) {
+		getMetricsInterface, err := getUncachedResourceInterfaceGetter(r)
+		if err != nil {
+			return nil, err
+		}
+		return cluster.GetResourceUsage(r.Context(), resourceType, namespace, resourceName, getResourceInterface, getMetricsInterface)
+	})
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/auth"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/cluster"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/common"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"github.com/golang-jwt/jwt/v4"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const (
+	watchHeartbeatInterval = 30 * time.Second
+	watchListEvent         = "list"
+	watchErrorEvent        = "error"
+)
+
+func isWatchRequest(r *http.Request) bool {
+	return r.URL.Query().Get("watch") == "true"
+}
+
+// watchResources streams a resource list as Server-Sent Events: a "list" event with the current
+// resources, then ADDED, MODIFIED and DELETED events with single rows. Every event is checked
+// against the role map again, and the stream ends with an "error" event when the token expires.
+func watchResources(w http.ResponseWriter, r *http.Request) {
+	request, err := prepareResourceOperation(r, models.List)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	claims, err := getClaims(r)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	listOptions, err := getListOptions(r)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	query, err := getResourceListQuery(r, request.resourceType)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	stream, err := newResponseStream(w, r)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	defer stream.close()
+	ctx := stream.ctx
+
+	// The whole list is sent at once, pagination does not apply to watches
+	listOptions.Limit = 0
+	listOptions.Continue = ""
+	// Usage changes without events, so watched rows are sent without it
+	var resources *models.ResourceList
+	if request.namespace != "" || !common.UsesRoleMap() {
+		resources, err = listFilteredResources(ctx, request.resourceType, request.namespace, listOptions, query, request.getResourceInterface, nil)
+	} else {
+		resources, err = listAllowedResources(ctx, request.resourceType, listOptions, query, claims, request.getResourceInterface, nil)
+	}
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	cluster.SortResourceList(resources, query)
+
+	listOptions.ResourceVersion = resources.ResourceVersion
+	events, err := cluster.WatchResources(ctx, request.resourceType, request.namespace, listOptions, request.getResourceInterface)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+
+	stream.start("text/event-stream")
+	stream.writeEvent(watchListEvent, resources)
+
+	serveEvents(stream, events, func(event models.ResourceEvent) bool {
+		if event.Type == cluster.WatchEventError {
+			stream.writeEvent(watchErrorEvent, event.Error)
+			return false
+		}
+
+		visible, errM := isWatchEventVisible(event, request.resourceType, claims)
+		if errM != nil {
+			stream.writeEvent(watchErrorEvent, errM)
+			return false
+		}
+		if !visible {
+			return true
+		}
+		// A resource changed so that it no longer passes the filters leaves the client's list
+		if !cluster.MatchesResourceListQuery(event.Resource, query) {
+			event.Type = string(watch.Deleted)
+		}
+		stream.writeEvent(event.Type, event)
+		return true
+	})
+}
+
+// isWatchEventVisible checks the event against the current role map, which may have changed since the watch started.
+func isWatchEventVisible(event models.ResourceEvent, resourceType string, claims *jwt.MapClaims) (bool, *models.ModelError) {
+	if !common.UsesRoleMap() {
+		return true, nil
+	}
+	return auth.IsNamespaceAllowed(claims, resourceType, event.Resource.Namespace, models.List)
+}
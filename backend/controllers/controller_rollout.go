@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/cluster"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+)
+
+const rolloutStatusEvent = "status"
+
+func RestartRolloutController(w http.ResponseWriter, r *http.Request) {
+	handleResourceOperation(w, r, models.Restart, func(resourceType, namespace, resourceName string, getResourceInterface cluster.ResourceInterfaceGetter) (interface{}, *models.ModelError) {
+		if err := cluster.RestartRollout(resourceType, namespace, resourceName, getResourceInterface); err != nil {
+			return nil, err
+		}
+		return rolloutActionStatus(fmt.Sprintf("Resource %s restarted", resourceName)), nil
+	})
+}
+
+func PauseRolloutController(w http.ResponseWriter, r *http.Request) {
+	handleResourceOperation(w, r, models.Pause, func(resourceType, namespace, resourceName string, getResourceInterface cluster.ResourceInterfaceGetter) (interface{}, *models.ModelError) {
+		if err := cluster.PauseRollout(resourceType, namespace, resourceName, getResourceInterface); err != nil {
+			return nil, err
+		}
+		return rolloutActionStatus(fmt.Sprintf("Resource %s paused", resourceName)), nil
+	})
+}
+
+func ResumeRolloutController(w http.ResponseWriter, r *http.Request) {
+	handleResourceOperation(w, r, models.Resume, func(resourceType, namespace, resourceName string, getResourceInterface cluster.ResourceInterfaceGetter) (interface{}, *models.ModelError) {
+		if err := cluster.ResumeRollout(resourceType, namespace, resourceName, getResourceInterface); err != nil {
+			return nil, err
+		}
+		return rolloutActionStatus(fmt.Sprintf("Resource %s resumed", resourceName)), nil
+	})
+}
+
+// UndoRolloutController rolls a workload back to the revision from the revision query parameter, or to the
+// revision before the current one when it is missing.
+func UndoRolloutController(w http.ResponseWriter, r *http.Request) {
+	handleResourceOperation(w, r, models.Undo, func(resourceType, namespace, resourceName string, getResourceInterface cluster.ResourceInterfaceGetter) (interface{}, *models.ModelError) {
+		revision, err := getNonNegativeIntQueryParam(r, "revision")
+		if err != nil {
+			return nil, err
+		}
+		getClientset, err := getClientsetGetter(r)
+		if err != nil {
+			return nil, err
+		}
+		result, err := cluster.UndoRollout(resourceType, namespace, resourceName, int64(revision), getResourceInterface, getClientset)
+		if err != nil {
+			return nil, err
+		}
+		return rolloutActionStatus(fmt.Sprintf("Resource %s %s", resourceName, result)), nil
+	})
+}
+
+// GetRolloutStatusController reports the progress of a rollout, which only requires the read operation.
+// With watch=true the status is streamed as Server-Sent Events until the rollout is done or fails.
+func GetRolloutStatusController(w http.ResponseWriter, r *http.Request) {
+	if isWatchRequest(r) {
+		watchRolloutStatus(w, r)
+		return
+	}
+	handleResourceOperation(w, r, models.Read, func(resourceType, namespace, resourceName string, getResourceInterface cluster.ResourceInterfaceGetter) (interface{}, *models.ModelError) {
+		revision, err := getNonNegativeIntQueryParam(r, "revision")
+		if err != nil {
+			return nil, err
+		}
+		return cluster.GetRolloutStatus(resourceType, namespace, resourceName, int64(revision), getResourceInterface)
+	})
+}
+
+// watchRolloutStatus sends a "status" event every time the rollout progresses. The stream ends after the
+// event of a finished or failed rollout, or with an "error" event when the watch fails or the token expires.
+func watchRolloutStatus(w http.ResponseWriter, r *http.Request) {
+	request, err := prepareResourceOperation(r, models.Read)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	revision, err := getNonNegativeIntQueryParam(r, "revision")
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	stream, err := newResponseStream(w, r)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	defer stream.close()
+
+	updates, err := cluster.WatchRolloutStatus(stream.ctx, request.resourceType, request.namespace, request.resourceName, int64(revision), request.getResourceInterface)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+
+	stream.start("text/event-stream")
+	serveEvents(stream, updates, func(update cluster.RolloutStatusUpdate) bool {
+		if update.Error != nil {
+			stream.writeEvent(watchErrorEvent, update.Error)
+			return false
+		}
+		stream.writeEvent(rolloutStatusEvent, update.Status)
+		return true
+	})
+}
+
+func rolloutActionStatus(message string) models.Status {
+	return models.Status{
+		Status:  "Success",
+		Code:    http.StatusOK,
+		Message: message,
+	}
+}
@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/auth"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/cluster"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/common"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+)
+
+// GetScaleController reads the replica count of a workload, which only requires permission to read it.
+func GetScaleController(w http.ResponseWriter, r *http.Request) {
+	handleResourceOperation(w, r, models.Read, func(resourceType, namespace, resourceName string, getResourceInterface cluster.ResourceInterfaceGetter) (interface{}, *models.ModelError) {
+		if err := checkScaleSupported(resourceType); err != nil {
+			return nil, err
+		}
+		return cluster.GetScale(resourceType, namespace, resourceName, getResourceInterface)
+	})
+}
+
+// UpdateScaleController sets the replica count of a workload. With the role map enabled the count
+// has to be within the replica limits of the roles of the user for the namespace and resource type.
+func UpdateScaleController(w http.ResponseWriter, r *http.Request) {
+	handleResourceOperation(w, r, models.Scale, func(resourceType, namespace, resourceName string, getResourceInterface cluster.ResourceInterfaceGetter) (interface{}, *models.ModelError) {
+		var body models.ResourceScaleBody
+		if !decodeJSONBody(r, &body) {
+			return nil, &models.ModelError{Code: http.StatusBadRequest, Message: "Invalid request body"}
+		}
+		if body.Replicas == nil || *body.Replicas < 0 {
+			return nil, &models.ModelError{Code: http.StatusBadRequest, Message: "Replicas must be a non-negative integer"}
+		}
+		if err := checkScaleSupported(resourceType); err != nil {
+			return nil, err
+		}
+		if err := checkReplicaLimits(r, resourceType, namespace, *body.Replicas); err != nil {
+			return nil, err
+		}
+		return cluster.UpdateScale(resourceType, namespace, resourceName, *body.Replicas, body.ResourceVersion, getResourceInterface)
+	})
+}
+
+func checkScaleSupported(resourceType string) *models.ModelError {
+	supported, err := cluster.SupportsScale(resourceType)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return &models.ModelError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Resource type %s does not support scaling", resourceType),
+		}
+	}
+	return nil
+}
+
+func checkReplicaLimits(r *http.Request, resourceType string, namespace string, replicas int32) *models.ModelError {
+	if !common.UsesRoleMap() {
+		return nil
+	}
+	claims, err := getClaims(r)
+	if err != nil {
+		return err
+	}
+	roles, err := auth.ExtractRoles(claims)
+	if err != nil {
+		return err
+	}
+
+	operation := models.Operation{
+		Resource:  resourceType,
+		Namespace: namespace,
+		Type:      models.Scale,
+	}
+	allowed, limitErr := auth.IsReplicaCountAllowed(operation, roles, replicas)
+	if limitErr != nil {
+		return &models.ModelError{
+			Code:    http.StatusInternalServerError,
+			Message: "Internal Server Error: " + limitErr.Error(),
+		}
+	}
+	if !allowed {
+		return &models.ModelError{
+			Code:    http.StatusForbidden,
+			Message: fmt.Sprintf("Replica count %d is outside the limits allowed for your roles", replicas),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/audit"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/auth"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/cluster"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/common"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+)
+
+const secretResourceType = "Secret"
+
+// RevealSecretController returns the decoded values of the keys of a Secret given by the key query parameters,
+// or of all its keys. It is authorized with the reveal operation, as Secret details only show masked values.
+// Every reveal is recorded in the audit trail before the values are returned.
+func RevealSecretController(w http.ResponseWriter, r *http.Request) {
+	namespace := getNamespace(r)
+	if namespace == "" {
+		namespace = common.DEFAULT_NAMESPACE
+	}
+	operation := models.Operation{
+		Resource:  secretResourceType,
+		Namespace: namespace,
+		Type:      models.Reveal,
+	}
+	if err := authenticateAndAuthorizeFixedType(r, operation); err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	claims, err := getClaims(r)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	getClientset, err := getClientsetGetter(r)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+
+	reveal, err := cluster.RevealSecret(namespace, getResourceName(r), r.URL.Query()["key"], getClientset)
+	if err != nil {
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+
+	keys := make([]string, 0, len(reveal.Data))
+	for key := range reveal.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	entry := models.AuditEntry{
+		Time:      time.Now().UTC(),
+		User:      auth.ExtractUsername(claims),
+		Action:    audit.ActionReveal,
+		Resource:  secretResourceType,
+		Namespace: namespace,
+		Name:      reveal.Name,
+		Keys:      keys,
+	}
+	// Values are never returned without a trace of who saw them
+	if recordErr := audit.GetTrail().Record(entry); recordErr != nil {
+		err = &models.ModelError{Code: http.StatusInternalServerError, Message: "Failed to write audit trail: " + recordErr.Error()}
+		writeJSONResponse(w, int(err.Code), err)
+		return
+	}
+	writeNegotiatedResponse(w, r, http.StatusOK, reveal)
+}
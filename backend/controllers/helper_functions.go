@@ -1,36 +1,273 @@
 package controllers
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/auth"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/cluster"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/common"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/helm"
 	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/gorilla/mux"
-	"net/http"
+	"github.com/gorilla/websocket"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
 )
 
 func setJSONContentType(w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 }
 
+// getPathVar returns the unescaped path variable. The router matches encoded paths,
+// so that e.g. "apps%2Fv1%2FDeployment" is a single resourceType segment.
+func getPathVar(r *http.Request, name string) string {
+	value := mux.Vars(r)[name]
+	if unescaped, err := url.PathUnescape(value); err == nil {
+		return unescaped
+	}
+	return value
+}
+
 func getResourceType(r *http.Request) string {
-	return mux.Vars(r)["resourceType"]
+	return getPathVar(r, "resourceType")
 }
 
 func getResourceName(r *http.Request) string {
-	return mux.Vars(r)["resourceName"]
+	return getPathVar(r, "resourceName")
 }
 
 func getNamespace(r *http.Request) string {
 	return r.URL.Query().Get("namespace")
 }
 
+// getListOptions reads the pagination and selector query parameters of a resource list request.
+func getListOptions(r *http.Request) (metav1.ListOptions, *models.ModelError) {
+	limit, err := getNonNegativeIntQueryParam(r, "limit")
+	if err != nil {
+		return metav1.ListOptions{}, err
+	}
+	listOptions := metav1.ListOptions{
+		Limit:         int64(limit),
+		Continue:      r.URL.Query().Get("continue"),
+		LabelSelector: r.URL.Query().Get("labelSelector"),
+		FieldSelector: r.URL.Query().Get("fieldSelector"),
+	}
+	if err := cluster.ValidateSelectors(listOptions); err != nil {
+		return metav1.ListOptions{}, err
+	}
+	return listOptions, nil
+}
+
+// getResourceListQuery reads the sortBy, order and repeated filter query parameters of a resource list request.
+func getResourceListQuery(r *http.Request, resourceType string) (cluster.ResourceListQuery, *models.ModelError) {
+	query := r.URL.Query()
+	return cluster.ParseResourceListQuery(resourceType, query.Get("sortBy"), query.Get("order"), query["filter"])
+}
+
+// isConsistentRequest reports whether the request must bypass the informer cache and read from the API server.
+func isConsistentRequest(r *http.Request) bool {
+	return r.URL.Query().Get("consistent") == "true"
+}
+
+// getOffsetPagination reads the offset and limit query parameters, a zero limit means no limit.
+func getOffsetPagination(r *http.Request) (int, int, *models.ModelError) {
+	offset, err := getNonNegativeIntQueryParam(r, "offset")
+	if err != nil {
+		return 0, 0, err
+	}
+	limit, err := getNonNegativeIntQueryParam(r, "limit")
+	if err != nil {
+		return 0, 0, err
+	}
+	return offset, limit, nil
+}
+
+func getNonNegativeIntQueryParam(r *http.Request, name string) (int, *models.ModelError) {
+	valueStr := r.URL.Query().Get(name)
+	if valueStr == "" {
+		return 0, nil
+	}
+	value, err := strconv.Atoi(valueStr)
+	if err != nil || value < 0 {
+		return 0, &models.ModelError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid %s: %s", name, valueStr)}
+	}
+	return value, nil
+}
+
+// getBoolQueryParam reads an optional boolean query parameter, which is false when missing.
+func getBoolQueryParam(r *http.Request, name string) (bool, *models.ModelError) {
+	valueStr := r.URL.Query().Get(name)
+	if valueStr == "" {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return false, &models.ModelError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid %s: %s", name, valueStr)}
+	}
+	return value, nil
+}
+
+// getPodLogOptions reads the container, tailLines, sinceSeconds, previous, timestamps and follow query parameters.
+func getPodLogOptions(r *http.Request) (*corev1.PodLogOptions, *models.ModelError) {
+	options := &corev1.PodLogOptions{Container: r.URL.Query().Get("container")}
+
+	if r.URL.Query().Has("tailLines") {
+		tailLines, err := getNonNegativeIntQueryParam(r, "tailLines")
+		if err != nil {
+			return nil, err
+		}
+		options.TailLines = ptr.To(int64(tailLines))
+	}
+	sinceSeconds, err := getNonNegativeIntQueryParam(r, "sinceSeconds")
+	if err != nil {
+		return nil, err
+	}
+	if sinceSeconds > 0 {
+		options.SinceSeconds = ptr.To(int64(sinceSeconds))
+	}
+
+	if options.Previous, err = getBoolQueryParam(r, "previous"); err != nil {
+		return nil, err
+	}
+	if options.Timestamps, err = getBoolQueryParam(r, "timestamps"); err != nil {
+		return nil, err
+	}
+	if options.Follow, err = getBoolQueryParam(r, "follow"); err != nil {
+		return nil, err
+	}
+	return options, nil
+}
+
+// getPodExecOptions reads the container, repeated command and tty query parameters of an exec or attach session.
+func getPodExecOptions(r *http.Request, subresource string, namespace string, podName string) (cluster.PodExecOptions, *models.ModelError) {
+	command := r.URL.Query()["command"]
+	if len(command) == 0 {
+		command = []string{defaultExecCommand}
+	}
+	tty, err := getBoolQueryParam(r, "tty")
+	if err != nil {
+		return cluster.PodExecOptions{}, err
+	}
+	return cluster.PodExecOptions{
+		Subresource: subresource,
+		Namespace:   namespace,
+		PodName:     podName,
+		Container:   r.URL.Query().Get("container"),
+		Command:     command,
+		TTY:         tty,
+	}, nil
+}
+
+// getPortQueryParam reads the required port query parameter of a port-forward tunnel.
+func getPortQueryParam(r *http.Request) (int32, *models.ModelError) {
+	valueStr := r.URL.Query().Get("port")
+	value, err := strconv.ParseInt(valueStr, 10, 32)
+	if err != nil || value < 1 || value > 65535 {
+		return 0, &models.ModelError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid port: %s", valueStr)}
+	}
+	return int32(value), nil
+}
+
+// getDryRunQueryParam reads the dryRun query parameter, which like in the Kubernetes API can only be All.
+func getDryRunQueryParam(r *http.Request) (bool, *models.ModelError) {
+	switch dryRun := r.URL.Query().Get("dryRun"); dryRun {
+	case "":
+		return false, nil
+	case metav1.DryRunAll:
+		return true, nil
+	default:
+		return false, &models.ModelError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Invalid dryRun: %s, the only supported value is %s", dryRun, metav1.DryRunAll),
+		}
+	}
+}
+
+// getDeleteOptions reads the propagationPolicy, gracePeriodSeconds, preconditionUid and preconditionResourceVersion
+// query parameters of a delete request, together with dryRun.
+func getDeleteOptions(r *http.Request) (cluster.DeleteOptions, *models.ModelError) {
+	dryRun, err := getDryRunQueryParam(r)
+	if err != nil {
+		return cluster.DeleteOptions{}, err
+	}
+	query := r.URL.Query()
+	options := cluster.DeleteOptions{
+		DryRun:            dryRun,
+		PropagationPolicy: query.Get("propagationPolicy"),
+		Uid:               query.Get("preconditionUid"),
+		ResourceVersion:   query.Get("preconditionResourceVersion"),
+	}
+	if query.Get("gracePeriodSeconds") != "" {
+		gracePeriod, err := getNonNegativeIntQueryParam(r, "gracePeriodSeconds")
+		if err != nil {
+			return cluster.DeleteOptions{}, err
+		}
+		gracePeriodSeconds := int64(gracePeriod)
+		options.GracePeriodSeconds = &gracePeriodSeconds
+	}
+	return options, nil
+}
+
+// getPatchType maps the media type of the request body to the patch type, ignoring parameters such as the charset.
+func getPatchType(r *http.Request) (types.PatchType, *models.ModelError) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err == nil {
+		switch patchType := types.PatchType(mediaType); patchType {
+		case types.StrategicMergePatchType, types.MergePatchType, types.JSONPatchType, types.ApplyPatchType:
+			return patchType, nil
+		}
+	}
+	return "", &models.ModelError{
+		Code: http.StatusUnsupportedMediaType,
+		Message: fmt.Sprintf("Unsupported patch content type, expected one of %s, %s, %s or %s",
+			types.StrategicMergePatchType, types.MergePatchType, types.JSONPatchType, types.ApplyPatchType),
+	}
+}
+
 func getReleaseName(r *http.Request) string {
-	return mux.Vars(r)["releaseName"]
+	return getPathVar(r, "releaseName")
+}
+
+// websocketUpgrader is shared by all WebSocket endpoints, which negotiate their own subprotocols with upgradeWebSocket.
+var websocketUpgrader = websocket.Upgrader{
+	// Tokens are never sent in cookies, so any origin is accepted, as with the CORS policy of the API
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// upgradeWebSocket upgrades the request to a WebSocket, selecting the subprotocol of the endpoint when the client
+// requests it. On failure the upgrader has already responded with an error.
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request, subprotocol string) (*websocket.Conn, error) {
+	var responseHeader http.Header
+	if slices.Contains(websocket.Subprotocols(r), subprotocol) {
+		responseHeader = http.Header{"Sec-Websocket-Protocol": []string{subprotocol}}
+	}
+	return websocketUpgrader.Upgrade(w, r, responseHeader)
 }
 
 func writeJSONResponse(w http.ResponseWriter, statusCode int, data interface{}) {
 	if w.Header().Get("Content-Type") == "" {
 		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	}
+	// Like the API server, tell clients when a throttled or timed out request may be retried
+	if modelErr, ok := data.(*models.ModelError); ok && modelErr.RetryAfterSeconds > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(modelErr.RetryAfterSeconds)))
+	}
 	if statusCode != http.StatusOK {
 		w.WriteHeader(statusCode)
 	}
@@ -48,9 +285,261 @@ func decodeJSONBody(r *http.Request, dst interface{}) bool {
 	return true
 }
 
+// decodeResourceBody decodes a resource sent as JSON or, with a YAML Content-Type, as a single YAML document.
+func decodeResourceBody(r *http.Request, dst interface{}) *models.ModelError {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if !isYAMLMediaType(mediaType) {
+		if !decodeJSONBody(r, dst) {
+			return &models.ModelError{Code: http.StatusBadRequest, Message: "Invalid request body"}
+		}
+		return nil
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return &models.ModelError{Code: http.StatusBadRequest, Message: "Invalid request body"}
+	}
+	documents, errM := decodeYAMLDocuments(body)
+	if errM != nil {
+		return errM
+	}
+	if len(documents) != 1 {
+		return &models.ModelError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Expected a single YAML document, found %d", len(documents)),
+		}
+	}
+	// Decoding the JSON form gives the same types as a JSON body, e.g. float64 for all numbers
+	content, err := json.Marshal(documents[0])
+	if err != nil {
+		return &models.ModelError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid YAML document: %s", err)}
+	}
+	if err := json.Unmarshal(content, dst); err != nil {
+		return &models.ModelError{Code: http.StatusBadRequest, Message: "Invalid request body"}
+	}
+	return nil
+}
+
+// decodeManifestBody decodes the objects of a manifest sent as a YAML stream of documents or, with a JSON
+// Content-Type, as a single JSON object, which may be a List.
+func decodeManifestBody(r *http.Request) ([]interface{}, *models.ModelError) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, &models.ModelError{Code: http.StatusBadRequest, Message: "Invalid request body"}
+	}
+
+	var documents []interface{}
+	if mediaType == "application/json" {
+		var document interface{}
+		if err := json.Unmarshal(body, &document); err != nil {
+			return nil, &models.ModelError{Code: http.StatusBadRequest, Message: "Invalid request body"}
+		}
+		documents = []interface{}{document}
+	} else {
+		var errM *models.ModelError
+		if documents, errM = decodeYAMLDocuments(body); errM != nil {
+			return nil, errM
+		}
+	}
+	if len(documents) == 0 {
+		return nil, &models.ModelError{Code: http.StatusBadRequest, Message: "The manifest contains no objects"}
+	}
+	return documents, nil
+}
+
+// decodeYAMLDocuments decodes every non-empty document of a YAML stream the way kubectl does, so keys such
+// as 1 or true and unquoted dates are kept as strings. Errors name the document and the line within it,
+// e.g. "Invalid YAML in document 2: yaml: line 3: mapping values are not allowed in this context".
+func decodeYAMLDocuments(body []byte) ([]interface{}, *models.ModelError) {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(body)))
+	documents := make([]interface{}, 0)
+	for index := 1; ; index++ {
+		content, err := reader.Read()
+		if err == io.EOF {
+			return documents, nil
+		}
+		if err != nil {
+			return nil, &models.ModelError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid YAML: %s", err)}
+		}
+		jsonContent, err := yaml.YAMLToJSONStrict(content)
+		if err != nil {
+			return nil, &models.ModelError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid YAML in document %d: %s", index, err)}
+		}
+
+		var document interface{}
+		if err := json.Unmarshal(jsonContent, &document); err != nil {
+			return nil, &models.ModelError{Code: http.StatusBadRequest, Message: fmt.Sprintf("Invalid YAML in document %d: %s", index, err)}
+		}
+		if document != nil {
+			documents = append(documents, document)
+		}
+	}
+}
+
+func isYAMLMediaType(mediaType string) bool {
+	switch mediaType {
+	case "application/yaml", "application/x-yaml", "text/yaml":
+		return true
+	}
+	return false
+}
+
+// acceptsYAML reports whether the Accept header prefers YAML to JSON, which is the default.
+func acceptsYAML(r *http.Request) bool {
+	yamlQuality, jsonQuality := 0.0, 0.0
+	for _, mediaRange := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		switch {
+		case isYAMLMediaType(mediaType):
+			yamlQuality = max(yamlQuality, quality)
+		case mediaType == "application/json", mediaType == "application/*", mediaType == "*/*":
+			jsonQuality = max(jsonQuality, quality)
+		}
+	}
+	return yamlQuality > jsonQuality
+}
+
+// writeNegotiatedResponse writes the data as YAML when the client prefers it and as JSON otherwise.
+func writeNegotiatedResponse(w http.ResponseWriter, r *http.Request, statusCode int, data interface{}) {
+	w.Header().Add("Vary", "Accept")
+	if !acceptsYAML(r) {
+		writeJSONResponse(w, statusCode, data)
+		return
+	}
+
+	// The JSON form is converted, so that field names follow the json tags and objects the API server returned keep their layout
+	jsonContent, err := json.Marshal(data)
+	var yamlContent []byte
+	if err == nil {
+		yamlContent, err = yaml.JSONToYAML(jsonContent)
+	}
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/yaml; charset=UTF-8")
+	w.WriteHeader(statusCode)
+	w.Write(yamlContent)
+}
+
 func checkVersion(version int32) *models.ModelError {
 	if version < 0 {
 		return &models.ModelError{Code: 400, Message: "Invalid version"}
 	}
 	return nil
 }
+
+// getResourceInterfaceGetter returns the getter used to reach the cluster for this request,
+// impersonating the caller when the authorization mode requires it. Reads are served from the informer
+// cache when it is enabled, unless the request asks for consistent data. The cache holds data read with
+// the service account, so it is never used for impersonated requests.
+func getResourceInterfaceGetter(r *http.Request) (cluster.ResourceInterfaceGetter, *models.ModelError) {
+	if !common.UsesImpersonation() && common.InformerCacheEnabled && !isConsistentRequest(r) {
+		return cluster.GetCachedResourceInterface, nil
+	}
+	return getUncachedResourceInterfaceGetter(r)
+}
+
+// getUncachedResourceInterfaceGetter is getResourceInterfaceGetter for requests which always need current data.
+func getUncachedResourceInterfaceGetter(r *http.Request) (cluster.ResourceInterfaceGetter, *models.ModelError) {
+	if !common.UsesImpersonation() {
+		return cluster.GetResourceInterface, nil
+	}
+	username, groups, err := getImpersonatedIdentity(r)
+	if err != nil {
+		return nil, err
+	}
+	return cluster.GetImpersonatedResourceInterfaceGetter(username, groups), nil
+}
+
+// getClientsetGetter is the typed client counterpart of getResourceInterfaceGetter.
+func getClientsetGetter(r *http.Request) (cluster.ClientsetGetter, *models.ModelError) {
+	if !common.UsesImpersonation() {
+		return cluster.GetClientset, nil
+	}
+	username, groups, err := getImpersonatedIdentity(r)
+	if err != nil {
+		return nil, err
+	}
+	return cluster.GetImpersonatedClientsetGetter(username, groups), nil
+}
+
+// getConfigGetter is the counterpart of getResourceInterfaceGetter for streaming clients.
+func getConfigGetter(r *http.Request) (cluster.ConfigGetter, *models.ModelError) {
+	if !common.UsesImpersonation() {
+		return cluster.GetRESTConfig, nil
+	}
+	username, groups, err := getImpersonatedIdentity(r)
+	if err != nil {
+		return nil, err
+	}
+	return cluster.GetImpersonatedConfigGetter(username, groups), nil
+}
+
+// getActionConfigGetter is the Helm counterpart of getResourceInterfaceGetter.
+func getActionConfigGetter(r *http.Request) (helm.ActionConfigGetter, *models.ModelError) {
+	if !common.UsesImpersonation() {
+		return helm.PrepareActionConfig, nil
+	}
+	username, groups, err := getImpersonatedIdentity(r)
+	if err != nil {
+		return nil, err
+	}
+	return helm.GetImpersonatedActionConfigGetter(username, groups), nil
+}
+
+// getClaims returns the claims of a valid token from the request.
+func getClaims(r *http.Request) (*jwt.MapClaims, *models.ModelError) {
+	token, err := auth.GetJWTTokenFromHeader(r)
+	isValid, claims := auth.IsTokenValid(token)
+
+	if err != nil || !isValid {
+		return nil, &models.ModelError{
+			Code:    http.StatusUnauthorized,
+			Message: "Authentication failed",
+		}
+	}
+	return claims, nil
+}
+
+// withTokenDeadline ends the context when the token of the request expires. A token without the exp
+// claim does not expire, so the context then ends only with the request.
+func withTokenDeadline(ctx context.Context, claims *jwt.MapClaims) (context.Context, context.CancelFunc) {
+	expiration := auth.ExtractExpirationTime(claims)
+	if expiration.IsZero() {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, expiration)
+}
+
+func getImpersonatedIdentity(r *http.Request) (string, []string, *models.ModelError) {
+	claims, errM := getClaims(r)
+	if errM != nil {
+		return "", nil, errM
+	}
+
+	username := auth.ExtractUsername(claims)
+	if username == "" {
+		return "", nil, &models.ModelError{
+			Code:    http.StatusUnauthorized,
+			Message: "Username missing in token",
+		}
+	}
+
+	roles, errM := auth.ExtractRoles(claims)
+	if errM != nil {
+		return "", nil, errM
+	}
+	return username, roles, nil
+}
@@ -0,0 +1,325 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func newBodyRequest(contentType string, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/k8s/Pod", strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	return r
+}
+
+func TestDecodeResourceBody(t *testing.T) {
+	tests := []struct {
+		name            string
+		contentType     string
+		body            string
+		expected        map[string]interface{}
+		expectedMessage string
+	}{
+		{
+			name:        "JSON body",
+			contentType: "application/json",
+			body:        `{"kind": "Pod", "metadata": {"name": "api"}, "spec": {"priority": 1}}`,
+			expected:    map[string]interface{}{"kind": "Pod", "metadata": map[string]interface{}{"name": "api"}, "spec": map[string]interface{}{"priority": float64(1)}},
+		},
+		{
+			name:     "JSON body without Content-Type",
+			body:     `{"kind": "Pod"}`,
+			expected: map[string]interface{}{"kind": "Pod"},
+		},
+		{
+			name:            "invalid JSON body",
+			contentType:     "application/json",
+			body:            `{"kind": `,
+			expectedMessage: "Invalid request body",
+		},
+		{
+			name:        "YAML body",
+			contentType: "application/yaml",
+			body:        "kind: Pod\nmetadata:\n  name: api\nspec:\n  priority: 1\n",
+			expected:    map[string]interface{}{"kind": "Pod", "metadata": map[string]interface{}{"name": "api"}, "spec": map[string]interface{}{"priority": float64(1)}},
+		},
+		{
+			name:        "YAML body with charset and legacy media type",
+			contentType: "application/x-yaml; charset=utf-8",
+			body:        "kind: Pod\n",
+			expected:    map[string]interface{}{"kind": "Pod"},
+		},
+		{
+			name:        "YAML timestamps stay strings",
+			contentType: "text/yaml",
+			body:        "metadata:\n  annotations:\n    released: 2024-01-02\n",
+			expected:    map[string]interface{}{"metadata": map[string]interface{}{"annotations": map[string]interface{}{"released": "2024-01-02"}}},
+		},
+		{
+			name:        "YAML non-string keys are kept as written",
+			contentType: "application/yaml",
+			body:        "data:\n  1: one\n  true: \"yes\"\n  1.5: half\n",
+			expected:    map[string]interface{}{"data": map[string]interface{}{"1": "one", "true": "yes", "1.5": "half"}},
+		},
+		{
+			name:        "YAML merge keys",
+			contentType: "application/yaml",
+			body:        "base: &base\n  app: api\nlabels:\n  <<: *base\n  tier: web\n",
+			expected:    map[string]interface{}{"base": map[string]interface{}{"app": "api"}, "labels": map[string]interface{}{"app": "api", "tier": "web"}},
+		},
+		{
+			name:            "YAML mapping as key",
+			contentType:     "application/yaml",
+			body:            "kind: Pod\ndata:\n  ? {a: 1}\n  : value\n",
+			expectedMessage: "Invalid YAML in document 1: yaml: invalid map key: map[interface {}]interface {}{\"a\":1}",
+		},
+		{
+			name:            "YAML syntax error",
+			contentType:     "application/yaml",
+			body:            "kind: Pod\nmetadata:\n  name: api\n  labels: app: api\n",
+			expectedMessage: "Invalid YAML in document 1: yaml: line 4: mapping values are not allowed in this context",
+		},
+		{
+			name:            "YAML duplicate key",
+			contentType:     "application/yaml",
+			body:            "kind: Pod\nmetadata:\n  name: api\n  labels: {}\n  name: web\n",
+			expectedMessage: "Invalid YAML in document 1: yaml: unmarshal errors:\n  line 5: key \"name\" already set in map",
+		},
+		{
+			name:            "multiple YAML documents",
+			contentType:     "application/yaml",
+			body:            "kind: Pod\n---\nkind: Service\n",
+			expectedMessage: "Expected a single YAML document, found 2",
+		},
+		{
+			name:            "empty YAML body",
+			contentType:     "application/yaml",
+			body:            "",
+			expectedMessage: "Expected a single YAML document, found 0",
+		},
+		{
+			name:            "YAML scalar instead of an object",
+			contentType:     "application/yaml",
+			body:            "just text\n",
+			expectedMessage: "Invalid request body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resource map[string]interface{}
+			err := decodeResourceBody(newBodyRequest(tt.contentType, tt.body), &resource)
+			if tt.expectedMessage != "" {
+				assert.Equal(t, &models.ModelError{Code: http.StatusBadRequest, Message: tt.expectedMessage}, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, resource)
+		})
+	}
+}
+
+func TestDecodeManifestBody(t *testing.T) {
+	tests := []struct {
+		name            string
+		contentType     string
+		body            string
+		expectedKinds   []string
+		expectedMessage string
+	}{
+		{
+			name:          "YAML stream",
+			contentType:   "application/yaml",
+			body:          "kind: Namespace\n---\nkind: Deployment\n---\nkind: Service\n",
+			expectedKinds: []string{"Namespace", "Deployment", "Service"},
+		},
+		{
+			name:          "empty documents and comments are skipped",
+			body:          "---\n# only a comment\n---\nkind: ConfigMap\n---\n",
+			expectedKinds: []string{"ConfigMap"},
+		},
+		{
+			name:          "JSON object",
+			contentType:   "application/json",
+			body:          `{"kind": "List", "items": []}`,
+			expectedKinds: []string{"List"},
+		},
+		{
+			name:            "line of an error in a later document",
+			contentType:     "application/yaml",
+			body:            "kind: Namespace\n---\nkind: Deployment\nmetadata:\n  name: api\n  labels: app: api\n",
+			expectedMessage: "Invalid YAML in document 2: yaml: line 4: mapping values are not allowed in this context",
+		},
+		{
+			name:            "invalid JSON",
+			contentType:     "application/json",
+			body:            `[`,
+			expectedMessage: "Invalid request body",
+		},
+		{
+			name:            "no objects",
+			contentType:     "application/yaml",
+			body:            "---\n---\n",
+			expectedMessage: "The manifest contains no objects",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			documents, err := decodeManifestBody(newBodyRequest(tt.contentType, tt.body))
+			if tt.expectedMessage != "" {
+				assert.Equal(t, &models.ModelError{Code: http.StatusBadRequest, Message: tt.expectedMessage}, err)
+				return
+			}
+			assert.Nil(t, err)
+			kinds := make([]string, 0, len(documents))
+			for _, document := range documents {
+				kinds = append(kinds, document.(map[string]interface{})["kind"].(string))
+			}
+			assert.Equal(t, tt.expectedKinds, kinds)
+		})
+	}
+}
+
+func TestAcceptsYAML(t *testing.T) {
+	tests := []struct {
+		accept   string
+		expected bool
+	}{
+		{"", false},
+		{"application/json", false},
+		{"application/yaml", true},
+		{"application/x-yaml", true},
+		{"text/yaml", true},
+		{"*/*", false},
+		{"application/*", false},
+		{"application/yaml, application/json", false},
+		{"application/yaml, */*;q=0.8", true},
+		{"application/json;q=0.5, application/yaml", true},
+		{"application/yaml;q=0.5, application/json", false},
+		{"application/yaml;q=0.9, */*;q=0.1", true},
+		{"application/yaml;q=0", false},
+		{"application/yaml;q=abc, application/json;q=0.1", false},
+		{"text/html, application/yaml;q=0.9", true},
+		{"text/html", false},
+		{"not a media type, application/yaml", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/k8s/Pod", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			assert.Equal(t, tt.expected, acceptsYAML(r))
+		})
+	}
+}
+
+func TestWriteNegotiatedResponse(t *testing.T) {
+	data := &models.ModelError{Code: http.StatusNotFound, Message: "Resource not found"}
+	tests := []struct {
+		name                string
+		accept              string
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "JSON by default",
+			expectedContentType: "application/json; charset=UTF-8",
+			expectedBody:        "{\"code\":404,\"message\":\"Resource not found\"}\n",
+		},
+		{
+			name:                "YAML when preferred",
+			accept:              "application/yaml",
+			expectedContentType: "application/yaml; charset=UTF-8",
+			expectedBody:        "code: 404\nmessage: Resource not found\n",
+		},
+		{
+			name:                "JSON when preferred over YAML",
+			accept:              "application/yaml;q=0.5, application/json",
+			expectedContentType: "application/json; charset=UTF-8",
+			expectedBody:        "{\"code\":404,\"message\":\"Resource not found\"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/k8s/Pod/api", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+
+			writeNegotiatedResponse(w, r, http.StatusNotFound, data)
+
+			assert.Equal(t, http.StatusNotFound, w.Code)
+			assert.Equal(t, tt.expectedContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, "Accept", w.Header().Get("Vary"))
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestUpgradeWebSocket(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgradeWebSocket(w, r, terminalProtocol)
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	tests := []struct {
+		name      string
+		requested []string
+		expected  string
+	}{
+		{"subprotocol of the endpoint", []string{"bearer.token", terminalProtocol}, terminalProtocol},
+		{"subprotocol of another endpoint", []string{"bearer.token", portForwardProtocol}, ""},
+		{"no subprotocol", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dialer := websocket.Dialer{Subprotocols: tt.requested}
+			conn, _, err := dialer.Dial(url, nil)
+			assert.Nil(t, err)
+			defer conn.Close()
+			assert.Equal(t, tt.expected, conn.Subprotocol())
+		})
+	}
+}
+
+func TestWithTokenDeadline(t *testing.T) {
+	t.Run("token with exp", func(t *testing.T) {
+		expiration := time.Now().Add(time.Hour).Truncate(time.Second)
+		ctx, cancel := withTokenDeadline(context.Background(), &jwt.MapClaims{"exp": float64(expiration.Unix())})
+		defer cancel()
+
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.Equal(t, expiration, deadline)
+	})
+
+	t.Run("token without exp", func(t *testing.T) {
+		ctx, cancel := withTokenDeadline(context.Background(), &jwt.MapClaims{"sub": "user"})
+
+		_, ok := ctx.Deadline()
+		assert.False(t, ok)
+		assert.NoError(t, ctx.Err())
+		cancel()
+		assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	})
+}
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+)
+
+// responseStream is a response written in parts for as long as the request lasts, which is until the client
+// disconnects or the token of the request expires.
+type responseStream struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// newResponseStream checks that the response can be streamed. Errors are returned before anything is
+// written, so they can still be sent as a JSON response. The stream must be closed with close.
+func newResponseStream(w http.ResponseWriter, r *http.Request) (*responseStream, *models.ModelError) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, &models.ModelError{Code: http.StatusInternalServerError, Message: "Streaming not supported"}
+	}
+	claims, err := getClaims(r)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := withTokenDeadline(r.Context(), claims)
+	return &responseStream{ctx: ctx, cancel: cancel, w: w, flusher: flusher}, nil
+}
+
+func (s *responseStream) close() {
+	s.cancel()
+}
+
+// start sends the headers of the stream, which proxies are asked not to buffer.
+func (s *responseStream) start(contentType string) {
+	s.w.Header().Set("Content-Type", contentType)
+	s.w.Header().Set("Cache-Control", "no-cache")
+	s.w.Header().Set("X-Accel-Buffering", "no")
+	s.w.WriteHeader(http.StatusOK)
+	s.flusher.Flush()
+}
+
+// write sends a chunk of the stream to the client right away.
+func (s *responseStream) write(chunk []byte) error {
+	if _, err := s.w.Write(chunk); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+func (s *responseStream) writeEvent(event string, data interface{}) {
+	writeServerSentEvent(s.w, s.flusher, event, data)
+}
+
+// serveEvents passes every update to send, which writes it as Server-Sent Events and returns false once the
+// stream should end. Comments keep the connection open in between. The stream also ends when updates is
+// closed or the client disconnects, and with an "error" event when the token expires.
+func serveEvents[T any](s *responseStream, updates <-chan T, send func(update T) bool) {
+	heartbeat := time.NewTicker(watchHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			if s.ctx.Err() == context.DeadlineExceeded {
+				s.writeEvent(watchErrorEvent, &models.ModelError{Code: http.StatusUnauthorized, Message: "Token expired"})
+			}
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(s.w, ": keep-alive\n\n")
+			s.flusher.Flush()
+		case update, open := <-updates:
+			if !open || !send(update) {
+				return
+			}
+		}
+	}
+}
+
+func writeServerSentEvent(w http.ResponseWriter, flusher http.Flusher, event string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+	flusher.Flush()
+}
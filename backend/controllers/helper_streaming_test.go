@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestResponseStream(ctx context.Context) (*responseStream, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	ctx, cancel := context.WithCancel(ctx)
+	return &responseStream{ctx: ctx, cancel: cancel, w: w, flusher: w}, w
+}
+
+func TestServeEventsUntilSendEnds(t *testing.T) {
+	stream, w := newTestResponseStream(context.Background())
+	defer stream.close()
+	updates := make(chan int, 3)
+	updates <- 1
+	updates <- 2
+	updates <- 3
+
+	serveEvents(stream, updates, func(update int) bool {
+		stream.writeEvent("count", update)
+		return update < 2
+	})
+
+	assert.Equal(t, "event: count\ndata: 1\n\nevent: count\ndata: 2\n\n", w.Body.String())
+}
+
+func TestServeEventsUntilUpdatesClose(t *testing.T) {
+	stream, w := newTestResponseStream(context.Background())
+	defer stream.close()
+	updates := make(chan int)
+	close(updates)
+
+	serveEvents(stream, updates, func(update int) bool {
+		stream.writeEvent("count", update)
+		return true
+	})
+
+	assert.Empty(t, w.Body.String())
+}
+
+func TestServeEventsTokenExpired(t *testing.T) {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+	stream, w := newTestResponseStream(ctx)
+	defer stream.close()
+
+	serveEvents(stream, make(chan int), func(update int) bool { return true })
+
+	assert.Equal(t, "event: error\ndata: {\"code\":401,\"message\":\"Token expired\"}\n\n", w.Body.String())
+}
+
+func TestServeEventsClientDisconnected(t *testing.T) {
+	stream, w := newTestResponseStream(context.Background())
+	stream.close()
+
+	serveEvents(stream, make(chan int), func(update int) bool { return true })
+
+	assert.Empty(t, w.Body.String())
+}
@@ -3,6 +3,7 @@ package health
 import (
 	"fmt"
 	"github.com/Icikowski/kubeprobes"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"net/http"
 )
 
@@ -13,8 +14,12 @@ func PrepareHealthEndpoints(port int) *http.Server {
 		kubeprobes.WithReadinessProbes(ServiceStatus.GetProbeFunction()),
 	)
 
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/", health)
+
 	return &http.Server{
 		Addr:    fmt.Sprintf(":%d", port),
-		Handler: health,
+		Handler: mux,
 	}
 }
@@ -2,8 +2,10 @@ package helm
 
 import (
 	"errors"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/cluster"
 	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
 	"helm.sh/helm/v3/pkg/storage/driver"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"strings"
 	"time"
 )
@@ -18,7 +20,7 @@ func GetHelmRelease(releaseName string, namespace string, getActionConfig Action
 
 	release, err := actionConfig.getRelease(releaseName)
 	if err != nil {
-		return nil, &models.ModelError{Code: 404, Message: "Release not found: " + err.Error()}
+		return nil, handleHelmError(err, 404, "Release not found: ")
 	}
 
 	return getReleaseData(release), nil
@@ -32,7 +34,7 @@ func ListHelmReleases(namespace string, getActionConfig ActionConfigGetter) ([]m
 
 	releases, err := actionConfig.listReleases(namespace == "")
 	if err != nil {
-		return nil, &models.ModelError{Code: 500, Message: "Failed to list releases: " + err.Error()}
+		return nil, handleHelmError(err, 500, "Failed to list releases: ")
 	}
 
 	var helmReleases []models.HelmRelease
@@ -59,10 +61,7 @@ func UninstallHelmRelease(releaseName string, namespace string, timeout time.Dur
 	select {
 	case err := <-errCh:
 		if err != nil {
-			if errors.Is(err, driver.ErrReleaseNotFound) {
-				return false, &models.ModelError{Code: 404, Message: "Release not found: " + err.Error()}
-			}
-			return false, &models.ModelError{Code: 500, Message: "Internal server error: " + err.Error()}
+			return false, handleHelmError(err, 500, "Internal server error: ")
 		}
 		return true, nil
 	case <-time.After(timeout):
@@ -78,7 +77,7 @@ func GetHelmReleaseHistory(releaseName string, namespace string, getActionConfig
 
 	releases, err := actionConfig.getReleaseHistory(releaseName, 0)
 	if err != nil {
-		return nil, &models.ModelError{Code: 404, Message: "Failed to get release history"}
+		return nil, handleHelmError(err, 404, "Failed to get release history: ")
 	}
 
 	var helmReleases []models.HelmReleaseHistory
@@ -119,18 +118,35 @@ func RollbackHelmRelease(releaseName string, namespace string, version int, time
 	select {
 	case result := <-resultCh:
 		if result.err != nil {
-			if errors.Is(result.err, driver.ErrReleaseNotFound) {
-				return nil, false, &models.ModelError{Code: 404, Message: "Release not found"}
-			}
-
-			if strings.Contains(result.err.Error(), "version") {
+			// Helm only tells about a missing revision in the message, which errors of the API server may also mention
+			isHelmError := !errors.Is(result.err, driver.ErrReleaseNotFound) && cluster.FromKubernetesError(result.err) == nil
+			if isHelmError && strings.Contains(result.err.Error(), "version") {
 				return nil, false, &models.ModelError{Code: 400, Message: "Invalid revision: " + result.err.Error()}
 			}
 
-			return nil, false, &models.ModelError{Code: 500, Message: "Internal server error: " + result.err.Error()}
+			return nil, false, handleHelmError(result.err, 500, "Internal server error: ")
 		}
 		return result.release, true, nil
 	case <-time.After(timeout):
 		return nil, false, nil
 	}
 }
+
+// GetHelmReleaseObjects returns the release together with the objects of its manifest, in the order Helm installs them.
+func GetHelmReleaseObjects(releaseName string, namespace string, getActionConfig ActionConfigGetter) (*models.HelmRelease, []*unstructured.Unstructured, *models.ModelError) {
+	actionConfig, cErr := getActionConfig(namespace, false)
+	if cErr != nil {
+		return nil, nil, cErr
+	}
+
+	release, err := actionConfig.getRelease(releaseName)
+	if err != nil {
+		return nil, nil, handleHelmError(err, 404, "Release not found: ")
+	}
+
+	objects, err := getManifestObjects(release.Manifest)
+	if err != nil {
+		return nil, nil, &models.ModelError{Code: 500, Message: "Failed to read release manifest: " + err.Error()}
+	}
+	return getReleaseData(release), objects, nil
+}
@@ -1,13 +1,33 @@
 package helm
 
 import (
+	"errors"
 	"fmt"
 	"github.com/ZPI-2024-25/KubernetesAccessManager/cluster"
 	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
 	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/rest"
+	"sort"
+	"strings"
 	"time"
 )
 
+// handleHelmError translates errors of the API server behind a Helm action, e.g. a forbidden read of the Secret
+// storing a release, and falls back to the code and message for errors of Helm itself.
+func handleHelmError(err error, code int32, message string) *models.ModelError {
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		return &models.ModelError{Code: 404, Message: "Release not found: " + err.Error()}
+	}
+	if modelErr := cluster.FromKubernetesError(err); modelErr != nil {
+		return modelErr
+	}
+	return &models.ModelError{Code: code, Message: message + err.Error()}
+}
+
 func getReleaseData(release *release.Release) *models.HelmRelease {
 	if release == nil {
 		return nil
@@ -92,6 +112,23 @@ func PrepareActionConfig(namespace string, useDefaultNamespace bool) (ActionConf
 		return nil, &models.ModelError{Code: 500, Message: "Failed to get cluster config"}
 	}
 
+	return prepareActionConfigForConfig(config, namespace, useDefaultNamespace)
+}
+
+// GetImpersonatedActionConfigGetter works like PrepareActionConfig, but Helm talks to the API server
+// on behalf of the given user.
+func GetImpersonatedActionConfigGetter(username string, groups []string) ActionConfigGetter {
+	return func(namespace string, useDefaultNamespace bool) (ActionConfigInterface, *models.ModelError) {
+		config, err := cluster.GetImpersonatedConfig(username, groups)
+		if err != nil {
+			return nil, &models.ModelError{Code: 500, Message: "Failed to get cluster config"}
+		}
+
+		return prepareActionConfigForConfig(config, namespace, useDefaultNamespace)
+	}
+}
+
+func prepareActionConfigForConfig(config *rest.Config, namespace string, useDefaultNamespace bool) (ActionConfigInterface, *models.ModelError) {
 	if namespace == "" && useDefaultNamespace {
 		namespace = "default"
 	}
@@ -103,3 +140,39 @@ func PrepareActionConfig(namespace string, useDefaultNamespace bool) (ActionConf
 
 	return actionConfig, nil
 }
+
+// PaginateHelmReleases returns the releases from offset on, at most limit of them when limit is positive.
+func PaginateHelmReleases(releases []models.HelmRelease, offset int, limit int) []models.HelmRelease {
+	if offset >= len(releases) {
+		return []models.HelmRelease{}
+	}
+	releases = releases[offset:]
+	if limit > 0 && limit < len(releases) {
+		releases = releases[:limit]
+	}
+	return releases
+}
+
+// getManifestObjects decodes the documents of a release manifest, skipping empty ones, e.g. of disabled templates.
+func getManifestObjects(manifest string) ([]*unstructured.Unstructured, error) {
+	documents := releaseutil.SplitManifests(manifest)
+	keys := make([]string, 0, len(documents))
+	for key := range documents {
+		keys = append(keys, key)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+	objects := make([]*unstructured.Unstructured, 0, len(keys))
+	for _, key := range keys {
+		object := &unstructured.Unstructured{}
+		decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(documents[key]), 4096)
+		if err := decoder.Decode(&object.Object); err != nil {
+			return nil, err
+		}
+		if len(object.Object) == 0 {
+			continue
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
@@ -1,6 +1,8 @@
 package helm
 
 import (
+	"errors"
+	"fmt"
 	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
 	"testing"
 	"time"
@@ -8,7 +10,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
 	helmtime "helm.sh/helm/v3/pkg/time"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func TestGetReleaseData_NilRelease(t *testing.T) {
@@ -457,3 +462,67 @@ func TestGetReleaseHistoryData(t *testing.T) {
 
 	assert.Equal(t, expectedHistory, result)
 }
+
+func TestPaginateHelmReleases(t *testing.T) {
+	releases := []models.HelmRelease{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+
+	tests := []struct {
+		name     string
+		offset   int
+		limit    int
+		expected []models.HelmRelease
+	}{
+		{"No pagination", 0, 0, releases},
+		{"Limit only", 0, 2, []models.HelmRelease{{Name: "a"}, {Name: "b"}}},
+		{"Offset only", 1, 0, []models.HelmRelease{{Name: "b"}, {Name: "c"}}},
+		{"Offset and limit", 1, 1, []models.HelmRelease{{Name: "b"}}},
+		{"Limit past the end", 2, 5, []models.HelmRelease{{Name: "c"}}},
+		{"Offset past the end", 3, 1, []models.HelmRelease{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, PaginateHelmReleases(releases, tt.offset, tt.limit))
+		})
+	}
+}
+
+func TestGetManifestObjects(t *testing.T) {
+	manifest := `---
+# Source: app/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+---
+# Source: app/templates/disabled.yaml
+---
+# Source: app/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+`
+
+	objects, err := getManifestObjects(manifest)
+
+	assert.Nil(t, err)
+	assert.Len(t, objects, 2)
+	assert.Equal(t, "Service", objects[0].GetKind())
+	assert.Equal(t, "Deployment", objects[1].GetKind())
+	assert.Equal(t, "app", objects[1].GetName())
+}
+
+func TestHandleHelmError(t *testing.T) {
+	forbidden := apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "", errors.New("no access"))
+
+	result := handleHelmError(fmt.Errorf("query: failed to query with labels: %w", forbidden), 500, "Failed to list releases: ")
+	assert.Equal(t, int32(403), result.Code)
+	assert.Equal(t, "Forbidden", result.Reason)
+
+	result = handleHelmError(driver.ErrReleaseNotFound, 500, "Internal server error: ")
+	assert.Equal(t, int32(404), result.Code)
+
+	result = handleHelmError(errors.New("chart is invalid"), 500, "Internal server error: ")
+	assert.Equal(t, &models.ModelError{Code: 500, Message: "Internal server error: chart is invalid"}, result)
+}
@@ -4,12 +4,15 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	sw "github.com/ZPI-2024-25/KubernetesAccessManager/api"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/audit"
 	"github.com/ZPI-2024-25/KubernetesAccessManager/auth"
 	"github.com/ZPI-2024-25/KubernetesAccessManager/cluster"
 	"github.com/ZPI-2024-25/KubernetesAccessManager/common"
 	"github.com/ZPI-2024-25/KubernetesAccessManager/health"
+	"github.com/ZPI-2024-25/KubernetesAccessManager/recordings"
 	"github.com/gorilla/handlers"
 )
 
@@ -23,12 +26,34 @@ func main() {
 		log.Fatalf("Error when loading config: %v\n", err)
 	}
 
-	_, err = auth.GetRoleMapInstance()
-	if err != nil {
-		log.Printf("Error when loading role map: %v\n", err)
+	if common.UsesRoleMap() {
+		_, err = auth.GetRoleMapInstance()
+		if err != nil {
+			log.Printf("Error when loading role map: %v\n", err)
+		}
+
+		go auth.WatchForRolemapChanges()
 	}
 
-	go auth.WatchForRolemapChanges()
+	go cluster.RefreshDiscoveryCachePeriodically(time.Duration(common.DiscoveryRefreshInterval) * time.Second)
+	go cluster.WatchForCRDChanges()
+	if common.InformerCacheEnabled {
+		if common.UsesImpersonation() {
+			log.Printf("Informer cache is not used in authorization mode %s", common.AuthorizationMode)
+		} else {
+			go cluster.StopIdleInformersPeriodically()
+		}
+	}
+	if common.SessionRecordingEnabled {
+		if err := recordings.InitStorage(common.SessionRecordingDir); err != nil {
+			log.Fatalf("Error when preparing session recordings storage: %v\n", err)
+		}
+	}
+	if common.AuditLogPath != "" {
+		if err := audit.InitTrail(common.AuditLogPath); err != nil {
+			log.Fatalf("Error when opening audit trail: %v\n", err)
+		}
+	}
 	go func() {
 		log.Printf("Health endpoints starting on port %d", common.HealthPort)
 		if err := healthServer.ListenAndServe(); err != nil {
@@ -41,13 +66,15 @@ func main() {
 
 	log.Printf("Server started")
 	log.Printf("Authentication method: %s", clusterSingleton.GetAuthenticationMethod())
+	log.Printf("Authorization mode: %s", common.AuthorizationMode)
 
 	router := sw.NewRouter()
 
 	corsHandler := handlers.CORS(
 		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
+		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}),
 		handlers.AllowedHeaders([]string{"Authorization", "Content-Type"}),
+		handlers.ExposedHeaders([]string{"X-Total-Count"}),
 	)
 
 	health.ServiceStatus.MarkAsUp()
@@ -0,0 +1,29 @@
+package models
+
+// Result of applying a manifest made of many objects.
+type ApplyReport struct {
+	// Whether every object of the manifest was applied.
+	Succeeded bool `json:"succeeded"`
+	// Whether the objects created before a failure were deleted again, set only for atomic requests.
+	RolledBack bool `json:"rolled_back,omitempty"`
+	// Whether the objects were only validated, without persisting any change.
+	DryRun bool `json:"dry_run,omitempty"`
+	// Results of the objects in the order they were applied.
+	Results []ApplyResult `json:"results"`
+}
+
+// Result of applying a single object of a manifest.
+type ApplyResult struct {
+	// Position of the document in the manifest, starting at 1. Items of a List share the position of the List.
+	Document   int    `json:"document"`
+	ApiVersion string `json:"api_version,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	// One of created, configured, unchanged, failed or skipped.
+	Action string `json:"action"`
+	// Whether the created object was deleted again after another object failed.
+	RolledBack bool `json:"rolled_back,omitempty"`
+	// Reason the object failed, or its rollback failed.
+	Error *ModelError `json:"error,omitempty"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Entry of the audit trail, recording an access to sensitive data.
+type AuditEntry struct {
+	Time time.Time `json:"time"`
+	// Username from the token of the user.
+	User string `json:"user"`
+	// Action taken, e.g. reveal.
+	Action    string `json:"action"`
+	Resource  string `json:"resource"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Keys of the Secret which were revealed.
+	Keys []string `json:"keys,omitempty"`
+}
@@ -14,4 +14,20 @@ type ModelError struct {
 	Code int32 `json:"code,omitempty"`
 	// Error message
 	Message string `json:"message,omitempty"`
+	// Reason reported by the Kubernetes API server, e.g. AlreadyExists or Conflict
+	Reason string `json:"reason,omitempty"`
+	// Field-level causes of the error, e.g. the fields which failed validation
+	Causes []ErrorCause `json:"causes,omitempty"`
+	// Seconds after which the request may be retried
+	RetryAfterSeconds int32 `json:"retry_after_seconds,omitempty"`
+}
+
+// Single cause of an error
+type ErrorCause struct {
+	// Machine-readable type of the cause, e.g. FieldValueInvalid
+	Type string `json:"type,omitempty"`
+	// Description of the cause
+	Message string `json:"message,omitempty"`
+	// Path of the field which caused the error, e.g. spec.replicas
+	Field string `json:"field,omitempty"`
 }
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Event reported by a Kubernetes component about a resource, e.g. a Pod which cannot be scheduled.
+type KubernetesEvent struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// Either Normal or Warning.
+	Type    string `json:"type"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	// Resource the event is about.
+	InvolvedObject EventObject `json:"involved_object"`
+	// Component which reported the event, e.g. default-scheduler or kubelet.
+	Source string `json:"source,omitempty"`
+	// Number of times the event has occurred.
+	Count          int32     `json:"count"`
+	FirstTimestamp time.Time `json:"first_timestamp"`
+	LastTimestamp  time.Time `json:"last_timestamp"`
+}
+
+// Resource an event is about.
+type EventObject struct {
+	ApiVersion string `json:"api_version,omitempty"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+	Uid        string `json:"uid,omitempty"`
+	// Part of the resource the event is about, e.g. a container of a Pod.
+	FieldPath string `json:"field_path,omitempty"`
+}
@@ -0,0 +1,22 @@
+package models
+
+// Progress of evicting a single Pod while draining a Node.
+type DrainPodStatus struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// One of evicting, waiting (the eviction is blocked by a PodDisruptionBudget and retried), evicted, skipped or failed.
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// Final report of draining a Node.
+type DrainReport struct {
+	Node string `json:"node"`
+	// Whether all Pods which had to leave the Node were evicted.
+	Succeeded bool             `json:"succeeded"`
+	Evicted   []DrainPodStatus `json:"evicted"`
+	// Pods which stay on the Node, e.g. those of DaemonSets.
+	Skipped []DrainPodStatus `json:"skipped"`
+	// Pods which could not be evicted, with the reason.
+	Failed []DrainPodStatus `json:"failed"`
+}
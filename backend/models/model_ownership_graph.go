@@ -0,0 +1,23 @@
+package models
+
+// Resource in an ownership graph, together with the resources it owns.
+type OwnershipNode struct {
+	ApiVersion string `json:"api_version,omitempty"`
+	// Kind of the resource, HelmRelease for a Helm release.
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Uid       string `json:"uid,omitempty"`
+	// Short summary of the state of the resource, e.g. CrashLoopBackOff for a Pod or "2/3 ready" for a Deployment.
+	Status string `json:"status,omitempty"`
+	// Resources owned by this one.
+	Children []OwnershipNode `json:"children,omitempty"`
+}
+
+// Ownership relations of a resource in both directions.
+type OwnershipGraph struct {
+	// Owners of the resource, from its direct owner up to the topmost one, followed by the Helm release which installed it.
+	Owners []OwnershipNode `json:"owners"`
+	// The resource with the resources it owns, directly or indirectly.
+	Resource OwnershipNode `json:"resource"`
+}
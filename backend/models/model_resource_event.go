@@ -0,0 +1,11 @@
+package models
+
+// Change of a resource streamed when watching a resource list.
+type ResourceEvent struct {
+	// One of ADDED, MODIFIED, DELETED or ERROR.
+	Type string `json:"type"`
+	// The changed resource with the columns of its type, empty for ERROR events.
+	Resource ResourceListResourceList `json:"resource"`
+	// Reason the watch has ended, set only for ERROR events.
+	Error *ModelError `json:"error,omitempty"`
+}
@@ -12,6 +12,16 @@ package models
 type ResourceList struct {
 	// List of column names that are included in this response.
 	Columns []string `json:"columns,omitempty"`
+	// Type of every column in Columns.
+	ColumnTypes map[string]ColumnType `json:"column_types,omitempty"`
 	// List of resources, each containing the data for the specified columns.
 	ResourceList []ResourceListResourceList `json:"resource_list"`
+	// Version of the listed resources, watching from it streams the changes made after the list.
+	ResourceVersion string `json:"resource_version,omitempty"`
+	// Token to pass as the continue parameter to get the next page, empty on the last page.
+	Continue string `json:"continue,omitempty"`
+	// Estimated number of resources left after this page, if known.
+	RemainingItemCount *int64 `json:"remaining_item_count,omitempty"`
+	// Problems which left some columns empty, e.g. resource usage when metrics.k8s.io is not served.
+	Warnings []string `json:"warnings,omitempty"`
 }
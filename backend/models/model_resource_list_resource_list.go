@@ -29,12 +29,18 @@ type ResourceListResourceList struct {
 	Containers string `json:"containers,omitempty"`
 	// Optional value for 'controlled_by'
 	ControlledBy string `json:"controlled_by,omitempty"`
+	// Optional value for 'cpu'
+	Cpu string `json:"cpu,omitempty"`
+	// Optional value for 'cpu_percent'
+	CpuPercent string `json:"cpu_percent,omitempty"`
 	// Optional value for 'current'
 	Current string `json:"current,omitempty"`
 	// Optional value for 'default'
 	Default_ string `json:"default,omitempty"`
 	// Optional value for 'desired'
 	Desired string `json:"desired,omitempty"`
+	// Time a Job ran for, as a Go duration, e.g. '1m30s'
+	Duration string `json:"duration,omitempty"`
 	// Optional value for 'external_ip'
 	ExternalIp string `json:"external_ip,omitempty"`
 	// Optional value for 'group'
@@ -47,6 +53,10 @@ type ResourceListResourceList struct {
 	LastSchedule string `json:"last_schedule,omitempty"`
 	// Optional value for 'loadbalancers'
 	Loadbalancers string `json:"loadbalancers,omitempty"`
+	// Optional value for 'memory'
+	Memory string `json:"memory,omitempty"`
+	// Optional value for 'memory_percent'
+	MemoryPercent string `json:"memory_percent,omitempty"`
 	// Optional value for 'name'
 	Name string `json:"name,omitempty"`
 	// Optional value for 'namespace'
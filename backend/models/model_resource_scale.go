@@ -0,0 +1,23 @@
+package models
+
+// Scale subresource of a workload.
+type ResourceScale struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// Desired number of replicas.
+	Replicas int32 `json:"replicas"`
+	// Number of replicas observed by the controller of the workload.
+	CurrentReplicas int32 `json:"current_replicas"`
+	// Label selector of the replicas.
+	Selector string `json:"selector,omitempty"`
+	// Version of the workload, which can be passed when scaling to detect concurrent changes.
+	ResourceVersion string `json:"resource_version,omitempty"`
+}
+
+// Request body for scaling a workload.
+type ResourceScaleBody struct {
+	// Desired number of replicas.
+	Replicas *int32 `json:"replicas"`
+	// Scaling fails when the workload has changed since this version was read.
+	ResourceVersion string `json:"resource_version,omitempty"`
+}
@@ -0,0 +1,34 @@
+package models
+
+// Usage of CPU or memory with the percentage of every reference value which is set.
+type ResourceUsageValue struct {
+	Usage              string `json:"usage"`
+	Request            string `json:"request,omitempty"`
+	RequestPercent     *int64 `json:"request_percent,omitempty"`
+	Limit              string `json:"limit,omitempty"`
+	LimitPercent       *int64 `json:"limit_percent,omitempty"`
+	Allocatable        string `json:"allocatable,omitempty"`
+	AllocatablePercent *int64 `json:"allocatable_percent,omitempty"`
+}
+
+type ContainerUsage struct {
+	Name   string             `json:"name"`
+	Cpu    ResourceUsageValue `json:"cpu"`
+	Memory ResourceUsageValue `json:"memory"`
+}
+
+// CPU and memory usage of a Pod or a Node reported by metrics.k8s.io.
+type ResourceUsage struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// Whether usage was reported, Message tells why it was not, e.g. when metrics-server is not installed.
+	Available bool   `json:"available"`
+	Message   string `json:"message,omitempty"`
+	// Time and length of the window the usage was measured in.
+	Timestamp  string              `json:"timestamp,omitempty"`
+	Window     string              `json:"window,omitempty"`
+	Cpu        *ResourceUsageValue `json:"cpu,omitempty"`
+	Memory     *ResourceUsageValue `json:"memory,omitempty"`
+	Containers []ContainerUsage    `json:"containers,omitempty"`
+}
@@ -0,0 +1,13 @@
+package models
+
+// Progress of the rollout of a Deployment, StatefulSet or DaemonSet.
+type RolloutStatus struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// Human-readable description of the progress, as printed by kubectl rollout status.
+	Message string `json:"message"`
+	// Whether the rollout has completed.
+	Done bool `json:"done"`
+	// Whether the rollout cannot complete, e.g. it exceeded its progress deadline.
+	Failed bool `json:"failed,omitempty"`
+}
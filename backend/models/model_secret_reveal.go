@@ -0,0 +1,11 @@
+package models
+
+// Decoded values of a Secret.
+type SecretReveal struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Decoded values by key.
+	Data map[string]string `json:"data"`
+	// Keys with binary values, which are left base64-encoded.
+	Base64Keys []string `json:"base64_keys,omitempty"`
+}
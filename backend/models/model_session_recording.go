@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Details of a recorded exec or attach session.
+type SessionRecording struct {
+	Id string `json:"id"`
+	// Username from the token of the user who opened the session.
+	User string `json:"user"`
+	// Roles from the token of the user who opened the session.
+	Roles []string `json:"roles,omitempty"`
+	// Either exec or attach.
+	Subresource string    `json:"subresource"`
+	Namespace   string    `json:"namespace"`
+	Pod         string    `json:"pod"`
+	Container   string    `json:"container,omitempty"`
+	Command     []string  `json:"command,omitempty"`
+	Tty         bool      `json:"tty"`
+	StartTime   time.Time `json:"start_time"`
+	// Empty while the session is running.
+	EndTime *time.Time `json:"end_time,omitempty"`
+	// Exit code of the command, if it has exited.
+	ExitCode *int `json:"exit_code,omitempty"`
+	// Reason the session has failed.
+	Error string `json:"error,omitempty"`
+}
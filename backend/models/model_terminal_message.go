@@ -0,0 +1,16 @@
+package models
+
+// Message exchanged over the WebSocket of an exec session.
+type TerminalMessage struct {
+	// One of stdin and resize sent by the client, or stdout, stderr, exit and error sent by the server.
+	Type string `json:"type"`
+	// Terminal input or output, for stdin, stdout and stderr messages.
+	Data string `json:"data,omitempty"`
+	// Terminal size, for resize messages.
+	Cols uint16 `json:"cols,omitempty"`
+	Rows uint16 `json:"rows,omitempty"`
+	// Exit code of the command, for exit messages.
+	ExitCode *int `json:"exit_code,omitempty"`
+	// Reason the session has failed, for error messages.
+	Error *ModelError `json:"error,omitempty"`
+}
@@ -0,0 +1,12 @@
+package models
+
+// ColumnType tells clients how values of a resource list column are compared.
+type ColumnType string
+
+const (
+	ColumnTypeString    ColumnType = "string"
+	ColumnTypeInteger   ColumnType = "integer"
+	ColumnTypeDuration  ColumnType = "duration"
+	ColumnTypeTimestamp ColumnType = "timestamp"
+	ColumnTypeQuantity  ColumnType = "quantity"
+)
@@ -8,8 +8,34 @@ const (
 	Read   OperationType = "read"
 	Delete OperationType = "delete"
 	List   OperationType = "list"
-	All    OperationType = "*"
-	all    string        = "*"
+	// Logs allows reading container logs of a Pod without reading its spec
+	Logs OperationType = "logs"
+	// Exec allows opening a shell or running commands in a container of a Pod
+	Exec OperationType = "exec"
+	// Recordings allows viewing recorded exec and attach sessions of Pods
+	Recordings OperationType = "recordings"
+	// PortForward allows opening tunnels to ports of a Pod or of Pods behind a Service
+	PortForward OperationType = "portforward"
+	// Scale allows changing the replica count of a workload through its scale subresource
+	Scale OperationType = "scale"
+	// Restart allows restarting the rollout of a Deployment, StatefulSet or DaemonSet
+	Restart OperationType = "restart"
+	// Pause and Resume allow stopping and continuing the rollout of a Deployment
+	Pause  OperationType = "pause"
+	Resume OperationType = "resume"
+	// Undo allows rolling a Deployment, StatefulSet or DaemonSet back to a previous revision
+	Undo OperationType = "undo"
+	// ForceDelete allows deleting a resource with a zero grace period, e.g. a Pod stuck in termination
+	ForceDelete OperationType = "forcedelete"
+	// Reveal allows reading the decoded values of a Secret, which are masked in its details
+	Reveal OperationType = "reveal"
+	// Cordon and Uncordon allow marking a Node as unschedulable and schedulable again
+	Cordon   OperationType = "cordon"
+	Uncordon OperationType = "uncordon"
+	// Drain allows cordoning a Node and evicting its Pods
+	Drain OperationType = "drain"
+	All   OperationType = "*"
+	all   string        = "*"
 )
 
 type Operation struct {
@@ -18,6 +44,7 @@ type Operation struct {
 	Namespace string        `json:"namespace,omitempty"`
 }
 
+// GetAllOperationTypes returns the basic operations a "*" in a permit expands to.
 func GetAllOperationTypes() []OperationType {
     return []OperationType{
         Create,
@@ -28,6 +55,27 @@ func GetAllOperationTypes() []OperationType {
     }
 }
 
+// GetPrivilegedOperationTypes returns the operations which are never granted by a "*" in a permit
+// and have to be listed explicitly, so that existing roles do not silently gain them.
+func GetPrivilegedOperationTypes() []OperationType {
+    return []OperationType{
+        Logs,
+        Exec,
+        Recordings,
+        PortForward,
+        Scale,
+        Restart,
+        Pause,
+        Resume,
+        Undo,
+        ForceDelete,
+        Reveal,
+        Cordon,
+        Uncordon,
+        Drain,
+    }
+}
+
 func (permission OperationType)ShortString() string {
 	switch permission {
 	case Create:
@@ -40,6 +88,34 @@ func (permission OperationType)ShortString() string {
 		return "d"
 	case List:
 		return "l"
+	case Logs:
+		return "g"
+	case Exec:
+		return "e"
+	case Recordings:
+		return "v"
+	case PortForward:
+		return "f"
+	case Scale:
+		return "s"
+	case Restart:
+		return "t"
+	case Pause:
+		return "p"
+	case Resume:
+		return "m"
+	case Undo:
+		return "n"
+	case ForceDelete:
+		return "k"
+	case Reveal:
+		return "w"
+	case Cordon:
+		return "o"
+	case Uncordon:
+		return "y"
+	case Drain:
+		return "i"
 	default:
 		return "x"
 	}
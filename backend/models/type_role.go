@@ -5,4 +5,14 @@ type Role struct {
 	Permit   []Operation `json:"permit,omitempty"`
 	Deny     []Operation `json:"deny,omitempty"`
 	Subroles []string    `json:"subroles,omitempty"`
+	// Replicas limits the replica counts the role may scale workloads to.
+	Replicas []ReplicaLimit `json:"replicas,omitempty"`
+}
+
+// ReplicaLimit bounds the replica count of workloads of a resource type in a namespace, "*" matches any.
+type ReplicaLimit struct {
+	Namespace string `json:"namespace,omitempty"`
+	Resource  string `json:"resource,omitempty"`
+	Min       *int32 `json:"min,omitempty"`
+	Max       *int32 `json:"max,omitempty"`
 }
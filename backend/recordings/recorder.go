@@ -0,0 +1,146 @@
+package recordings
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+)
+
+const (
+	// asciicast v2, see https://docs.asciinema.org/manual/asciicast/v2/
+	castVersion       = 2
+	defaultCastWidth  = 80
+	defaultCastHeight = 24
+
+	castEventOutput = "o"
+	castEventInput  = "i"
+	castEventResize = "r"
+)
+
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     uint16            `json:"width"`
+	Height    uint16            `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Command   string            `json:"command,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Recorder writes a session in asciicast format and keeps its metadata up to date.
+type Recorder struct {
+	mutex     sync.Mutex
+	storage   Storage
+	writer    io.WriteCloser
+	recording models.SessionRecording
+	// writeErr is the first failed write, after which events are dropped
+	writeErr error
+}
+
+// StartRecording creates a recording of a new session. The metadata is saved right away,
+// so running sessions are listed as well.
+func StartRecording(storage Storage, recording models.SessionRecording) (*Recorder, error) {
+	id, err := newRecordingId()
+	if err != nil {
+		return nil, err
+	}
+	recording.Id = id
+	recording.StartTime = time.Now().UTC()
+
+	writer, err := storage.Create(id)
+	if err != nil {
+		return nil, err
+	}
+	recorder := &Recorder{storage: storage, writer: writer, recording: recording}
+
+	header, err := json.Marshal(castHeader{
+		Version:   castVersion,
+		Width:     defaultCastWidth,
+		Height:    defaultCastHeight,
+		Timestamp: recording.StartTime.Unix(),
+		Command:   strings.Join(recording.Command, " "),
+		Title:     fmt.Sprintf("%s %s/%s %s by %s", recording.Subresource, recording.Namespace, recording.Pod, recording.Container, recording.User),
+		Env:       map[string]string{"TERM": "xterm"},
+	})
+	if err != nil {
+		writer.Close()
+		return nil, err
+	}
+	if _, err := writer.Write(append(header, '\n')); err != nil {
+		writer.Close()
+		return nil, err
+	}
+	if err := storage.SaveMetadata(recording); err != nil {
+		writer.Close()
+		return nil, err
+	}
+	return recorder, nil
+}
+
+func (r *Recorder) Id() string {
+	return r.recording.Id
+}
+
+func (r *Recorder) Output(data []byte) {
+	r.writeEvent(castEventOutput, string(data))
+}
+
+func (r *Recorder) Input(data []byte) {
+	r.writeEvent(castEventInput, string(data))
+}
+
+func (r *Recorder) Resize(width uint16, height uint16) {
+	r.writeEvent(castEventResize, fmt.Sprintf("%dx%d", width, height))
+}
+
+func (r *Recorder) writeEvent(eventType string, data string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.writeErr != nil {
+		return
+	}
+
+	elapsed := time.Since(r.recording.StartTime).Round(time.Microsecond).Seconds()
+	event, err := json.Marshal([]interface{}{elapsed, eventType, data})
+	if err == nil {
+		_, err = r.writer.Write(append(event, '\n'))
+	}
+	r.writeErr = err
+}
+
+// Finish closes the recording, saving the end time together with the exit code or the error of the session.
+func (r *Recorder) Finish(exitCode *int, sessionErr *models.ModelError) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	endTime := time.Now().UTC()
+	r.recording.EndTime = &endTime
+	r.recording.ExitCode = exitCode
+	if sessionErr != nil {
+		r.recording.Error = sessionErr.Message
+	}
+
+	closeErr := r.writer.Close()
+	if err := r.storage.SaveMetadata(r.recording); err != nil {
+		return err
+	}
+	if r.writeErr != nil {
+		return r.writeErr
+	}
+	return closeErr
+}
+
+func newRecordingId() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,111 @@
+package recordings
+
+import (
+	"bufio"
+	"encoding/json"
+	"testing"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRecording() models.SessionRecording {
+	return models.SessionRecording{
+		User:        "alice",
+		Roles:       []string{"developer"},
+		Subresource: "exec",
+		Namespace:   "payments",
+		Pod:         "api-0",
+		Container:   "app",
+		Command:     []string{"/bin/sh"},
+		Tty:         true,
+	}
+}
+
+func readCastLines(t *testing.T, storage Storage, id string) []string {
+	reader, err := storage.Open(id)
+	assert.NoError(t, err)
+	defer reader.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	assert.NoError(t, scanner.Err())
+	return lines
+}
+
+func TestRecorderWritesAsciicast(t *testing.T) {
+	storage, err := NewLocalStorage(t.TempDir())
+	assert.NoError(t, err)
+
+	recorder, err := StartRecording(storage, newTestRecording())
+	assert.NoError(t, err)
+
+	recorder.Resize(120, 40)
+	recorder.Input([]byte("ls\r"))
+	recorder.Output([]byte("bin etc\r\n"))
+	exitCode := 0
+	assert.NoError(t, recorder.Finish(&exitCode, nil))
+
+	lines := readCastLines(t, storage, recorder.Id())
+	assert.Len(t, lines, 4)
+
+	var header castHeader
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &header))
+	assert.Equal(t, 2, header.Version)
+	assert.Equal(t, "/bin/sh", header.Command)
+
+	expectedEvents := [][2]string{{"r", "120x40"}, {"i", "ls\r"}, {"o", "bin etc\r\n"}}
+	for i, expected := range expectedEvents {
+		var event []interface{}
+		assert.NoError(t, json.Unmarshal([]byte(lines[i+1]), &event))
+		assert.Len(t, event, 3)
+		assert.IsType(t, float64(0), event[0])
+		assert.Equal(t, expected[0], event[1])
+		assert.Equal(t, expected[1], event[2])
+	}
+
+	recording, err := storage.GetMetadata(recorder.Id())
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", recording.User)
+	assert.NotNil(t, recording.EndTime)
+	assert.Equal(t, &exitCode, recording.ExitCode)
+}
+
+func TestRecorderSavesMetadataOnStart(t *testing.T) {
+	storage, err := NewLocalStorage(t.TempDir())
+	assert.NoError(t, err)
+
+	recorder, err := StartRecording(storage, newTestRecording())
+	assert.NoError(t, err)
+
+	recordings, err := storage.ListMetadata()
+	assert.NoError(t, err)
+	assert.Len(t, recordings, 1)
+	assert.Equal(t, recorder.Id(), recordings[0].Id)
+	assert.Nil(t, recordings[0].EndTime)
+
+	assert.NoError(t, recorder.Finish(nil, &models.ModelError{Code: 401, Message: "Token expired"}))
+	recording, err := storage.GetMetadata(recorder.Id())
+	assert.NoError(t, err)
+	assert.Equal(t, "Token expired", recording.Error)
+	assert.Nil(t, recording.ExitCode)
+}
+
+func TestLocalStorageRejectsInvalidIds(t *testing.T) {
+	storage, err := NewLocalStorage(t.TempDir())
+	assert.NoError(t, err)
+
+	_, err = storage.Open("../secrets")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = storage.GetMetadata("../secrets")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = storage.GetMetadata("0123456789abcdef0123456789abcdef")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	writer, err := storage.Create("not-an-id")
+	assert.Error(t, err)
+	assert.Nil(t, writer)
+}
@@ -0,0 +1,157 @@
+package recordings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ZPI-2024-25/KubernetesAccessManager/models"
+)
+
+const (
+	castExtension     = ".cast"
+	metadataExtension = ".json"
+)
+
+var (
+	ErrNotFound = errors.New("recording not found")
+
+	recordingIdPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+)
+
+// Storage keeps every session recording as an asciicast file and a metadata file.
+type Storage interface {
+	Create(id string) (io.WriteCloser, error)
+	Open(id string) (io.ReadCloser, error)
+	SaveMetadata(recording models.SessionRecording) error
+	GetMetadata(id string) (models.SessionRecording, error)
+	ListMetadata() ([]models.SessionRecording, error)
+}
+
+// LocalStorage keeps recordings in a directory, e.g. one with a persistent volume mounted.
+type LocalStorage struct {
+	dir string
+}
+
+var (
+	storageInstance Storage
+	storageMutex    sync.RWMutex
+)
+
+// InitStorage prepares the directory for recordings used by GetStorage.
+func InitStorage(dir string) error {
+	storage, err := NewLocalStorage(dir)
+	if err != nil {
+		return err
+	}
+
+	storageMutex.Lock()
+	defer storageMutex.Unlock()
+	storageInstance = storage
+	return nil
+}
+
+// GetStorage returns the storage prepared by InitStorage, or nil when recording is disabled.
+func GetStorage() Storage {
+	storageMutex.RLock()
+	defer storageMutex.RUnlock()
+	return storageInstance
+}
+
+func NewLocalStorage(dir string) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create recordings directory: %w", err)
+	}
+	return &LocalStorage{dir: dir}, nil
+}
+
+func (s *LocalStorage) Create(id string) (io.WriteCloser, error) {
+	if !recordingIdPattern.MatchString(id) {
+		return nil, fmt.Errorf("invalid recording id: %s", id)
+	}
+	return os.OpenFile(s.path(id, castExtension), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+}
+
+func (s *LocalStorage) Open(id string) (io.ReadCloser, error) {
+	if !recordingIdPattern.MatchString(id) {
+		return nil, ErrNotFound
+	}
+	file, err := os.Open(s.path(id, castExtension))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+// SaveMetadata replaces the metadata file at once, so readers never see it partially written.
+func (s *LocalStorage) SaveMetadata(recording models.SessionRecording) error {
+	if !recordingIdPattern.MatchString(recording.Id) {
+		return fmt.Errorf("invalid recording id: %s", recording.Id)
+	}
+	data, err := json.Marshal(recording)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := s.path(recording.Id, metadataExtension+".tmp")
+	if err := os.WriteFile(tmpPath, data, 0o640); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, s.path(recording.Id, metadataExtension))
+}
+
+func (s *LocalStorage) GetMetadata(id string) (models.SessionRecording, error) {
+	if !recordingIdPattern.MatchString(id) {
+		return models.SessionRecording{}, ErrNotFound
+	}
+	data, err := os.ReadFile(s.path(id, metadataExtension))
+	if errors.Is(err, os.ErrNotExist) {
+		return models.SessionRecording{}, ErrNotFound
+	}
+	if err != nil {
+		return models.SessionRecording{}, err
+	}
+
+	var recording models.SessionRecording
+	if err := json.Unmarshal(data, &recording); err != nil {
+		return models.SessionRecording{}, err
+	}
+	return recording, nil
+}
+
+// ListMetadata returns all recordings, the most recent first.
+func (s *LocalStorage) ListMetadata() ([]models.SessionRecording, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	recordings := make([]models.SessionRecording, 0)
+	for _, entry := range entries {
+		id, isMetadata := strings.CutSuffix(entry.Name(), metadataExtension)
+		if !isMetadata || !recordingIdPattern.MatchString(id) {
+			continue
+		}
+		recording, err := s.GetMetadata(id)
+		if err != nil {
+			return nil, err
+		}
+		recordings = append(recordings, recording)
+	}
+
+	sort.Slice(recordings, func(i, j int) bool {
+		return recordings[i].StartTime.After(recordings[j].StartTime)
+	})
+	return recordings, nil
+}
+
+func (s *LocalStorage) path(id string, extension string) string {
+	return filepath.Join(s.dir, id+extension)
+}
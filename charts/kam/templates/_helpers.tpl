@@ -154,6 +154,48 @@ Define backend enviromental values
 - name: ROLEMAP_NAME
   value: "{{ .Values.global.env.ROLEMAP_NAME }}"
 {{- end }}
+{{- if .Values.global.env.AUTHORIZATION_MODE }}
+- name: AUTHORIZATION_MODE
+  value: "{{ .Values.global.env.AUTHORIZATION_MODE }}"
+{{- end }}
+{{- if .Values.global.env.RESOURCE_TYPES_INCLUDE }}
+- name: RESOURCE_TYPES_INCLUDE
+  value: "{{ .Values.global.env.RESOURCE_TYPES_INCLUDE }}"
+{{- end }}
+{{- if .Values.global.env.RESOURCE_TYPES_EXCLUDE }}
+- name: RESOURCE_TYPES_EXCLUDE
+  value: "{{ .Values.global.env.RESOURCE_TYPES_EXCLUDE }}"
+{{- end }}
+{{- if .Values.global.env.DISCOVERY_REFRESH_INTERVAL }}
+- name: DISCOVERY_REFRESH_INTERVAL
+  value: "{{ .Values.global.env.DISCOVERY_REFRESH_INTERVAL }}"
+{{- end }}
+{{- if .Values.global.env.INFORMER_CACHE_ENABLED }}
+- name: INFORMER_CACHE_ENABLED
+  value: "{{ .Values.global.env.INFORMER_CACHE_ENABLED }}"
+{{- end }}
+{{- if .Values.global.env.INFORMER_CACHE_IDLE_TIMEOUT }}
+- name: INFORMER_CACHE_IDLE_TIMEOUT
+  value: "{{ .Values.global.env.INFORMER_CACHE_IDLE_TIMEOUT }}"
+{{- end }}
+{{- if .Values.global.env.SESSION_RECORDING_ENABLED }}
+- name: SESSION_RECORDING_ENABLED
+  value: "{{ .Values.global.env.SESSION_RECORDING_ENABLED }}"
+{{- end }}
+- name: SESSION_RECORDING_DIR
+  value: "{{ include "charts.recordingsDir" . }}"
+{{- if .Values.global.env.PORT_FORWARD_IDLE_TIMEOUT }}
+- name: PORT_FORWARD_IDLE_TIMEOUT
+  value: "{{ .Values.global.env.PORT_FORWARD_IDLE_TIMEOUT }}"
+{{- end }}
+{{- if .Values.global.env.PORT_FORWARD_MAX_TUNNELS_PER_USER }}
+- name: PORT_FORWARD_MAX_TUNNELS_PER_USER
+  value: "{{ .Values.global.env.PORT_FORWARD_MAX_TUNNELS_PER_USER }}"
+{{- end }}
+{{- if .Values.global.env.AUDIT_LOG_PATH }}
+- name: AUDIT_LOG_PATH
+  value: "{{ .Values.global.env.AUDIT_LOG_PATH }}"
+{{- end }}
 - name: IN_CLUSTER_MODE
   value: "true"
 {{- end }}
@@ -201,3 +243,17 @@ Define frontend environmental values
   value: "{{ .Values.global.env.ROLEMAP_NAME }}"
 {{- end }}
 {{- end }}
+
+{{/*
+Directory of session recordings, where the recordings volume is mounted
+*/}}
+{{- define "charts.recordingsDir" -}}
+{{- .Values.global.env.SESSION_RECORDING_DIR | default "/var/lib/kam/recordings" }}
+{{- end }}
+
+{{/*
+Name of the PersistentVolumeClaim for session recordings
+*/}}
+{{- define "charts.recordingsClaimName" -}}
+{{- .Values.backend.recordings.persistence.existingClaim | default (printf "%s-recordings" (include "charts.fullnameBackend" .)) }}
+{{- end }}
@@ -13,4 +13,4 @@ export interface UserStatus {
     };
 }
 
-export type Operation = "c" | "r" | "u" | "d" | "l";
\ No newline at end of file
+export type Operation = "c" | "r" | "u" | "d" | "l" | "g" | "e" | "v" | "f" | "s" | "t" | "p" | "m" | "n" | "k" | "w" | "o" | "y" | "i";
\ No newline at end of file
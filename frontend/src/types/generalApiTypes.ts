@@ -4,7 +4,16 @@ export interface Status {
     status: string;
 }
 
+export interface ErrorCause {
+    type?: string;
+    message?: string;
+    field?: string;
+}
+
 export interface ApiError {
     code: number;
     message: string;
+    reason?: string;
+    causes?: ErrorCause[];
+    retry_after_seconds?: number;
 }
\ No newline at end of file